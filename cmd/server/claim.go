@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"integration-test-example/internal/repository"
+	"integration-test-example/pkg/config"
+	"integration-test-example/pkg/database"
+	"log"
+	"strings"
+)
+
+const usage = `usage:
+  server                              start the API server (default)
+  server claim-unowned -email EMAIL   give todos, lists and tags created before users existed to the EMAIL user`
+
+// claimUnowned 는 사용자 도입 전에 만들어진 (소유자가 없는) todo, list, 태그를 -email 사용자에게 넘긴다.
+// 사용자는 먼저 /api/v1/auth/register 로 가입해 있어야 한다.
+func claimUnowned(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("claim-unowned", flag.ExitOnError)
+	email := fs.String("email", "", "email of the user who takes over the unowned rows")
+	_ = fs.Parse(args)
+
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+	if cfg.Store != config.StoreMySQL {
+		log.Fatalf("claim-unowned requires the %s store", config.StoreMySQL)
+	}
+
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect db:", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewTodoRepository(db)
+	user, err := repo.GetUserByEmail(strings.ToLower(strings.TrimSpace(*email)))
+	if err != nil {
+		log.Fatal("Failed to find user:", err)
+	}
+
+	claimed, err := repo.ClaimUnowned(user.ID)
+	if err != nil {
+		log.Fatal("Failed to claim unowned rows:", err)
+	}
+	fmt.Printf("claimed for %s: %d todos, %d lists, %d tags\n", user.Email, claimed.Todos, claimed.Lists, claimed.Tags)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"integration-test-example/internal/middleware"
+	"integration-test-example/internal/service"
+	"integration-test-example/pkg/config"
+	"os"
+)
+
+// authSecretEnv 는 HS256 서명 키를 읽는 환경 변수. 키가 저장소에 커밋되지 않도록 config.json 에서는 읽지 않는다.
+const authSecretEnv = "AUTH_SECRET"
+
+// placeholderSecrets 는 예제 설정에 커밋된 적이 있어 서명 키로 쓸 수 없는 값
+var placeholderSecrets = []string{
+	"change-me-to-a-random-secret-of-32-bytes",
+	"integration-test-secret-0123456789abcdef",
+}
+
+// serverConfig 는 공통 설정(config.Config)에 API 서버에서만 쓰는 middleware, service 설정을 더한 config.json 구조
+type serverConfig struct {
+	config.Config
+	// RateLimit 정책이 비어 있으면 middleware.DefaultRateLimitConfig 를 사용한다.
+	RateLimit   middleware.RateLimitConfig   `json:"rate_limit"`
+	Idempotency middleware.IdempotencyConfig `json:"idempotency"`
+	Batch       service.BatchConfig          `json:"batch"`
+	// Auth 는 로그인 토큰의 서명과 검증 설정. Secret 은 AUTH_SECRET 환경 변수로만 설정한다.
+	Auth middleware.AuthConfig `json:"auth"`
+}
+
+func loadConfig(filename string) (*serverConfig, error) {
+	var cfg serverConfig
+	if err := config.Decode(filename, &cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadAuthSecret(); err != nil {
+		return nil, err
+	}
+
+	cfg.SetDefaults()
+	return &cfg, nil
+}
+
+// loadAuthSecret 은 HS256 서명 키를 환경 변수에서 읽는다. 기본값은 없으며,
+// config.json 에 키가 있거나 커밋된 적이 있는 예제 값이면 서버를 시작하지 않는다.
+func (c *serverConfig) loadAuthSecret() error {
+	if c.Auth.Secret != "" {
+		return fmt.Errorf("auth.secret must not be set in the config file; set %s instead", authSecretEnv)
+	}
+
+	c.Auth.Secret = os.Getenv(authSecretEnv)
+	for _, placeholder := range placeholderSecrets {
+		if c.Auth.Secret == placeholder {
+			return fmt.Errorf("%s is a placeholder; set it to a random secret", authSecretEnv)
+		}
+	}
+	if c.Auth.Secret == "" && c.Auth.SigningMethod != middleware.SigningRS256 {
+		return errors.New(authSecretEnv + " is required for HS256 tokens")
+	}
+	return nil
+}
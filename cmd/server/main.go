@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"integration-test-example/internal/handler"
@@ -10,19 +11,31 @@ import (
 	"integration-test-example/pkg/config"
 	"integration-test-example/pkg/database"
 	redisClient "integration-test-example/pkg/redis"
+	"integration-test-example/pkg/sqs"
 	"log"
 	"net/http"
+	"os"
 	"time"
 )
 
 func main() {
-	cfg, err := config.Load("config.json")
+	cfg, err := loadConfig("config.json")
 	if err != nil {
 		log.Fatal("Fail to load config:", err)
 	}
-	db, err := database.Connect(cfg.Database)
+
+	if args := os.Args[1:]; len(args) > 0 {
+		if args[0] != "claim-unowned" {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		claimUnowned(&cfg.Config, args[1:])
+		return
+	}
+
+	stores, err := newStores(&cfg.Config)
 	if err != nil {
-		log.Fatal("Fail to connect db:", err)
+		log.Fatal("Fail to create todo store:", err)
 	}
 
 	rdb, err := redisClient.NewRedisClient(cfg.Redis)
@@ -32,18 +45,64 @@ func main() {
 
 	log.Println(cfg)
 
+	var publisher service.EventPublisher = service.LogPublisher{}
+	if cfg.SQSEnabled() {
+		sqsClient, err := sqs.NewSQSClient(cfg.SQS)
+		if err != nil {
+			log.Fatal("Failed to connect to SQS:", err)
+		}
+		publisher = service.NewNotificationService(sqsClient)
+	} else {
+		log.Println("SQS is not configured, todo notifications are disabled")
+	}
+
+	relay := service.NewOutboxRelay(
+		stores.outbox,
+		publisher,
+		time.Duration(cfg.Outbox.PollIntervalMs)*time.Millisecond,
+		cfg.Outbox.BatchSize,
+		time.Duration(cfg.Outbox.RetentionHours)*time.Hour,
+	)
+	go relay.Run(context.Background())
+
+	purger := service.NewTrashPurger(
+		stores.trash,
+		time.Duration(cfg.Trash.RetentionDays)*24*time.Hour,
+		time.Duration(cfg.Trash.PurgeIntervalMinutes)*time.Minute,
+		cfg.Trash.BatchSize,
+	)
+	go purger.Run(context.Background())
+
+	reminders := service.NewReminderScheduler(
+		stores.reminders,
+		time.Duration(cfg.Reminder.PollIntervalSeconds)*time.Second,
+		cfg.Reminder.BatchSize,
+	)
+	go reminders.Run(context.Background())
+
 	r := gin.Default()
 
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 
-	rateLimiter := middleware.NewRateLimiter(rdb, 120, time.Minute)
+	auth, err := middleware.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		log.Fatal("Failed to create authenticator:", err)
+	}
+	// 사용자별 rate limit 이 사용자 ID 를 읽을 수 있도록 RateLimit 보다 먼저 등록한다.
+	r.Use(auth.Authenticate())
+
+	rateLimiter, err := middleware.NewRateLimiter(rdb, cfg.RateLimit)
+	if err != nil {
+		log.Fatal("Failed to create rate limiter:", err)
+	}
 	r.Use(rateLimiter.RateLimit())
 
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, If-None-Match, Idempotency-Key")
+		c.Header("Access-Control-Expose-Headers", "ETag, Idempotent-Replayed")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -61,16 +120,59 @@ func main() {
 
 	api := r.Group("/api/v1")
 	{
-		todoRepo := repository.NewTodoRepository(db)
-		todoSerivce := service.NewTodoService(todoRepo)
+		authHandler := handler.NewAuthHandler(service.NewUserService(stores.users), auth)
+		api.POST("/auth/register", authHandler.Register)
+		api.POST("/auth/login", authHandler.Login)
+
+		// todo, 태그, list API 는 로그인한 사용자만 사용할 수 있다.
+		protected := api.Group("", middleware.RequireUser())
+
+		todoSerivce := service.NewTodoService(stores.todos)
+		todoSerivce.ConfigureBatch(cfg.Batch)
 		todoHandler := handler.NewTodoHandler(todoSerivce)
-		todos := api.Group("/todos")
+		idempotency := middleware.NewIdempotency(rdb, cfg.Idempotency)
+		todos := protected.Group("/todos")
 
-		todos.POST("", todoHandler.CreateTodo)
+		todos.POST("", idempotency.Idempotent(), todoHandler.CreateTodo)
 		todos.GET("", todoHandler.GetTodos)
+		todos.GET("/trash", todoHandler.GetTrash)
 		todos.GET("/:id", todoHandler.GetTodo)
-		todos.PUT("/:id", todoHandler.UpdateTodo)
+		todos.PUT("/:id", todoHandler.ReplaceTodo)
+		todos.PATCH("/:id", todoHandler.PatchTodo)
 		todos.DELETE("/:id", todoHandler.DeleteTodo)
+		todos.POST("/:id/restore", todoHandler.RestoreTodo)
+		todos.POST("/:id/move", todoHandler.MoveTodo)
+		todos.GET("/:id/occurrences", todoHandler.GetOccurrences)
+		todos.PUT("/:id/tags/:tag_id", todoHandler.AttachTag)
+		todos.DELETE("/:id/tags/:tag_id", todoHandler.DetachTag)
+		todos.GET("/:id/items", todoHandler.GetItems)
+		todos.POST("/:id/items", todoHandler.CreateItem)
+		todos.PUT("/:id/items/:item_id", todoHandler.ReplaceItem)
+		todos.DELETE("/:id/items/:item_id", todoHandler.DeleteItem)
+		todos.POST("/:id/items/:item_id/move", todoHandler.MoveItem)
+
+		// custom method: POST /api/v1/todos:batch
+		protected.POST("/todos:method", handler.CustomMethods(map[string]gin.HandlerFunc{
+			"batch": todoHandler.BatchTodos,
+		}))
+
+		tagHandler := handler.NewTagHandler(service.NewTagService(stores.tags))
+		tags := protected.Group("/tags")
+
+		tags.GET("", tagHandler.GetTags)
+		tags.POST("", tagHandler.CreateTag)
+		tags.PUT("/:id", tagHandler.RenameTag)
+		tags.DELETE("/:id", tagHandler.DeleteTag)
+
+		listHandler := handler.NewListHandler(service.NewListService(stores.lists), todoSerivce)
+		lists := protected.Group("/lists")
+
+		lists.GET("", listHandler.GetLists)
+		lists.POST("", listHandler.CreateList)
+		lists.GET("/:id", listHandler.GetList)
+		lists.PUT("/:id", listHandler.RenameList)
+		lists.DELETE("/:id", listHandler.DeleteList)
+		lists.GET("/:id/todos", listHandler.GetListTodos)
 	}
 
 	log.Printf("Server starting on port %d", cfg.Server.Port)
@@ -78,3 +180,32 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// stores 는 같은 backend 위에서 동작하는 저장소들
+type stores struct {
+	todos     repository.TodoStore
+	outbox    repository.OutboxStore
+	trash     repository.TrashStore
+	reminders repository.ReminderStore
+	tags      repository.TagStore
+	lists     repository.ListStore
+	users     repository.UserStore
+}
+
+// newStores 는 설정된 backend 로 todo 저장소와 같은 저장소 위의 outbox, 휴지통, 알림, 태그, list, 사용자 저장소를 만든다.
+func newStores(cfg *config.Config) (*stores, error) {
+	switch cfg.Store {
+	case config.StoreMySQL:
+		db, err := database.Connect(cfg.Database)
+		if err != nil {
+			return nil, fmt.Errorf("fail to connect db: %w", err)
+		}
+		repo := repository.NewTodoRepository(db)
+		return &stores{todos: repo, outbox: repo, trash: repo, reminders: repo, tags: repo, lists: repo, users: repo}, nil
+	case config.StoreMemory:
+		repo := repository.NewMemoryTodoRepository()
+		return &stores{todos: repo, outbox: repo, trash: repo, reminders: repo, tags: repo, lists: repo, users: repo}, nil
+	default:
+		return nil, fmt.Errorf("unknown store: %q", cfg.Store)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/worker"
+	"integration-test-example/pkg/config"
+	"integration-test-example/pkg/sqs"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const usage = `usage:
+  worker                    consume notification messages (default)
+  worker dlq inspect [-n N] show dead-letter queue stats and up to N (max 10) messages
+  worker dlq redrive [-max N] move dead-letter messages back to the main queue (0 = all)`
+
+func main() {
+	cfg, err := config.Load("config.json")
+	if err != nil {
+		log.Fatal("Fail to load config:", err)
+	}
+
+	if !cfg.SQSEnabled() {
+		log.Fatal("SQS is not configured")
+	}
+
+	sqsClient, err := sqs.NewSQSClient(cfg.SQS)
+	if err != nil {
+		log.Fatal("Failed to connect to SQS:", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	args := os.Args[1:]
+	switch {
+	case len(args) == 0:
+		consume(ctx, cfg, sqsClient)
+	case len(args) >= 2 && args[0] == "dlq" && args[1] == "inspect":
+		inspectDLQ(ctx, sqsClient, args[2:])
+	case len(args) >= 2 && args[0] == "dlq" && args[1] == "redrive":
+		redriveDLQ(ctx, sqsClient, args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func consume(ctx context.Context, cfg *config.Config, sqsClient *sqs.SQSClient) {
+	dispatcher := worker.NewDispatcher()
+	for _, eventType := range []string{
+		model.EventTodoCreated,
+		model.EventTodoUpdated,
+		model.EventTodoCompleted,
+		model.EventTodoDeleted,
+		model.EventTodoRestored,
+		model.EventTodoReminder,
+	} {
+		dispatcher.Register(eventType, worker.LogHandler{})
+	}
+
+	consumer := sqs.NewConsumer(sqsClient, dispatcher.HandleMessage, cfg.Worker)
+
+	log.Printf("Worker consuming queue %s", cfg.SQS.QueueName)
+	consumer.Run(ctx)
+	log.Println("Worker stopped")
+}
+
+func inspectDLQ(ctx context.Context, sqsClient *sqs.SQSClient, args []string) {
+	fs := flag.NewFlagSet("dlq inspect", flag.ExitOnError)
+	n := fs.Int64("n", 10, "number of messages to show (max 10)")
+	_ = fs.Parse(args)
+
+	stats, err := sqsClient.GetDLQStats(ctx)
+	if err != nil {
+		log.Fatal("Failed to inspect dead-letter queue:", err)
+	}
+	fmt.Printf("messages: %d (in flight: %d)\n", stats.Messages, stats.MessagesNotVisible)
+
+	if *n <= 0 {
+		return
+	}
+
+	messages, err := sqsClient.PeekDeadLetters(ctx, *n)
+	if err != nil {
+		log.Fatal("Failed to inspect dead-letter queue:", err)
+	}
+	for _, message := range messages {
+		fmt.Printf("%s (received %d times): %s\n", message.ID, message.ReceiveCount, message.Body)
+	}
+}
+
+func redriveDLQ(ctx context.Context, sqsClient *sqs.SQSClient, args []string) {
+	fs := flag.NewFlagSet("dlq redrive", flag.ExitOnError)
+	limit := fs.Int("max", 0, "maximum number of messages to redrive (0 = all)")
+	_ = fs.Parse(args)
+
+	moved, err := sqsClient.RedriveDeadLetters(ctx, *limit)
+	fmt.Printf("redriven: %d\n", moved)
+	if err != nil {
+		log.Fatal("Failed to redrive dead-letter queue:", err)
+	}
+}
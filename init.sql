@@ -1,8 +1,137 @@
+-- 회원. 이메일은 소문자로 저장하며 비밀번호는 bcrypt 해시만 저장한다.
+CREATE TABLE IF NOT EXISTS users (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(254) NOT NULL,
+    password_hash VARCHAR(60) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    UNIQUE INDEX uq_users_email (email)
+);
+
+-- todo 를 묶는 list (프로젝트). list 가 삭제되면 속한 todo 는 휴지통으로 옮겨지거나 함께 삭제된다.
+CREATE TABLE IF NOT EXISTS lists (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    -- 소유자. API 는 인증된 사용자의 list 만 다룬다.
+    user_id INT NULL,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    -- 사용자별 list 조회용
+    INDEX idx_lists_user (user_id, created_at, id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS todos (
     id INT AUTO_INCREMENT PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
     description TEXT,
     completed BOOLEAN DEFAULT FALSE,
+    -- 소유자. API 는 인증된 사용자의 todo 만 다룬다.
+    -- 사용자 도입 전에 만든 todo 는 NULL 이며 `server claim-unowned` 로 사용자에게 넘기기 전까지 보이지 않는다. (list, 태그도 같다)
+    user_id INT NULL,
+    -- 속한 list (없으면 NULL)
+    list_id INT NULL,
+    -- 모든 item 이 완료되면 todo 도 완료할지 여부
+    auto_complete BOOLEAN NOT NULL DEFAULT FALSE,
+    -- 우선순위 Rank: 0=low, 1=normal, 2=high, 3=urgent
+    priority TINYINT NOT NULL DEFAULT 1,
+    -- 수동 정렬 순서 (fractional index). byte 순서로 비교해야 하므로 ascii_bin
+    position VARCHAR(255) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    -- optimistic concurrency: 변경마다 1 증가 (ETag / If-Match)
+    version BIGINT NOT NULL DEFAULT 1,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
-);
\ No newline at end of file
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    -- 마감 / 알림 시각. reminded_at 은 알림이 발송된 시각이며 remind_at 이 바뀌면 NULL 로 돌아간다.
+    due_at TIMESTAMP(6) NULL,
+    remind_at TIMESTAMP(6) NULL,
+    reminded_at TIMESTAMP(6) NULL,
+    -- 반복 일정 (RFC 5545 RRULE). due_at 이 첫 발생 시각이며 반복하지 않으면 빈 문자열
+    rrule VARCHAR(255) NOT NULL DEFAULT '',
+    -- soft delete: 휴지통으로 이동된 시각 (활성 todo 는 NULL)
+    deleted_at TIMESTAMP(6) NULL,
+
+    -- GET /api/v1/todos keyset 페이지네이션용 (deleted_at IS NULL, sort 컬럼, id)
+    INDEX idx_todos_created_at (deleted_at, created_at, id),
+    INDEX idx_todos_updated_at (deleted_at, updated_at, id),
+    INDEX idx_todos_title (deleted_at, title, id),
+    -- completed 필터와 함께 쓰는 경우
+    INDEX idx_todos_completed_created_at (deleted_at, completed, created_at, id),
+    INDEX idx_todos_completed_updated_at (deleted_at, completed, updated_at, id),
+    INDEX idx_todos_completed_title (deleted_at, completed, title, id),
+    INDEX idx_todos_priority (deleted_at, priority, id),
+    -- 휴지통을 포함해 position 은 사용자의 todo 마다 다르다. (새 키는 같은 사용자의 인접한 두 키 사이에서 만든다)
+    -- 사용자마다 순서가 따로이므로 todo 생성 / 이동은 같은 사용자의 행만 잠근다.
+    UNIQUE INDEX uq_todos_position (user_id, position),
+    -- overdue / due_before / due_after 필터용
+    INDEX idx_todos_due_at (deleted_at, completed, due_at),
+    -- reminder scheduler 폴링용: 아직 발송되지 않은 알림을 알림 시각 순으로
+    INDEX idx_todos_reminder (reminded_at, remind_at, id),
+    -- GET /api/v1/todos?q= 전문 검색용. ngram parser 로 띄어쓰기 없는 한국어도 부분 일치로 찾는다.
+    FULLTEXT INDEX ft_todos_title_description (title, description) WITH PARSER ngram,
+    -- GET /api/v1/lists/:id/todos 및 list 삭제 시 속한 todo 조회용
+    INDEX idx_todos_list (list_id, deleted_at, created_at, id),
+    -- 사용자별 todo 조회용
+    INDEX idx_todos_user (user_id, deleted_at, created_at, id),
+    -- 사용자가 삭제되면 그 사용자의 todo 도 함께 삭제된다.
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    -- list 를 삭제할 때 속한 todo 는 DeleteList 가 먼저 정리한다. (SET NULL 은 안전장치)
+    FOREIGN KEY (list_id) REFERENCES lists (id) ON DELETE SET NULL
+);
+
+-- todo 의 하위 항목(체크리스트). todo 가 영구 삭제되면 함께 삭제된다.
+CREATE TABLE IF NOT EXISTS todo_items (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    todo_id INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    -- todo 안에서의 순서 (fractional index). todos.position 과 같이 ascii_bin
+    position VARCHAR(255) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    -- 순서대로 조회 / progress 집계용
+    UNIQUE INDEX uq_todo_items_position (todo_id, position),
+    FOREIGN KEY (todo_id) REFERENCES todos (id) ON DELETE CASCADE
+);
+
+-- todo 태그. 이름은 사용자마다 기본 collation(대소문자 구분 없음)으로 유일하다.
+CREATE TABLE IF NOT EXISTS tags (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    -- 소유자. API 는 인증된 사용자의 태그만 다룬다.
+    user_id INT NULL,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    UNIQUE INDEX uq_tags_user_name (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- todo 와 태그의 다대다 관계. todo 가 영구 삭제되거나 태그가 삭제되면 함께 지워진다.
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id INT NOT NULL,
+    tag_id INT NOT NULL,
+
+    PRIMARY KEY (todo_id, tag_id),
+    -- tag 필터 / 태그 이름 변경 시 태그가 붙은 todo 조회용
+    INDEX idx_todo_tags_tag (tag_id, todo_id),
+    FOREIGN KEY (todo_id) REFERENCES todos (id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+-- todo 도메인 이벤트 transactional outbox
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    todo_id INT NOT NULL,
+    payload JSON NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    available_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    dispatched_at TIMESTAMP(6) NULL,
+
+    -- relay 폴링용: 미발행 이벤트를 발행 가능 시각 순으로
+    -- 발행된 이벤트는 retention(outbox.retention_hours)이 지나면 relay 가 dispatched_at 순으로 삭제한다. (같은 인덱스 사용)
+    INDEX idx_outbox_pending (dispatched_at, available_at, id)
+);
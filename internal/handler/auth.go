@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/service"
+	"net/http"
+	"time"
+)
+
+// maxAuthBodyBytes 는 가입 / 로그인 요청 바디의 최대 크기
+const maxAuthBodyBytes = 4 << 10
+
+// TokenIssuer 는 로그인한 사용자의 access token 을 발급한다. (middleware.Authenticator)
+type TokenIssuer interface {
+	IssueToken(userID int64) (string, time.Time, error)
+}
+
+type AuthHandler struct {
+	userService *service.UserService
+	tokens      TokenIssuer
+}
+
+func NewAuthHandler(userService *service.UserService, tokens TokenIssuer) *AuthHandler {
+	return &AuthHandler{userService: userService, tokens: tokens}
+}
+
+func (h AuthHandler) Register(c *gin.Context) {
+	var req model.RegisterRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAuthBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	user, err := h.userService.Register(req.Email, req.Password)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User registered successfully",
+		"user":    user,
+	})
+}
+
+// Login 은 이메일과 비밀번호를 확인하고 Authorization: Bearer 헤더에 쓸 access token 을 발급한다.
+func (h AuthHandler) Login(c *gin.Context) {
+	var req model.LoginRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAuthBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	user, err := h.userService.Login(req.Email, req.Password)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	token, expiresAt, err := h.tokens.IssueToken(user.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_at":   expiresAt,
+		"user":         user,
+	})
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/middleware"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/service"
+	"net/http"
+	"testing"
+)
+
+// newAuthTestRouter: 실제 JWT 인증 미들웨어를 거치는 라우터 테스트 픽스쳐
+func newAuthTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	auth, err := middleware.NewAuthenticator(middleware.AuthConfig{Secret: "0123456789abcdef0123456789abcdef"})
+	require.NoError(t, err)
+	repo := repository.NewMemoryTodoRepository()
+	return newRouter(repo, service.NewTodoService(repo), auth, auth.Authenticate())
+}
+
+// login: 사용자를 가입시키고 로그인해 Authorization 헤더 값을 반환하는 테스트 헬퍼
+func login(t *testing.T, r http.Handler, email string) string {
+	t.Helper()
+	credentials := map[string]interface{}{"email": email, "password": "correct horse"}
+	registered := doRequest(r, http.MethodPost, "/api/v1/auth/register", credentials)
+	require.Equal(t, http.StatusOK, registered.Code)
+	w := doRequest(r, http.MethodPost, "/api/v1/auth/login", credentials)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.TokenType + " " + resp.AccessToken
+}
+
+func TestAuthHandler(t *testing.T) {
+	t.Run("Todos Are Visible Only To Their Owner", func(t *testing.T) {
+		// Arrange
+		r := newAuthTestRouter(t)
+		alice := login(t, r, "alice@example.com")
+		bob := login(t, r, "bob@example.com")
+		created := doRequest(r, http.MethodPost, "/api/v1/todos",
+			map[string]interface{}{"title": "alice's todo"}, "Authorization", alice)
+		require.Equal(t, http.StatusOK, created.Code)
+		todo := decodeTodo(t, created)
+		path := fmt.Sprintf("/api/v1/todos/%d", todo.ID)
+
+		// Act
+		own := doRequest(r, http.MethodGet, path, nil, "Authorization", alice)
+		other := doRequest(r, http.MethodGet, path, nil, "Authorization", bob)
+		otherDelete := doRequest(r, http.MethodDelete, path, nil, "Authorization", bob)
+		otherList := doRequest(r, http.MethodGet, "/api/v1/todos", nil, "Authorization", bob)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, own.Code)
+		assert.NotContains(t, own.Body.String(), "user_id")
+		assert.Equal(t, CodeTodoNotFound, decodeProblem(t, other).Code)
+		assert.Equal(t, CodeTodoNotFound, decodeProblem(t, otherDelete).Code)
+		assert.Empty(t, decodePage(t, otherList).Todos)
+	})
+
+	t.Run("Lists And Tags Are Visible Only To Their Owner", func(t *testing.T) {
+		// Arrange
+		r := newAuthTestRouter(t)
+		alice := login(t, r, "alice@example.com")
+		bob := login(t, r, "bob@example.com")
+		list := createList(t, r, "work", "Authorization", alice)
+		tag := createTag(t, r, "work", "Authorization", alice)
+		listPath := fmt.Sprintf("/api/v1/lists/%d", list.ID)
+		tagPath := fmt.Sprintf("/api/v1/tags/%d", tag.ID)
+
+		// Act
+		otherList := doRequest(r, http.MethodGet, listPath, nil, "Authorization", bob)
+		otherListTodos := doRequest(r, http.MethodGet, listPath+"/todos", nil, "Authorization", bob)
+		otherListDelete := doRequest(r, http.MethodDelete, listPath+"?cascade=delete", nil, "Authorization", bob)
+		otherTagRename := doRequest(r, http.MethodPut, tagPath, map[string]interface{}{"name": "stolen"}, "Authorization", bob)
+		otherTagDelete := doRequest(r, http.MethodDelete, tagPath, nil, "Authorization", bob)
+		otherTodo := doRequest(r, http.MethodPost, "/api/v1/todos",
+			map[string]interface{}{"title": "bob's todo", "list_id": list.ID}, "Authorization", bob)
+		bobLists := doRequest(r, http.MethodGet, "/api/v1/lists", nil, "Authorization", bob)
+		bobTag := createTag(t, r, "work", "Authorization", bob)
+
+		// Assert
+		assert.Equal(t, CodeListNotFound, decodeProblem(t, otherList).Code)
+		assert.Equal(t, CodeListNotFound, decodeProblem(t, otherListTodos).Code)
+		assert.Equal(t, CodeListNotFound, decodeProblem(t, otherListDelete).Code)
+		assert.Equal(t, CodeTagNotFound, decodeProblem(t, otherTagRename).Code)
+		assert.Equal(t, CodeTagNotFound, decodeProblem(t, otherTagDelete).Code)
+		assert.Equal(t, CodeValidationFailed, decodeProblem(t, otherTodo).Code)
+		assert.JSONEq(t, `{"lists": []}`, bobLists.Body.String())
+		assert.NotEqual(t, tag.ID, bobTag.ID, "tag names are unique per owner")
+
+		own := doRequest(r, http.MethodGet, listPath, nil, "Authorization", alice)
+		assert.Equal(t, http.StatusOK, own.Code)
+		assert.NotContains(t, own.Body.String(), "user_id")
+		ownTags := doRequest(r, http.MethodGet, "/api/v1/tags", nil, "Authorization", alice)
+		assert.Contains(t, ownTags.Body.String(), `"name":"work"`)
+		assert.NotContains(t, ownTags.Body.String(), "stolen")
+	})
+
+	t.Run("Todo API Requires A Valid Token", func(t *testing.T) {
+		// Arrange
+		r := newAuthTestRouter(t)
+
+		// Act
+		anonymous := doRequest(r, http.MethodGet, "/api/v1/todos", nil)
+		forged := doRequest(r, http.MethodGet, "/api/v1/todos", nil, "Authorization", "Bearer not-a-jwt")
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, anonymous.Code)
+		assert.Equal(t, http.StatusUnauthorized, forged.Code)
+	})
+
+	t.Run("Register And Login Errors", func(t *testing.T) {
+		// Arrange
+		r := newAuthTestRouter(t)
+		login(t, r, "alice@example.com")
+
+		// Act
+		invalid := doRequest(r, http.MethodPost, "/api/v1/auth/register",
+			map[string]interface{}{"email": "alice", "password": "short"})
+		duplicate := doRequest(r, http.MethodPost, "/api/v1/auth/register",
+			map[string]interface{}{"email": "Alice@Example.com", "password": "another password"})
+		wrongPassword := doRequest(r, http.MethodPost, "/api/v1/auth/login",
+			map[string]interface{}{"email": "alice@example.com", "password": "battery staple"})
+
+		// Assert
+		assert.Equal(t, CodeValidationFailed, decodeProblem(t, invalid).Code)
+		assert.Equal(t, http.StatusConflict, duplicate.Code)
+		assert.Equal(t, CodeEmailTaken, decodeProblem(t, duplicate).Code)
+		assert.Equal(t, http.StatusUnauthorized, wrongPassword.Code)
+		assert.Equal(t, CodeInvalidCredentials, decodeProblem(t, wrongPassword).Code)
+	})
+}
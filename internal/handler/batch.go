@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/service"
+	"integration-test-example/internal/validation"
+	"net/http"
+	"strings"
+)
+
+// maxBatchBodyBytes 는 batch 요청 바디의 최대 크기
+const maxBatchBodyBytes = 4 << 20
+
+// BatchResult 는 batch 응답의 operation 하나의 결과
+type BatchResult struct {
+	Index  int         `json:"index"`
+	Op     string      `json:"op"`
+	Status int         `json:"status"`
+	Todo   *model.Todo `json:"todo,omitempty"`
+	Error  *Problem    `json:"error,omitempty"`
+}
+
+// CustomMethods 는 "/todos:batch" 같은 custom method 경로를 처리한다.
+// gin 은 경로 중간의 ':' 이후를 path 파라미터로 해석하므로 "/todos:method" 로 등록하고
+// 파라미터 값(":batch")으로 handler 를 고른다.
+func CustomMethods(handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name, ok := strings.CutPrefix(c.Param("method"), ":")
+		handler, found := handlers[name]
+		if !ok || !found {
+			writeProblem(c, newProblem(http.StatusNotFound, CodeNotFound, "Resource not found"))
+			return
+		}
+		handler(c)
+	}
+}
+
+// BatchTodos 는 여러 create / update / delete 를 하나의 트랜잭션으로 실행한다. (POST /todos:batch)
+//
+// atomic 모드에서 실패하면 실패한 operation 의 상태로 problem 을 응답하고 아무것도 반영하지 않는다.
+// independent 모드에서는 항상 200 과 함께 항목별 결과를 응답한다.
+func (h TodoHandler) BatchTodos(c *gin.Context) {
+	var req model.BatchTodoRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	results, err := h.todos(c).BatchTodos(req.Mode, req.Operations)
+	if err != nil {
+		var batchErr *service.BatchError
+		if errors.As(err, &batchErr) {
+			writeProblem(c, batchOperationProblem(c, batchErr))
+			return
+		}
+		writeError(c, err)
+		return
+	}
+
+	response := make([]BatchResult, len(results))
+	for i, result := range results {
+		response[i] = BatchResult{Index: i, Op: result.Op, Todo: result.Todo}
+		switch {
+		case result.Err != nil:
+			problem := errorProblem(c, result.Err)
+			prefixFieldErrors(problem, i)
+			response[i].Status = problem.Status
+			response[i].Error = problem
+		case result.Op == model.BatchCreate:
+			response[i].Status = http.StatusCreated
+		default:
+			response[i].Status = http.StatusOK
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": response,
+	})
+}
+
+// batchOperationProblem 은 atomic batch 를 중단시킨 operation 의 에러를 어느 operation 인지와 함께 나타낸다.
+func batchOperationProblem(c *gin.Context, batchErr *service.BatchError) *Problem {
+	problem := errorProblem(c, batchErr.Err)
+	problem.Detail = fmt.Sprintf("operation %d failed, no changes were applied: %s", batchErr.Index, problem.Detail)
+	prefixFieldErrors(problem, batchErr.Index)
+	return problem
+}
+
+// prefixFieldErrors 는 필드 에러의 경로를 요청 바디 기준(operations[i].title)으로 바꾼다.
+func prefixFieldErrors(problem *Problem, index int) {
+	if len(problem.Errors) == 0 {
+		return
+	}
+	errs := make(validation.Errors, len(problem.Errors))
+	for i, fieldErr := range problem.Errors {
+		fieldErr.Field = fmt.Sprintf("operations[%d].%s", index, fieldErr.Field)
+		errs[i] = fieldErr
+	}
+	problem.Errors = errs
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"net/http"
+	"testing"
+)
+
+func TestBatchTodos(t *testing.T) {
+	t.Run("Atomic Batch", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		existing, err := todoService.CreateTodo("existing", "")
+		require.NoError(t, err)
+
+		// Act
+		w := doRequest(r, http.MethodPost, "/api/v1/todos:batch", map[string]interface{}{
+			"operations": []map[string]interface{}{
+				{"op": "create", "title": "created"},
+				{"op": "update", "id": existing.ID, "title": "updated"},
+				{"op": "delete", "id": existing.ID},
+			},
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Results []BatchResult `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Results, 3)
+		assert.Equal(t, http.StatusCreated, resp.Results[0].Status)
+		assert.Equal(t, "updated", resp.Results[1].Todo.Title)
+		assert.Equal(t, http.StatusOK, resp.Results[2].Status)
+	})
+
+	t.Run("Atomic Batch Failure Points To Operation", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodPost, "/api/v1/todos:batch", map[string]interface{}{
+			"mode": "atomic",
+			"operations": []map[string]interface{}{
+				{"op": "create", "title": "created"},
+				{"op": "create", "title": ""},
+			},
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		problem := decodeProblem(t, w)
+		assert.Equal(t, CodeValidationFailed, problem.Code)
+		assert.Contains(t, problem.Detail, "operation 1")
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, "operations[1].title", problem.Errors[0].Field)
+
+		page, err := todoService.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, page.Todos)
+	})
+
+	t.Run("Independent Batch Returns Per Item Status", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodPost, "/api/v1/todos:batch", map[string]interface{}{
+			"mode": "independent",
+			"operations": []map[string]interface{}{
+				{"op": "delete", "id": 999},
+				{"op": "create", "title": "created"},
+			},
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Results []BatchResult `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Results, 2)
+		assert.Equal(t, http.StatusNotFound, resp.Results[0].Status)
+		assert.Equal(t, CodeTodoNotFound, resp.Results[0].Error.Code)
+		assert.Equal(t, http.StatusCreated, resp.Results[1].Status)
+	})
+
+	t.Run("Invalid Operation Is Rejected Before Execution", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodPost, "/api/v1/todos:batch", map[string]interface{}{
+			"operations": []map[string]interface{}{
+				{"op": "create", "title": "created"},
+				{"op": "update"},
+			},
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		problem := decodeProblem(t, w)
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, "operations[1].id", problem.Errors[0].Field)
+	})
+
+	t.Run("Unknown Custom Method Returns 404", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodPost, "/api/v1/todos:unknown", map[string]interface{}{})
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, CodeNotFound, decodeProblem(t, w).Code)
+	})
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/service"
+	"strconv"
+	"strings"
+)
+
+// todoETag 는 todo 의 version 으로 만든 strong entity tag
+func todoETag(todo *model.Todo) string {
+	return `"` + strconv.FormatInt(todo.Version, 10) + `"`
+}
+
+func setTodoETag(c *gin.Context, todo *model.Todo) {
+	c.Header("ETag", todoETag(todo))
+}
+
+// ifMatch 는 If-Match 헤더를 service.Precondition 으로 변환한다. 헤더가 없으면 nil
+// If-Match 는 strong 비교를 사용하므로 weak tag(W/"...")는 일치하지 않는다. (RFC 9110 13.1.1)
+func ifMatch(c *gin.Context) service.Precondition {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return nil
+	}
+	return func(current *model.Todo) bool {
+		return matchETag(header, todoETag(current), false)
+	}
+}
+
+// ifNoneMatch 는 If-None-Match 헤더가 todo 의 현재 ETag 와 weak 비교로 일치하는지 확인한다. (RFC 9110 13.1.2)
+func ifNoneMatch(c *gin.Context, todo *model.Todo) bool {
+	header := c.GetHeader("If-None-Match")
+	return header != "" && matchETag(header, todoETag(todo), true)
+}
+
+// matchETag 는 "*" 또는 쉼표로 구분된 entity tag 목록 중 etag 와 일치하는 것이 있는지 확인한다.
+func matchETag(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"integration-test-example/internal/model"
+	"net/http"
+	"strconv"
+)
+
+// GetItems 는 todo 의 item 을 순서대로 조회한다.
+func (h TodoHandler) GetItems(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.todos(c).GetItems(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items": items,
+	})
+}
+
+// CreateItem 은 todo 의 item 중 맨 뒤에 item 을 추가한다.
+// item 변경은 todo 의 변경이므로 If-Match 와 응답의 ETag 는 todo 기준이다.
+func (h TodoHandler) CreateItem(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	var req model.TodoItemRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTodoBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	item, todo, err := h.todos(c).CreateItem(id, ifMatch(c), req.Title, req.Completed)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setTodoETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item created successfully",
+		"item":    item,
+		"todo":    todo,
+	})
+}
+
+// ReplaceItem 은 item 의 title, completed 를 교체한다. (PUT)
+func (h TodoHandler) ReplaceItem(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+	itemID, ok := parseItemID(c)
+	if !ok {
+		return
+	}
+
+	var req model.TodoItemRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTodoBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	item, todo, err := h.todos(c).ReplaceItem(id, itemID, ifMatch(c), req.Title, req.Completed)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setTodoETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item updated successfully",
+		"item":    item,
+		"todo":    todo,
+	})
+}
+
+func (h TodoHandler) DeleteItem(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+	itemID, ok := parseItemID(c)
+	if !ok {
+		return
+	}
+
+	todo, err := h.todos(c).DeleteItem(id, itemID, ifMatch(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setTodoETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item deleted successfully",
+		"todo":    todo,
+	})
+}
+
+// MoveItem 은 item 을 같은 todo 의 다른 item 바로 뒤(after_id) 또는 바로 앞(before_id)으로 옮긴다.
+func (h TodoHandler) MoveItem(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+	itemID, ok := parseItemID(c)
+	if !ok {
+		return
+	}
+
+	var req model.MoveTodoRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTodoBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	item, todo, err := h.todos(c).MoveItem(id, itemID, ifMatch(c), int(req.AfterID), int(req.BeforeID))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setTodoETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item moved successfully",
+		"item":    item,
+		"todo":    todo,
+	})
+}
+
+// parseItemID 는 :item_id 경로 파라미터를 읽고, 잘못된 값이면 400 을 응답한 뒤 false 를 반환한다.
+func parseItemID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("item_id"))
+	if err != nil || id <= 0 {
+		writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidItemID, "Item id must be a positive integer"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"net/http"
+	"testing"
+)
+
+func TestItemHandler(t *testing.T) {
+	t.Run("Create And Complete Items", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		todo, err := todoService.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		path := fmt.Sprintf("/api/v1/todos/%d/items", todo.ID)
+
+		// Act
+		created := doRequest(r, http.MethodPost, path, map[string]interface{}{"title": "step"})
+		var resp struct {
+			Item model.TodoItem `json:"item"`
+			Todo model.Todo     `json:"todo"`
+		}
+		require.NoError(t, json.Unmarshal(created.Body.Bytes(), &resp))
+		stale := doRequest(r, http.MethodPut, fmt.Sprintf("%s/%d", path, resp.Item.ID),
+			map[string]interface{}{"title": "step", "completed": true}, "If-Match", `"1"`)
+		replaced := doRequest(r, http.MethodPut, fmt.Sprintf("%s/%d", path, resp.Item.ID),
+			map[string]interface{}{"title": "step", "completed": true}, "If-Match", created.Header().Get("ETag"))
+		list := doRequest(r, http.MethodGet, path, nil)
+
+		// Assert
+		require.Equal(t, http.StatusOK, created.Code)
+		assert.Equal(t, &model.TodoProgress{Completed: 0, Total: 1, Percent: 0}, resp.Todo.Progress)
+		assert.Equal(t, `"2"`, created.Header().Get("ETag"), "item changes are todo changes")
+		assert.Equal(t, http.StatusPreconditionFailed, stale.Code)
+		require.Equal(t, http.StatusOK, replaced.Code)
+		assert.Contains(t, replaced.Body.String(), `"percent":100`)
+		assert.Contains(t, list.Body.String(), `"completed":true`)
+	})
+
+	t.Run("Item Errors", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		todo, err := todoService.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		path := fmt.Sprintf("/api/v1/todos/%d/items", todo.ID)
+
+		// Act
+		blank := doRequest(r, http.MethodPost, path, map[string]interface{}{"title": " "})
+		invalid := doRequest(r, http.MethodDelete, path+"/abc", nil)
+		missing := doRequest(r, http.MethodDelete, path+"/999", nil)
+		noTodo := doRequest(r, http.MethodGet, "/api/v1/todos/999/items", nil)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, blank.Code)
+		assert.Equal(t, CodeValidationFailed, decodeProblem(t, blank).Code)
+		assert.Equal(t, CodeInvalidItemID, decodeProblem(t, invalid).Code)
+		assert.Equal(t, http.StatusNotFound, missing.Code)
+		assert.Equal(t, CodeItemNotFound, decodeProblem(t, missing).Code)
+		assert.Equal(t, CodeTodoNotFound, decodeProblem(t, noTodo).Code)
+	})
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"integration-test-example/internal/middleware"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/service"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxListBodyBytes 는 list 요청 바디의 최대 크기
+const maxListBodyBytes = 4 << 10
+
+// ListHandler 는 list 자체와, list 에 속한 todo 목록(/lists/:id/todos)을 다룬다.
+type ListHandler struct {
+	listService *service.ListService
+	todoService *service.TodoService
+}
+
+func NewListHandler(listService *service.ListService, todoService *service.TodoService) *ListHandler {
+	return &ListHandler{listService: listService, todoService: todoService}
+}
+
+// lists 는 인증된 사용자의 list 만 다루는 ListService 를 반환한다.
+func (h ListHandler) lists(c *gin.Context) *service.ListService {
+	userID, _ := middleware.CurrentUserID(c)
+	return h.listService.ForUser(userID)
+}
+
+func (h ListHandler) GetLists(c *gin.Context) {
+	lists, err := h.lists(c).GetLists()
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"lists": lists,
+	})
+}
+
+func (h ListHandler) GetList(c *gin.Context) {
+	id, ok := parseListID(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.lists(c).GetList(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"list": list,
+	})
+}
+
+func (h ListHandler) CreateList(c *gin.Context) {
+	var req model.ListRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxListBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	list, err := h.lists(c).CreateList(req.Name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "List created successfully",
+		"list":    list,
+	})
+}
+
+// RenameList 는 list 이름을 바꾼다. (PUT)
+func (h ListHandler) RenameList(c *gin.Context) {
+	id, ok := parseListID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ListRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxListBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	list, err := h.lists(c).RenameList(id, req.Name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "List renamed successfully",
+		"list":    list,
+	})
+}
+
+// DeleteList 는 list 를 삭제한다. cascade 쿼리 파라미터로 속한 todo 의 처리 방식을 정한다.
+//
+//   - archive (기본값): todo 를 휴지통으로 옮긴다. 복원하면 list 없이 복원된다.
+//   - delete: 휴지통의 todo 를 포함해 todo 를 영구 삭제한다.
+func (h ListHandler) DeleteList(c *gin.Context) {
+	id, ok := parseListID(c)
+	if !ok {
+		return
+	}
+
+	affected, err := h.lists(c).DeleteList(id, strings.ToLower(c.Query("cascade")))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "List deleted successfully",
+		"affected_todos": affected,
+	})
+}
+
+// GetListTodos 는 list 에 속한 todo 목록을 조회한다. 쿼리 파라미터와 페이지네이션은 GET /todos 와 같다.
+func (h ListHandler) GetListTodos(c *gin.Context) {
+	id, ok := parseListID(c)
+	if !ok {
+		return
+	}
+
+	query, err := parseListQuery(c)
+	if err != nil {
+		writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidQuery, err.Error()))
+		return
+	}
+
+	if _, err := h.lists(c).GetList(id); err != nil {
+		writeError(c, err)
+		return
+	}
+	listID := int64(id)
+	query.ListID = &listID
+
+	page, err := userTodos(c, h.todoService).GetAllTodos(query)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"todos":       page.Todos,
+		"next_cursor": page.NextCursor,
+	})
+}
+
+// parseListID 는 id 경로 파라미터의 list id 를 읽고, 잘못된 값이면 400 을 응답한 뒤 false 를 반환한다.
+func parseListID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidListID, "List id must be a positive integer"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// createList: list 를 만들고 응답의 list 를 반환하는 테스트 헬퍼
+func createList(t *testing.T, r http.Handler, name string, headers ...string) model.List {
+	t.Helper()
+	w := doRequest(r, http.MethodPost, "/api/v1/lists", map[string]interface{}{"name": name}, headers...)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		List model.List `json:"list"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.List
+}
+
+func decodePage(t *testing.T, w *httptest.ResponseRecorder) model.TodoPage {
+	t.Helper()
+	var page model.TodoPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	return page
+}
+
+func TestListHandler(t *testing.T) {
+	t.Run("List Todos Are Paginated Like Get Todos", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+		list := createList(t, r, "work")
+		for _, title := range []string{"first", "second", "third"} {
+			created := doRequest(r, http.MethodPost, "/api/v1/todos",
+				map[string]interface{}{"title": title, "list_id": list.ID})
+			require.Equal(t, http.StatusOK, created.Code)
+		}
+		doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{"title": "elsewhere"})
+		path := fmt.Sprintf("/api/v1/lists/%d/todos?sort=title&order=asc&limit=2", list.ID)
+
+		// Act
+		first := doRequest(r, http.MethodGet, path, nil)
+		firstPage := decodePage(t, first)
+		second := doRequest(r, http.MethodGet, path+"&cursor="+firstPage.NextCursor, nil)
+		secondPage := decodePage(t, second)
+
+		// Assert
+		require.Equal(t, http.StatusOK, first.Code)
+		require.Len(t, firstPage.Todos, 2)
+		assert.Equal(t, "first", firstPage.Todos[0].Title)
+		assert.Equal(t, list.ID, *firstPage.Todos[0].ListID)
+		require.Equal(t, http.StatusOK, second.Code)
+		require.Len(t, secondPage.Todos, 1)
+		assert.Equal(t, "third", secondPage.Todos[0].Title)
+		assert.Empty(t, secondPage.NextCursor)
+	})
+
+	t.Run("Rename And Delete List", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+		list := createList(t, r, "work")
+		created := doRequest(r, http.MethodPost, "/api/v1/todos",
+			map[string]interface{}{"title": "in list", "list_id": list.ID})
+		todo := decodeTodo(t, created)
+		path := fmt.Sprintf("/api/v1/lists/%d", list.ID)
+
+		// Act
+		renamed := doRequest(r, http.MethodPut, path, map[string]interface{}{"name": "office"})
+		deleted := doRequest(r, http.MethodDelete, path+"?cascade=delete", nil)
+		missing := doRequest(r, http.MethodGet, path, nil)
+		todoAfter := doRequest(r, http.MethodGet, fmt.Sprintf("/api/v1/todos/%d", todo.ID), nil)
+
+		// Assert
+		require.Equal(t, http.StatusOK, renamed.Code)
+		assert.Contains(t, renamed.Body.String(), `"name":"office"`)
+		require.Equal(t, http.StatusOK, deleted.Code)
+		assert.Contains(t, deleted.Body.String(), `"affected_todos":1`)
+		assert.Equal(t, CodeListNotFound, decodeProblem(t, missing).Code)
+		assert.Equal(t, http.StatusNotFound, todoAfter.Code)
+	})
+
+	t.Run("List Errors", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+		list := createList(t, r, "work")
+
+		// Act
+		blank := doRequest(r, http.MethodPost, "/api/v1/lists", map[string]interface{}{"name": " "})
+		invalid := doRequest(r, http.MethodGet, "/api/v1/lists/abc/todos", nil)
+		missing := doRequest(r, http.MethodGet, "/api/v1/lists/999/todos", nil)
+		badCascade := doRequest(r, http.MethodDelete, fmt.Sprintf("/api/v1/lists/%d?cascade=orphan", list.ID), nil)
+		unknownList := doRequest(r, http.MethodPost, "/api/v1/todos",
+			map[string]interface{}{"title": "dummy title", "list_id": 999})
+
+		// Assert
+		assert.Equal(t, CodeValidationFailed, decodeProblem(t, blank).Code)
+		assert.Equal(t, CodeInvalidListID, decodeProblem(t, invalid).Code)
+		assert.Equal(t, CodeListNotFound, decodeProblem(t, missing).Code)
+		assert.Equal(t, CodeInvalidQuery, decodeProblem(t, badCascade).Code)
+		assert.Equal(t, http.StatusBadRequest, unknownList.Code)
+		assert.Equal(t, "list_id", decodeProblem(t, unknownList).Errors[0].Field)
+	})
+}
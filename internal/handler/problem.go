@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"integration-test-example/internal/problem"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/service"
+	"integration-test-example/internal/validation"
+	"io"
+	"log"
+	"net/http"
+)
+
+// ProblemContentType 은 RFC 9457 problem details 응답의 media type
+const ProblemContentType = problem.ContentType
+
+// 클라이언트가 분기할 수 있는 고정된 에러 코드
+const (
+	CodeInvalidRequestBody   = "invalid_request_body"
+	CodeInvalidTodoID        = "invalid_todo_id"
+	CodeInvalidTagID         = "invalid_tag_id"
+	CodeInvalidItemID        = "invalid_item_id"
+	CodeInvalidListID        = "invalid_list_id"
+	CodeInvalidQuery         = "invalid_query"
+	CodeInvalidBatch         = "invalid_batch"
+	CodeInvalidMove          = "invalid_move"
+	CodeBatchTooLarge        = "batch_too_large"
+	CodeNotFound             = "not_found"
+	CodeInvalidPatch         = "invalid_patch"
+	CodePatchConflict        = "patch_conflict"
+	CodePreconditionFailed   = "precondition_failed"
+	CodeTodoConflict         = "todo_conflict"
+	CodeRequestTooLarge      = "request_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeValidationFailed     = "validation_failed"
+	CodeTodoNotFound         = "todo_not_found"
+	CodeTagNotFound          = "tag_not_found"
+	CodeItemNotFound         = "item_not_found"
+	CodeListNotFound         = "list_not_found"
+	CodeTagConflict          = "tag_conflict"
+	CodeEmailTaken           = "email_taken"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeInternalError        = "internal_error"
+)
+
+// Problem represents an RFC 9457 problem details object
+type Problem = problem.Problem
+
+func init() {
+	// 요청 바인딩도 service 와 같은 커스텀 규칙과 json 필드 이름을 사용하도록 등록
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := validation.Register(v); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func newProblem(status int, code, detail string) *Problem {
+	return problem.New(status, code, detail)
+}
+
+// writeProblem 은 problem 을 application/problem+json 으로 응답하고 요청 처리를 중단한다.
+func writeProblem(c *gin.Context, p *Problem) {
+	problem.Write(c, p)
+}
+
+// writeError 는 service / repository 에러를 HTTP 상태와 에러 코드로 매핑해 응답한다.
+func writeError(c *gin.Context, err error) {
+	writeProblem(c, errorProblem(c, err))
+}
+
+// errorProblem 은 service / repository 에러를 problem 으로 변환한다.
+// 매핑되지 않은 에러는 내부 정보를 노출하지 않도록 500 으로 응답하고 로그만 남긴다.
+func errorProblem(c *gin.Context, err error) *Problem {
+	var validationErrors validation.Errors
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound), errors.Is(err, repository.ErrTodoNotFound):
+		return newProblem(http.StatusNotFound, CodeTodoNotFound, "Todo not found")
+	case errors.Is(err, service.ErrItemNotFound):
+		return newProblem(http.StatusNotFound, CodeItemNotFound, "Todo item not found")
+	case errors.Is(err, service.ErrListNotFound):
+		return newProblem(http.StatusNotFound, CodeListNotFound, "List not found")
+	case errors.Is(err, service.ErrTagNotFound):
+		return newProblem(http.StatusNotFound, CodeTagNotFound, "Tag not found")
+	case errors.Is(err, service.ErrTagConflict):
+		return newProblem(http.StatusConflict, CodeTagConflict, "A tag with the same name already exists")
+	case errors.Is(err, service.ErrEmailTaken):
+		return newProblem(http.StatusConflict, CodeEmailTaken, "A user with the same email already exists")
+	case errors.Is(err, service.ErrInvalidCredentials):
+		return newProblem(http.StatusUnauthorized, CodeInvalidCredentials, "Email or password is incorrect")
+	case errors.Is(err, service.ErrInvalidListQuery), errors.Is(err, service.ErrInvalidCascade):
+		return newProblem(http.StatusBadRequest, CodeInvalidQuery, err.Error())
+	case errors.Is(err, service.ErrInvalidPatch):
+		return newProblem(http.StatusBadRequest, CodeInvalidPatch, err.Error())
+	case errors.Is(err, service.ErrPatchConflict):
+		return newProblem(http.StatusConflict, CodePatchConflict, err.Error())
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return newProblem(http.StatusPreconditionFailed, CodePreconditionFailed,
+			"Todo has been modified; fetch the latest version and retry")
+	case errors.Is(err, service.ErrTodoConflict):
+		return newProblem(http.StatusConflict, CodeTodoConflict, "Todo is being modified concurrently; retry the request")
+	case errors.Is(err, service.ErrInvalidBatch):
+		return newProblem(http.StatusBadRequest, CodeInvalidBatch, err.Error())
+	case errors.Is(err, service.ErrInvalidMove):
+		return newProblem(http.StatusBadRequest, CodeInvalidMove, err.Error())
+	case errors.Is(err, service.ErrBatchTooLarge):
+		return newProblem(http.StatusRequestEntityTooLarge, CodeBatchTooLarge, err.Error())
+	case errors.As(err, &validationErrors):
+		return validationProblem(validationErrors)
+	default:
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		return newProblem(http.StatusInternalServerError, CodeInternalError, "An unexpected error occurred")
+	}
+}
+
+// writeBindError 는 요청 바디 바인딩 실패를 필드 단위 상세와 함께 응답한다.
+func writeBindError(c *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		writeValidationProblem(c, validation.FromValidator(validationErrors))
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		writeValidationProblem(c, validation.Errors{{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: fmt.Sprintf("must be %s", typeErr.Type.String()),
+		}})
+		return
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeProblem(c, newProblem(http.StatusRequestEntityTooLarge, CodeRequestTooLarge,
+			fmt.Sprintf("Request body must be at most %d bytes", maxBytesErr.Limit)))
+		return
+	}
+
+	detail := "Request body must be valid JSON"
+	if errors.Is(err, io.EOF) {
+		detail = "Request body is empty"
+	}
+	writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidRequestBody, detail))
+}
+
+// writeValidationProblem 은 모든 필드 에러를 한 번에 400 으로 응답한다.
+func writeValidationProblem(c *gin.Context, errs validation.Errors) {
+	writeProblem(c, validationProblem(errs))
+}
+
+func validationProblem(errs validation.Errors) *Problem {
+	p := newProblem(http.StatusBadRequest, CodeValidationFailed, "Request body has invalid fields")
+	p.Errors = errs
+	return p
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceHandler(t *testing.T) {
+	t.Run("Create Recurring Todo And Preview Occurrences", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		created := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title":  "pay rent",
+			"due_at": "2026-10-30T09:00:00Z",
+			"rrule":  "FREQ=MONTHLY;BYMONTHDAY=-1",
+		})
+		todo := decodeTodo(t, created)
+		preview := doRequest(r, http.MethodGet, fmt.Sprintf("/api/v1/todos/%d/occurrences?limit=3", todo.ID), nil)
+		var resp struct {
+			Occurrences []time.Time `json:"occurrences"`
+		}
+		require.NoError(t, json.Unmarshal(preview.Body.Bytes(), &resp))
+
+		// Assert
+		require.Equal(t, http.StatusOK, created.Code)
+		assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=-1", todo.RRule)
+		require.Equal(t, http.StatusOK, preview.Code)
+		assert.Equal(t, []time.Time{
+			time.Date(2026, 10, 31, 9, 0, 0, 0, time.UTC),
+			time.Date(2026, 11, 30, 9, 0, 0, 0, time.UTC),
+			time.Date(2026, 12, 31, 9, 0, 0, 0, time.UTC),
+		}, resp.Occurrences)
+	})
+
+	t.Run("Completing Spawns The Next Occurrence", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+		created := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title":  "standup",
+			"due_at": "2026-10-16T09:00:00Z",
+			"rrule":  "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		})
+		todo := decodeTodo(t, created)
+
+		// Act
+		completed := doPatch(r, fmt.Sprintf("/api/v1/todos/%d", todo.ID), "application/merge-patch+json", `{"completed": true}`)
+		list := doRequest(r, http.MethodGet, "/api/v1/todos?completed=false", nil)
+
+		// Assert
+		require.Equal(t, http.StatusOK, completed.Code)
+		assert.True(t, decodeTodo(t, completed).Completed)
+		require.Equal(t, http.StatusOK, list.Code)
+		assert.Contains(t, list.Body.String(), `"due_at":"2026-10-19T09:00:00Z"`)
+	})
+
+	t.Run("Recurrence Errors", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		todo, err := todoService.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+
+		// Act
+		unsupported := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title": "dummy title", "due_at": "2026-10-16T09:00:00Z", "rrule": "FREQ=HOURLY",
+		})
+		noDue := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title": "dummy title", "rrule": "FREQ=DAILY",
+		})
+		badLimit := doRequest(r, http.MethodGet, fmt.Sprintf("/api/v1/todos/%d/occurrences?limit=0", todo.ID), nil)
+		tooMany := doRequest(r, http.MethodGet, fmt.Sprintf("/api/v1/todos/%d/occurrences?limit=101", todo.ID), nil)
+		notRecurring := doRequest(r, http.MethodGet, fmt.Sprintf("/api/v1/todos/%d/occurrences", todo.ID), nil)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, unsupported.Code)
+		assert.Equal(t, "rrule", decodeProblem(t, unsupported).Errors[0].Field)
+		assert.Equal(t, "due_at", decodeProblem(t, noDue).Errors[0].Field)
+		assert.Equal(t, CodeInvalidQuery, decodeProblem(t, badLimit).Code)
+		assert.Equal(t, CodeInvalidQuery, decodeProblem(t, tooMany).Code)
+		require.Equal(t, http.StatusOK, notRecurring.Code)
+		assert.JSONEq(t, `{"occurrences": []}`, notRecurring.Body.String())
+	})
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"integration-test-example/internal/middleware"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/service"
+	"net/http"
+	"strconv"
+)
+
+// maxTagBodyBytes 는 태그 요청 바디의 최대 크기
+const maxTagBodyBytes = 4 << 10
+
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+func NewTagHandler(tagService *service.TagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+// tags 는 인증된 사용자의 태그만 다루는 TagService 를 반환한다.
+func (h TagHandler) tags(c *gin.Context) *service.TagService {
+	userID, _ := middleware.CurrentUserID(c)
+	return h.tagService.ForUser(userID)
+}
+
+func (h TagHandler) GetTags(c *gin.Context) {
+	tags, err := h.tags(c).GetTags()
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"tags": tags,
+	})
+}
+
+func (h TagHandler) CreateTag(c *gin.Context) {
+	var req model.TagRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTagBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	tag, err := h.tags(c).CreateTag(req.Name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag created successfully",
+		"tag":     tag,
+	})
+}
+
+// RenameTag 는 태그 이름을 바꾼다. (PUT)
+func (h TagHandler) RenameTag(c *gin.Context) {
+	id, ok := parseTagID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.TagRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTagBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	tag, err := h.tags(c).RenameTag(id, req.Name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag renamed successfully",
+		"tag":     tag,
+	})
+}
+
+func (h TagHandler) DeleteTag(c *gin.Context) {
+	id, ok := parseTagID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.tags(c).DeleteTag(id); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tag deleted successfully",
+	})
+}
+
+// parseTagID 는 name 경로 파라미터의 태그 id 를 읽고, 잘못된 값이면 400 을 응답한 뒤 false 를 반환한다.
+func parseTagID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidTagID, "Tag id must be a positive integer"))
+		return 0, false
+	}
+	return id, true
+}
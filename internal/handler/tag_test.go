@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// createTag: 태그를 만들고 응답의 태그를 반환하는 테스트 헬퍼
+func createTag(t *testing.T, r http.Handler, name string, headers ...string) model.Tag {
+	t.Helper()
+	w := doRequest(r, http.MethodPost, "/api/v1/tags", map[string]interface{}{"name": name}, headers...)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Tag model.Tag `json:"tag"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Tag
+}
+
+func decodeTodo(t *testing.T, w *httptest.ResponseRecorder) model.Todo {
+	t.Helper()
+	var resp struct {
+		Todo model.Todo `json:"todo"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Todo
+}
+
+func TestTagHandler(t *testing.T) {
+	t.Run("Create Rename And Delete Tag", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+		tag := createTag(t, r, "work")
+		createTag(t, r, "home")
+
+		// Act
+		duplicate := doRequest(r, http.MethodPost, "/api/v1/tags", map[string]interface{}{"name": "Work"})
+		renamed := doRequest(r, http.MethodPut, fmt.Sprintf("/api/v1/tags/%d", tag.ID),
+			map[string]interface{}{"name": "office"})
+		deleted := doRequest(r, http.MethodDelete, fmt.Sprintf("/api/v1/tags/%d", tag.ID), nil)
+		missing := doRequest(r, http.MethodDelete, fmt.Sprintf("/api/v1/tags/%d", tag.ID), nil)
+		list := doRequest(r, http.MethodGet, "/api/v1/tags", nil)
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, duplicate.Code)
+		assert.Equal(t, CodeTagConflict, decodeProblem(t, duplicate).Code)
+		assert.Equal(t, http.StatusOK, renamed.Code)
+		assert.Contains(t, renamed.Body.String(), `"name":"office"`)
+		assert.Equal(t, http.StatusOK, deleted.Code)
+		assert.Equal(t, http.StatusNotFound, missing.Code)
+		assert.Equal(t, CodeTagNotFound, decodeProblem(t, missing).Code)
+
+		var resp struct {
+			Tags []model.Tag `json:"tags"`
+		}
+		require.NoError(t, json.Unmarshal(list.Body.Bytes(), &resp))
+		require.Len(t, resp.Tags, 1)
+		assert.Equal(t, "home", resp.Tags[0].Name)
+	})
+
+	t.Run("Attach And Detach Tag", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		todo, err := todoService.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		tag := createTag(t, r, "work")
+		path := fmt.Sprintf("/api/v1/todos/%d/tags/%d", todo.ID, tag.ID)
+
+		// Act
+		attached := doRequest(r, http.MethodPut, path, nil)
+		stale := doRequest(r, http.MethodDelete, path, nil, "If-Match", `"1"`)
+		detached := doRequest(r, http.MethodDelete, path, nil, "If-Match", attached.Header().Get("ETag"))
+
+		// Assert
+		require.Equal(t, http.StatusOK, attached.Code)
+		assert.Equal(t, []model.Tag{tag}, decodeTodo(t, attached).Tags)
+		assert.Equal(t, `"2"`, attached.Header().Get("ETag"))
+		assert.Equal(t, http.StatusPreconditionFailed, stale.Code)
+		require.Equal(t, http.StatusOK, detached.Code)
+		assert.Empty(t, decodeTodo(t, detached).Tags)
+		assert.Contains(t, detached.Body.String(), `"tags":[]`, "tags are always an array")
+	})
+
+	t.Run("Attach Tag Rejects Invalid Ids", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		todo, err := todoService.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+
+		// Act
+		invalid := doRequest(r, http.MethodPut, fmt.Sprintf("/api/v1/todos/%d/tags/abc", todo.ID), nil)
+		missing := doRequest(r, http.MethodPut, fmt.Sprintf("/api/v1/todos/%d/tags/999", todo.ID), nil)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, invalid.Code)
+		assert.Equal(t, CodeInvalidTagID, decodeProblem(t, invalid).Code)
+		assert.Equal(t, http.StatusNotFound, missing.Code)
+		assert.Equal(t, CodeTagNotFound, decodeProblem(t, missing).Code)
+	})
+
+	t.Run("Get Todos Filters By Tags", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		work := createTag(t, r, "work")
+		urgent := createTag(t, r, "urgent")
+		both, err := todoService.CreateTodo("both", "")
+		require.NoError(t, err)
+		workOnly, err := todoService.CreateTodo("work only", "")
+		require.NoError(t, err)
+		for _, attach := range [][2]int64{{both.ID, work.ID}, {both.ID, urgent.ID}, {workOnly.ID, work.ID}} {
+			w := doRequest(r, http.MethodPut, fmt.Sprintf("/api/v1/todos/%d/tags/%d", attach[0], attach[1]), nil)
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+		titles := func(path string) []string {
+			w := doRequest(r, http.MethodGet, path, nil)
+			require.Equal(t, http.StatusOK, w.Code)
+			var resp struct {
+				Todos []model.Todo `json:"todos"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			var titles []string
+			for _, todo := range resp.Todos {
+				titles = append(titles, todo.Title)
+			}
+			return titles
+		}
+
+		// Act & Assert
+		assert.ElementsMatch(t, []string{"both", "work only"}, titles("/api/v1/todos?tag=work&tag=urgent"))
+		assert.Equal(t, []string{"both"}, titles("/api/v1/todos?tag=work&tag=urgent&tag_match=all"))
+
+		w := doRequest(r, http.MethodGet, "/api/v1/todos?tag=work&tag_match=some", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, CodeInvalidQuery, decodeProblem(t, w).Code)
+	})
+}
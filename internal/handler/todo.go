@@ -1,13 +1,24 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"integration-test-example/internal/middleware"
 	"integration-test-example/internal/model"
+	"integration-test-example/internal/patch"
 	"integration-test-example/internal/service"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// maxTodoBodyBytes 는 todo 요청 바디의 최대 크기.
+// 필드 검증 전에 과도하게 큰 바디를 읽지 않도록 제한한다.
+const maxTodoBodyBytes = 64 << 10
+
 type TodoHandler struct {
 	todoService *service.TodoService
 }
@@ -16,19 +27,33 @@ func NewTodoHandler(todoService *service.TodoService) *TodoHandler {
 	return &TodoHandler{todoService: todoService}
 }
 
+// todos 는 요청한 사용자의 todo 만 다루는 TodoService 를 반환한다.
+func (h TodoHandler) todos(c *gin.Context) *service.TodoService {
+	return userTodos(c, h.todoService)
+}
+
+// userTodos 는 todoService 를 인증된 사용자의 todo 로 범위를 좁힌다.
+// todo API 는 middleware.RequireUser 뒤에 등록되므로 사용자가 항상 있다.
+func userTodos(c *gin.Context, todoService *service.TodoService) *service.TodoService {
+	userID, _ := middleware.CurrentUserID(c)
+	return todoService.ForUser(userID)
+}
+
 func (h TodoHandler) CreateTodo(c *gin.Context) {
 	var req model.CreateTodoRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTodoBodyBytes)
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		writeBindError(c, err)
 		return
 	}
-	todo, err := h.todoService.CreateTodo(req.Title, req.Description)
+	todo, err := h.todos(c).CreateTodo(req.Title, req.Description,
+		service.WithPriority(req.Priority), service.WithList(req.ListID), service.WithAutoComplete(req.AutoComplete),
+		service.WithSchedule(req.DueAt, req.RemindAt), service.WithRecurrence(req.RRule))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, err)
+		writeError(c, err)
 		return
 	}
+	setTodoETag(c, todo)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Todo created successfully",
 		"todo":    todo,
@@ -36,38 +61,117 @@ func (h TodoHandler) CreateTodo(c *gin.Context) {
 }
 
 func (h TodoHandler) GetTodos(c *gin.Context) {
-	todos, err := h.todoService.GetAllTodos()
+	query, err := parseListQuery(c)
+	if err != nil {
+		writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidQuery, err.Error()))
+		return
+	}
+
+	page, err := h.todos(c).GetAllTodos(query)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Fail to get todos",
-		})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"todos": todos,
+		"todos":       page.Todos,
+		"next_cursor": page.NextCursor,
 	})
 }
 
-func (h TodoHandler) GetTodo(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
+// GetTrash 는 휴지통의 todo 목록을 조회한다. 쿼리 파라미터는 GetTodos 와 같고 sort=deleted_at 을 추가로 지원한다.
+func (h TodoHandler) GetTrash(c *gin.Context) {
+	query, err := parseListQuery(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid todo Id",
-		})
+		writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidQuery, err.Error()))
+		return
 	}
 
-	todo, err := h.todoService.GetTodoById(id)
+	page, err := h.todos(c).GetTrash(query)
 	if err != nil {
-		if err == service.ErrTodoNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Todo not found",
-			})
-			return
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"todos":       page.Todos,
+		"next_cursor": page.NextCursor,
+	})
+}
+
+// parseListQuery 는 limit, cursor, completed, overdue, due_before, due_after, sort, order, q, tag, tag_match 쿼리 파라미터를 읽는다.
+// tag 는 여러 번 지정할 수 있다. (?tag=work&tag=home)
+func parseListQuery(c *gin.Context) (model.TodoListQuery, error) {
+	query := model.TodoListQuery{
+		Cursor:   c.Query("cursor"),
+		Sort:     c.Query("sort"),
+		Order:    strings.ToLower(c.Query("order")),
+		Q:        c.Query("q"),
+		Tags:     c.QueryArray("tag"),
+		TagMatch: strings.ToLower(c.Query("tag_match")),
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return query, errors.New("limit must be a positive integer")
+		}
+		query.Limit = limit
+	}
+
+	if v := c.Query("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, errors.New("completed must be true or false")
+		}
+		query.Completed = &completed
+	}
+
+	if v := c.Query("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, errors.New("overdue must be true or false")
 		}
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Fail to get todo",
-		})
+		query.Overdue = &overdue
+	}
+
+	var err error
+	if query.DueBefore, err = parseTimeQuery(c, "due_before"); err != nil {
+		return query, err
+	}
+	if query.DueAfter, err = parseTimeQuery(c, "due_after"); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+// parseTimeQuery 는 RFC 3339 시각 쿼리 파라미터를 읽는다. 없으면 nil 이다.
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+func (h TodoHandler) GetTodo(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	todo, err := h.todos(c).GetTodoById(id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setTodoETag(c, todo)
+	if ifNoneMatch(c, todo) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
@@ -76,54 +180,206 @@ func (h TodoHandler) GetTodo(c *gin.Context) {
 	})
 }
 
-func (h TodoHandler) UpdateTodo(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
+// GetOccurrences 는 반복 todo 의 현재 마감 이후 발생 시각을 limit 개(기본 10, 최대 100) 미리 보여준다.
+// 반복하지 않는 todo 는 빈 목록이다.
+func (h TodoHandler) GetOccurrences(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidQuery, "limit must be a positive integer"))
+			return
+		}
+	}
+
+	occurrences, err := h.todos(c).GetOccurrences(id, limit)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid todo Id",
-		})
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"occurrences": occurrences,
+	})
+}
+
+// ReplaceTodo 는 todo 전체를 교체한다. (PUT)
+func (h TodoHandler) ReplaceTodo(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
 	}
 
-	var req model.UpdateTodoRequest
+	var req model.ReplaceTodoRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTodoBodyBytes)
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		writeBindError(c, err)
 		return
 	}
 
-	todo, err := h.todoService.UpdateTodo(id, req.Title, req.Description, req.Completed)
+	todo, err := h.todos(c).ReplaceTodo(id, ifMatch(c), req.Title, req.Description, req.Completed,
+		service.WithPriority(req.Priority), service.WithList(req.ListID), service.WithAutoComplete(req.AutoComplete),
+		service.WithSchedule(req.DueAt, req.RemindAt), service.WithRecurrence(req.RRule))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Fail to update todo",
-		})
+		writeError(c, err)
 		return
 	}
 
+	setTodoETag(c, todo)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Todo updated successfully",
 		"todo":    todo,
 	})
 }
 
-func (h TodoHandler) DeleteTodo(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
+// acceptPatch 는 PATCH 가 지원하는 media type 목록 (RFC 5789 Accept-Patch)
+var acceptPatch = strings.Join([]string{patch.MergePatchContentType, patch.JSONPatchContentType}, ", ")
+
+// PatchTodo 는 merge patch 또는 JSON Patch 로 todo 를 부분 수정한다. (PATCH)
+func (h TodoHandler) PatchTodo(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Accept-Patch", acceptPatch)
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxTodoBodyBytes))
+	if err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	p, supported, err := patch.Decode(c.ContentType(), body)
+	if !supported {
+		writeProblem(c, newProblem(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType,
+			"Content-Type must be one of: "+acceptPatch))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid todo Id",
-		})
+		writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidPatch, err.Error()))
+		return
+	}
+
+	todo, err := h.todos(c).PatchTodo(id, ifMatch(c), p)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setTodoETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Todo updated successfully",
+		"todo":    todo,
+	})
+}
+
+func (h TodoHandler) DeleteTodo(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
 	}
 
-	err = h.todoService.DeleteTodo(id)
+	err := h.todos(c).DeleteTodo(id, ifMatch(c))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Fail to delete todo",
-		})
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Todo deleted successfully",
 	})
 }
+
+// MoveTodo 는 todo 를 다른 todo 의 바로 뒤(after_id) 또는 바로 앞(before_id)으로 옮긴다.
+// 옮겨진 todo 의 position 만 바뀌므로 sort=position 목록에서 바로 반영된다.
+func (h TodoHandler) MoveTodo(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	var req model.MoveTodoRequest
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxTodoBodyBytes)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeBindError(c, err)
+		return
+	}
+
+	todo, err := h.todos(c).MoveTodo(id, ifMatch(c), int(req.AfterID), int(req.BeforeID))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setTodoETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Todo moved successfully",
+		"todo":    todo,
+	})
+}
+
+// RestoreTodo 는 휴지통의 todo 를 되돌린다.
+func (h TodoHandler) RestoreTodo(c *gin.Context) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+
+	todo, err := h.todos(c).RestoreTodo(id, ifMatch(c))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setTodoETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Todo restored successfully",
+		"todo":    todo,
+	})
+}
+
+// AttachTag 는 todo 에 태그를 붙인다. 이미 붙어 있어도 성공한다. (PUT)
+func (h TodoHandler) AttachTag(c *gin.Context) {
+	h.changeTag(c, h.todos(c).AttachTag)
+}
+
+// DetachTag 는 todo 에서 태그를 뗀다. 붙어 있지 않아도 성공한다.
+func (h TodoHandler) DetachTag(c *gin.Context) {
+	h.changeTag(c, h.todos(c).DetachTag)
+}
+
+func (h TodoHandler) changeTag(c *gin.Context, change func(id int, ifMatch service.Precondition, tagID int) (*model.Todo, error)) {
+	id, ok := parseTodoID(c)
+	if !ok {
+		return
+	}
+	tagID, ok := parseTagID(c, "tag_id")
+	if !ok {
+		return
+	}
+
+	todo, err := change(id, ifMatch(c), tagID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	setTodoETag(c, todo)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Todo updated successfully",
+		"todo":    todo,
+	})
+}
+
+// parseTodoID 는 :id 경로 파라미터를 읽고, 잘못된 값이면 400 을 응답한 뒤 false 를 반환한다.
+func parseTodoID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		writeProblem(c, newProblem(http.StatusBadRequest, CodeInvalidTodoID, "Todo id must be a positive integer"))
+		return 0, false
+	}
+	return id, true
+}
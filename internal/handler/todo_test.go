@@ -0,0 +1,615 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/middleware"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testUserID 는 newTestRouter 로 보낸 요청의 사용자
+const testUserID = 1
+
+// newTestRouter: 메모리 저장소 기반 라우터 테스트 픽스쳐. 모든 요청은 testUserID 사용자로 인증된다.
+// 반환된 TodoService 도 testUserID 사용자의 todo 만 다룬다.
+func newTestRouter() (*gin.Engine, *service.TodoService) {
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryTodoRepository()
+	todoService := service.NewTodoService(repo)
+	r := newRouter(repo, todoService, nil, func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, int64(testUserID))
+		c.Next()
+	})
+	return r, todoService.ForUser(testUserID)
+}
+
+// newRouter 는 cmd/server 와 같은 route 를 등록한다. authenticate 는 가입 / 로그인 외의 route 에 적용된다.
+func newRouter(repo *repository.MemoryTodoRepository, todoService *service.TodoService, tokens TokenIssuer, authenticate gin.HandlerFunc) *gin.Engine {
+	todoHandler := NewTodoHandler(todoService)
+	tagHandler := NewTagHandler(service.NewTagService(repo))
+	listHandler := NewListHandler(service.NewListService(repo), todoService)
+
+	r := gin.New()
+	authHandler := NewAuthHandler(service.NewUserService(repo), tokens)
+	r.POST("/api/v1/auth/register", authHandler.Register)
+	r.POST("/api/v1/auth/login", authHandler.Login)
+
+	api := r.Group("/api/v1", authenticate, middleware.RequireUser())
+	todos := api.Group("/todos")
+	todos.POST("", todoHandler.CreateTodo)
+	todos.GET("", todoHandler.GetTodos)
+	todos.GET("/trash", todoHandler.GetTrash)
+	todos.GET("/:id", todoHandler.GetTodo)
+	todos.PUT("/:id", todoHandler.ReplaceTodo)
+	todos.PATCH("/:id", todoHandler.PatchTodo)
+	todos.DELETE("/:id", todoHandler.DeleteTodo)
+	todos.POST("/:id/restore", todoHandler.RestoreTodo)
+	todos.POST("/:id/move", todoHandler.MoveTodo)
+	todos.GET("/:id/occurrences", todoHandler.GetOccurrences)
+	todos.PUT("/:id/tags/:tag_id", todoHandler.AttachTag)
+	todos.DELETE("/:id/tags/:tag_id", todoHandler.DetachTag)
+	todos.GET("/:id/items", todoHandler.GetItems)
+	todos.POST("/:id/items", todoHandler.CreateItem)
+	todos.PUT("/:id/items/:item_id", todoHandler.ReplaceItem)
+	todos.DELETE("/:id/items/:item_id", todoHandler.DeleteItem)
+	todos.POST("/:id/items/:item_id/move", todoHandler.MoveItem)
+	api.POST("/todos:method", CustomMethods(map[string]gin.HandlerFunc{
+		"batch": todoHandler.BatchTodos,
+	}))
+	tags := api.Group("/tags")
+	tags.GET("", tagHandler.GetTags)
+	tags.POST("", tagHandler.CreateTag)
+	tags.PUT("/:id", tagHandler.RenameTag)
+	tags.DELETE("/:id", tagHandler.DeleteTag)
+	lists := api.Group("/lists")
+	lists.GET("", listHandler.GetLists)
+	lists.POST("", listHandler.CreateList)
+	lists.GET("/:id", listHandler.GetList)
+	lists.PUT("/:id", listHandler.RenameList)
+	lists.DELETE("/:id", listHandler.DeleteList)
+	lists.GET("/:id/todos", listHandler.GetListTodos)
+
+	return r
+}
+
+func doRequest(r http.Handler, method, path string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	// headers: "이름", "값" 쌍
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// doPatch: Content-Type 을 지정해 PATCH 요청을 보내는 테스트 헬퍼
+func doPatch(r http.Handler, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// decodeProblem: problem+json 응답을 읽는 테스트 헬퍼
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, w.Code, problem.Status)
+	return problem
+}
+
+func TestTodoHandler(t *testing.T) {
+	t.Run("Create Todo", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title":       "dummy title",
+			"description": "desc",
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Message string     `json:"message"`
+			Todo    model.Todo `json:"todo"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "Todo created successfully", resp.Message)
+
+		stored, err := todoService.GetTodoById(int(resp.Todo.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "dummy title", stored.Title)
+	})
+
+	t.Run("Create Todo with empty title", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title": "",
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		problem := decodeProblem(t, w)
+		assert.Equal(t, CodeValidationFailed, problem.Code)
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, "title", problem.Errors[0].Field)
+		assert.Equal(t, "required", problem.Errors[0].Code)
+	})
+
+	t.Run("Create Todo Reports All Field Violations Together", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title":       strings.Repeat("a", model.MaxTodoTitleLength+1),
+			"description": "bell\a",
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		problem := decodeProblem(t, w)
+		assert.Equal(t, CodeValidationFailed, problem.Code)
+		codes := map[string]string{}
+		for _, fieldErr := range problem.Errors {
+			codes[fieldErr.Field] = fieldErr.Code
+		}
+		assert.Equal(t, map[string]string{"title": "max", "description": "nocontrol"}, codes)
+	})
+
+	t.Run("Create Todo With Blank Or Multiline Title", func(t *testing.T) {
+		for title, code := range map[string]string{
+			"   ":         "notblank",
+			"line\nbreak": "singleline",
+		} {
+			t.Run(code, func(t *testing.T) {
+				// Arrange
+				r, _ := newTestRouter()
+
+				// Act
+				w := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{"title": title})
+
+				// Assert
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				problem := decodeProblem(t, w)
+				require.Len(t, problem.Errors, 1)
+				assert.Equal(t, code, problem.Errors[0].Code)
+			})
+		}
+	})
+
+	t.Run("Create Todo With Oversized Body Should Return 413", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title": strings.Repeat("a", maxTodoBodyBytes),
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Equal(t, CodeRequestTooLarge, decodeProblem(t, w).Code)
+	})
+
+	t.Run("Update Todo With Blank Title Should Return 400", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		todo, err := todoService.CreateTodo("title", "")
+		require.NoError(t, err)
+
+		// Act
+		w := doRequest(r, http.MethodPut, "/api/v1/todos/"+strconv.FormatInt(todo.ID, 10), map[string]interface{}{
+			"title": " ",
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, CodeValidationFailed, decodeProblem(t, w).Code)
+	})
+
+	t.Run("Create Todo with malformed body", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/todos", bytes.NewBufferString("{"))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		// Act
+		r.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, CodeInvalidRequestBody, decodeProblem(t, w).Code)
+	})
+
+	t.Run("Replace todo", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		created, err := todoService.CreateTodo("dummy title", "dummy desc")
+		require.NoError(t, err)
+
+		// Act
+		w := doRequest(r, http.MethodPut, "/api/v1/todos/"+strconv.Itoa(int(created.ID)), map[string]interface{}{
+			"title": "updated title",
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		stored, err := todoService.GetTodoById(int(created.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "updated title", stored.Title)
+		assert.Empty(t, stored.Description, "PUT replaces omitted fields with empty values")
+	})
+
+	t.Run("Patch todo", func(t *testing.T) {
+		patches := map[string]string{
+			"application/merge-patch+json": `{"title": "updated title", "description": null}`,
+			"application/json-patch+json":  `[{"op": "replace", "path": "/title", "value": "updated title"}, {"op": "remove", "path": "/description"}]`,
+		}
+		for contentType, body := range patches {
+			t.Run(contentType, func(t *testing.T) {
+				// Arrange
+				r, todoService := newTestRouter()
+				created, err := todoService.CreateTodo("dummy title", "dummy desc")
+				require.NoError(t, err)
+
+				// Act
+				w := doPatch(r, "/api/v1/todos/"+strconv.Itoa(int(created.ID)), contentType, body)
+
+				// Assert
+				assert.Equal(t, http.StatusOK, w.Code)
+				stored, err := todoService.GetTodoById(int(created.ID))
+				require.NoError(t, err)
+				assert.Equal(t, "updated title", stored.Title)
+				assert.Empty(t, stored.Description)
+				assert.False(t, stored.Completed)
+			})
+		}
+	})
+
+	t.Run("Patch todo errors", func(t *testing.T) {
+		r, todoService := newTestRouter()
+		created, err := todoService.CreateTodo("dummy title", "dummy desc")
+		require.NoError(t, err)
+		path := "/api/v1/todos/" + strconv.Itoa(int(created.ID))
+
+		cases := map[string]struct {
+			contentType string
+			body        string
+			status      int
+			code        string
+		}{
+			"unsupported media type": {"application/json", `{"title": "x"}`, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType},
+			"malformed patch":        {"application/json-patch+json", `{"op": "replace"}`, http.StatusBadRequest, CodeInvalidPatch},
+			"failed test operation":  {"application/json-patch+json", `[{"op": "test", "path": "/completed", "value": true}]`, http.StatusConflict, CodePatchConflict},
+			"invalid result":         {"application/merge-patch+json", `{"title": null}`, http.StatusBadRequest, CodeValidationFailed},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Act
+				w := doPatch(r, path, tc.contentType, tc.body)
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+				assert.Equal(t, tc.code, decodeProblem(t, w).Code)
+				assert.NotEmpty(t, w.Header().Get("Accept-Patch"))
+			})
+		}
+	})
+
+	t.Run("Get Todo Returns ETag And Honors If-None-Match", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		created, err := todoService.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		path := "/api/v1/todos/" + strconv.Itoa(int(created.ID))
+
+		// Act
+		first := doRequest(r, http.MethodGet, path, nil)
+		etag := first.Header().Get("ETag")
+		notModified := doRequest(r, http.MethodGet, path, nil, "If-None-Match", "W/"+etag)
+		modified := doRequest(r, http.MethodGet, path, nil, "If-None-Match", `"999"`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, `"1"`, etag)
+		assert.Equal(t, http.StatusNotModified, notModified.Code)
+		assert.Empty(t, notModified.Body.String())
+		assert.Equal(t, etag, notModified.Header().Get("ETag"))
+		assert.Equal(t, http.StatusOK, modified.Code)
+	})
+
+	t.Run("Update With If-Match", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		created, err := todoService.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		path := "/api/v1/todos/" + strconv.Itoa(int(created.ID))
+
+		// Act
+		replaced := doRequest(r, http.MethodPut, path, map[string]interface{}{"title": "first"}, "If-Match", `"1"`)
+		stale := doRequest(r, http.MethodPut, path, map[string]interface{}{"title": "second"}, "If-Match", `"1"`)
+		weak := doRequest(r, http.MethodPut, path, map[string]interface{}{"title": "second"}, "If-Match", `W/"2"`)
+		staleDelete := doRequest(r, http.MethodDelete, path, nil, "If-Match", `"1"`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, replaced.Code)
+		assert.Equal(t, `"2"`, replaced.Header().Get("ETag"))
+		assert.Equal(t, http.StatusPreconditionFailed, stale.Code)
+		assert.Equal(t, CodePreconditionFailed, decodeProblem(t, stale).Code)
+		assert.Equal(t, http.StatusPreconditionFailed, weak.Code, "If-Match uses strong comparison")
+		assert.Equal(t, http.StatusPreconditionFailed, staleDelete.Code)
+
+		stored, err := todoService.GetTodoById(int(created.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "first", stored.Title)
+	})
+
+	t.Run("Patch And Delete With Matching If-Match", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		created, err := todoService.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		path := "/api/v1/todos/" + strconv.Itoa(int(created.ID))
+
+		// Act
+		req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(`{"completed": true}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", `"0", "1"`)
+		patched := httptest.NewRecorder()
+		r.ServeHTTP(patched, req)
+		deleted := doRequest(r, http.MethodDelete, path, nil, "If-Match", patched.Header().Get("ETag"))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, patched.Code)
+		assert.Equal(t, `"2"`, patched.Header().Get("ETag"))
+		assert.Equal(t, http.StatusOK, deleted.Code)
+	})
+
+	t.Run("Get Todos Returns Next Cursor", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		for i := 0; i < 3; i++ {
+			_, err := todoService.CreateTodo("dummy title", "")
+			require.NoError(t, err)
+		}
+
+		// Act
+		w := doRequest(r, http.MethodGet, "/api/v1/todos?limit=2&sort=updated_at&order=asc", nil)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Todos      []model.Todo `json:"todos"`
+			NextCursor string       `json:"next_cursor"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Todos, 2)
+		assert.NotEmpty(t, resp.NextCursor)
+	})
+
+	t.Run("Search Todos Returns Match With Highlights", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		_, err := todoService.CreateTodo("주말 장보기", "우유, 달걀")
+		require.NoError(t, err)
+		_, err = todoService.CreateTodo("운동하기", "")
+		require.NoError(t, err)
+
+		// Act
+		w := doRequest(r, http.MethodGet, "/api/v1/todos?q="+url.QueryEscape("장보기 우유"), nil)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Todos []model.Todo `json:"todos"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Todos, 1)
+		require.NotNil(t, resp.Todos[0].Match)
+		assert.Equal(t, "주말 <mark>장보기</mark>", resp.Todos[0].Match.Highlights["title"])
+		assert.Equal(t, "<mark>우유</mark>, 달걀", resp.Todos[0].Match.Highlights["description"])
+	})
+
+	t.Run("Create Todo With Schedule And Filter Overdue", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+		due := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+		remind := due.Add(-time.Hour)
+
+		// Act
+		createResp := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title":     "dummy title",
+			"due_at":    due.Format(time.RFC3339),
+			"remind_at": remind.Format(time.RFC3339),
+		})
+		invalidResp := doRequest(r, http.MethodPost, "/api/v1/todos", map[string]interface{}{
+			"title":     "dummy title",
+			"due_at":    remind.Format(time.RFC3339),
+			"remind_at": due.Format(time.RFC3339),
+		})
+		overdueResp := doRequest(r, http.MethodGet, "/api/v1/todos?overdue=true", nil)
+
+		// Assert
+		require.Equal(t, http.StatusOK, createResp.Code)
+		var created struct {
+			Todo model.Todo `json:"todo"`
+		}
+		require.NoError(t, json.Unmarshal(createResp.Body.Bytes(), &created))
+		require.NotNil(t, created.Todo.DueAt)
+		assert.True(t, due.Equal(*created.Todo.DueAt))
+
+		assert.Equal(t, http.StatusBadRequest, invalidResp.Code)
+		problem := decodeProblem(t, invalidResp)
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, "remind_at", problem.Errors[0].Field)
+
+		require.Equal(t, http.StatusOK, overdueResp.Code)
+		var page struct {
+			Todos []model.Todo `json:"todos"`
+		}
+		require.NoError(t, json.Unmarshal(overdueResp.Body.Bytes(), &page))
+		require.Len(t, page.Todos, 1)
+		assert.Equal(t, created.Todo.ID, page.Todos[0].ID)
+	})
+
+	t.Run("Move Todo", func(t *testing.T) {
+		// Arrange
+		r, todoService := newTestRouter()
+		first, err := todoService.CreateTodo("first", "", service.WithPriority(model.PriorityUrgent))
+		require.NoError(t, err)
+		second, err := todoService.CreateTodo("second", "")
+		require.NoError(t, err)
+		path := "/api/v1/todos/" + strconv.FormatInt(second.ID, 10) + "/move"
+
+		// Act
+		invalidResp := doRequest(r, http.MethodPost, path, map[string]interface{}{})
+		moveResp := doRequest(r, http.MethodPost, path, map[string]interface{}{"before_id": first.ID})
+		listResp := doRequest(r, http.MethodGet, "/api/v1/todos?sort=position", nil)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, invalidResp.Code)
+		assert.Equal(t, CodeInvalidMove, decodeProblem(t, invalidResp).Code)
+
+		require.Equal(t, http.StatusOK, moveResp.Code)
+		assert.Equal(t, `"2"`, moveResp.Header().Get("ETag"))
+
+		var page struct {
+			Todos []model.Todo `json:"todos"`
+		}
+		require.NoError(t, json.Unmarshal(listResp.Body.Bytes(), &page))
+		require.Len(t, page.Todos, 2)
+		assert.Equal(t, second.ID, page.Todos[0].ID)
+		assert.Equal(t, model.PriorityUrgent, page.Todos[1].Priority)
+	})
+
+	t.Run("Get Todos With Invalid Query Should Return 400", func(t *testing.T) {
+		r, _ := newTestRouter()
+
+		for _, rawQuery := range []string{"limit=abc", "completed=maybe", "sort=id", "cursor=bogus", "sort=relevance",
+			"overdue=soon", "due_before=tomorrow", "sort=position&cursor=bogus"} {
+			w := doRequest(r, http.MethodGet, "/api/v1/todos?"+rawQuery, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+		}
+	})
+
+	t.Run("Get Not Exist Todo Should Return 404", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodGet, "/api/v1/todos/999", nil)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		problem := decodeProblem(t, w)
+		assert.Equal(t, CodeTodoNotFound, problem.Code)
+		assert.Equal(t, "/api/v1/todos/999", problem.Instance)
+	})
+
+	t.Run("Update And Delete Not Exist Todo Should Return 404", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		updateResp := doRequest(r, http.MethodPut, "/api/v1/todos/999", map[string]interface{}{"title": "x"})
+		deleteResp := doRequest(r, http.MethodDelete, "/api/v1/todos/999", nil)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, updateResp.Code)
+		assert.Equal(t, CodeTodoNotFound, decodeProblem(t, updateResp).Code)
+		assert.Equal(t, http.StatusNotFound, deleteResp.Code)
+		assert.Equal(t, CodeTodoNotFound, decodeProblem(t, deleteResp).Code)
+	})
+
+	t.Run("Trash And Restore", func(t *testing.T) {
+		// Arrange
+		r, svc := newTestRouter()
+		created, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		path := "/api/v1/todos/" + strconv.FormatInt(created.ID, 10)
+		require.Equal(t, http.StatusOK, doRequest(r, http.MethodDelete, path, nil).Code)
+
+		// Act
+		getResp := doRequest(r, http.MethodGet, path, nil)
+		trashResp := doRequest(r, http.MethodGet, "/api/v1/todos/trash", nil)
+		staleResp := doRequest(r, http.MethodPost, path+"/restore", nil, "If-Match", `"1"`)
+		restoreResp := doRequest(r, http.MethodPost, path+"/restore", nil, "If-Match", `"2"`)
+		againResp := doRequest(r, http.MethodPost, path+"/restore", nil)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, getResp.Code)
+
+		require.Equal(t, http.StatusOK, trashResp.Code)
+		var trash struct {
+			Todos []model.Todo `json:"todos"`
+		}
+		require.NoError(t, json.Unmarshal(trashResp.Body.Bytes(), &trash))
+		require.Len(t, trash.Todos, 1)
+		assert.Equal(t, created.ID, trash.Todos[0].ID)
+		assert.NotNil(t, trash.Todos[0].DeletedAt)
+
+		assert.Equal(t, http.StatusPreconditionFailed, staleResp.Code)
+		require.Equal(t, http.StatusOK, restoreResp.Code)
+		assert.Equal(t, `"3"`, restoreResp.Header().Get("ETag"))
+		assert.Equal(t, http.StatusNotFound, againResp.Code)
+		assert.Equal(t, http.StatusOK, doRequest(r, http.MethodGet, path, nil).Code)
+	})
+
+	t.Run("Get Todos Rejects Deleted At Sort Outside Trash", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		todosResp := doRequest(r, http.MethodGet, "/api/v1/todos?sort=deleted_at", nil)
+		trashResp := doRequest(r, http.MethodGet, "/api/v1/todos/trash?sort=deleted_at&order=asc", nil)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, todosResp.Code)
+		assert.Equal(t, CodeInvalidQuery, decodeProblem(t, todosResp).Code)
+		assert.Equal(t, http.StatusOK, trashResp.Code)
+	})
+
+	t.Run("Invalid Todo Id Should Return 400", func(t *testing.T) {
+		// Arrange
+		r, _ := newTestRouter()
+
+		// Act
+		w := doRequest(r, http.MethodGet, "/api/v1/todos/abc", nil)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, CodeInvalidTodoID, decodeProblem(t, w).Code)
+	})
+}
@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"integration-test-example/internal/problem"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 토큰 서명 방식
+const (
+	SigningHS256 = "HS256"
+	SigningRS256 = "RS256"
+)
+
+const (
+	defaultTokenTTL    = time.Hour
+	defaultJWKSRefresh = time.Minute
+	// minSecretLength 는 HS256 서명 키의 최소 byte 수 (SHA-256 출력 크기)
+	minSecretLength = 32
+)
+
+// CodeUnauthorized 는 인증이 없거나 올바르지 않은 요청의 에러 코드
+const CodeUnauthorized = "unauthorized"
+
+// ErrInvalidToken 은 토큰의 서명, 만료, 클레임이 올바르지 않을 때 반환된다.
+var ErrInvalidToken = errors.New("invalid token")
+
+// AuthConfig 는 config.json 의 JWT 인증 설정
+//
+// 로그인 토큰은 SigningMethod 로 서명하고, 검증은 키가 설정된 방식을 모두 받는다.
+// Secret 과 JWKSFile 을 함께 설정하면 HS256 에서 RS256 으로 옮기는 동안 두 방식의 토큰을 모두 받을 수 있다.
+type AuthConfig struct {
+	// SigningMethod 는 발급하는 토큰의 서명 방식: HS256 (기본) 또는 RS256
+	SigningMethod string `json:"signing_method"`
+	// Secret 은 HS256 서명 키 (32 byte 이상). 비어 있으면 HS256 토큰을 받지 않는다.
+	Secret string `json:"secret"`
+	// PrivateKeyFile 은 RS256 토큰을 서명하는 PEM 개인 키 파일, KeyID 는 토큰 헤더의 kid 로 쓰는 그 키의 ID
+	PrivateKeyFile string `json:"private_key_file"`
+	KeyID          string `json:"key_id"`
+	// JWKSFile 은 RS256 토큰을 kid 로 검증하는 공개 키 집합(JWKS) 파일. 비어 있으면 RS256 토큰을 받지 않는다.
+	// 파일이 바뀌면 다시 읽으므로 새 키 추가 → 서명 키 교체 → 옛 키 제거 순서로 재시작 없이 키를 교체할 수 있다.
+	JWKSFile string `json:"jwks_file"`
+	// JWKSRefreshSeconds 는 JWKS 파일이 바뀌었는지 확인하는 주기 (기본 60). 모르는 kid 의 토큰이 오면 바로 확인한다.
+	JWKSRefreshSeconds int `json:"jwks_refresh_seconds"`
+	// Issuer 가 설정되면 발급하는 토큰의 iss 로 쓰고, 검증할 때 iss 가 같아야 한다.
+	Issuer string `json:"issuer"`
+	// TokenTTLMinutes 는 발급한 토큰의 유효 기간 (기본 60)
+	TokenTTLMinutes int `json:"token_ttl_minutes"`
+}
+
+// String 은 설정을 로그에 남길 때 Secret 을 가린다.
+func (c AuthConfig) String() string {
+	if c.Secret != "" {
+		c.Secret = "[REDACTED]"
+	}
+	type plain AuthConfig
+	return fmt.Sprintf("%+v", plain(c))
+}
+
+// Authenticator 는 로그인한 사용자에게 JWT 를 발급하고, 요청의 Bearer 토큰을 검증해 사용자 ID 를 gin.Context 에 저장한다.
+type Authenticator struct {
+	signingMethod string
+	secret        []byte
+	privateKey    *rsa.PrivateKey
+	keyID         string
+	// keys 는 JWKSFile 이 설정된 경우에만 있다.
+	keys   *keySet
+	issuer string
+	ttl    time.Duration
+	parser *jwt.Parser
+}
+
+func NewAuthenticator(config AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		signingMethod: config.SigningMethod,
+		secret:        []byte(config.Secret),
+		keyID:         config.KeyID,
+		issuer:        config.Issuer,
+		ttl:           time.Duration(config.TokenTTLMinutes) * time.Minute,
+	}
+	if a.signingMethod == "" {
+		a.signingMethod = SigningHS256
+	}
+	if a.ttl <= 0 {
+		a.ttl = defaultTokenTTL
+	}
+
+	var methods []string
+	if len(a.secret) > 0 {
+		if len(a.secret) < minSecretLength {
+			return nil, fmt.Errorf("auth secret must be at least %d bytes", minSecretLength)
+		}
+		methods = append(methods, SigningHS256)
+	}
+	if config.JWKSFile != "" {
+		refresh := time.Duration(config.JWKSRefreshSeconds) * time.Second
+		if refresh <= 0 {
+			refresh = defaultJWKSRefresh
+		}
+		keys, err := loadKeySet(config.JWKSFile, refresh)
+		if err != nil {
+			return nil, fmt.Errorf("fail to load jwks file: %w", err)
+		}
+		a.keys = keys
+		methods = append(methods, SigningRS256)
+	}
+
+	switch a.signingMethod {
+	case SigningHS256:
+		if len(a.secret) == 0 {
+			return nil, errors.New("auth secret is required to sign HS256 tokens")
+		}
+	case SigningRS256:
+		if err := a.loadSigningKey(config.PrivateKeyFile); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing method: %q", a.signingMethod)
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if a.issuer != "" {
+		options = append(options, jwt.WithIssuer(a.issuer))
+	}
+	a.parser = jwt.NewParser(options...)
+	return a, nil
+}
+
+// loadSigningKey 는 RS256 서명 키를 읽는다. 발급한 토큰을 스스로 검증할 수 있도록 공개 키가 JWKS 에 있어야 한다.
+func (a *Authenticator) loadSigningKey(path string) error {
+	if path == "" || a.keyID == "" || a.keys == nil {
+		return errors.New("private_key_file, key_id and jwks_file are required to sign RS256 tokens")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("fail to read private key file: %w", err)
+	}
+	if a.privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(data); err != nil {
+		return fmt.Errorf("fail to parse private key: %w", err)
+	}
+
+	publicKey, err := a.keys.key(a.keyID)
+	if err != nil || !publicKey.Equal(&a.privateKey.PublicKey) {
+		return fmt.Errorf("jwks file has no public key for signing key %q", a.keyID)
+	}
+	return nil
+}
+
+// IssueToken 은 userID 사용자의 access token 과 만료 시각을 발급한다.
+func (a *Authenticator) IssueToken(userID int64) (string, time.Time, error) {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.FormatInt(userID, 10),
+		Issuer:    a.issuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(a.ttl)),
+	}
+
+	var (
+		signed string
+		err    error
+	)
+	if a.signingMethod == SigningRS256 {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = a.keyID
+		signed, err = token.SignedString(a.privateKey)
+	} else {
+		signed, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, claims.ExpiresAt.Time, nil
+}
+
+// Authenticate 는 Authorization 헤더의 Bearer 토큰을 검증하고 사용자 ID(int64)를 UserIDKey 로 저장한다.
+// 헤더가 없으면 익명 요청으로 그대로 처리하고, 토큰이 올바르지 않으면 401 로 응답한다.
+// 사용자별 rate limit 이 동작하도록 RateLimit 보다 먼저 등록한다.
+func (a *Authenticator) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			unauthorized(c, `Bearer error="invalid_request"`, "Authorization header must be a Bearer token")
+			return
+		}
+		userID, err := a.parseToken(strings.TrimSpace(token))
+		if err != nil {
+			unauthorized(c, `Bearer error="invalid_token"`, "Access token is invalid or expired")
+			return
+		}
+
+		c.Set(UserIDKey, userID)
+		c.Next()
+	}
+}
+
+// RequireUser 는 Authenticate 로 인증되지 않은 요청을 401 로 거부한다.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentUserID(c); !ok {
+			unauthorized(c, "Bearer", "Authentication is required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentUserID 는 Authenticate 가 저장한 사용자 ID 를 반환한다.
+func CurrentUserID(c *gin.Context) (int64, bool) {
+	userID, ok := c.Get(UserIDKey)
+	if !ok {
+		return 0, false
+	}
+	id, ok := userID.(int64)
+	return id, ok
+}
+
+func unauthorized(c *gin.Context, challenge, message string) {
+	c.Header("WWW-Authenticate", challenge)
+	problem.Write(c, problem.New(http.StatusUnauthorized, CodeUnauthorized, message))
+}
+
+// parseToken 은 토큰을 검증하고 subject 의 사용자 ID 를 반환한다.
+func (a *Authenticator) parseToken(tokenString string) (int64, error) {
+	var claims jwt.RegisteredClaims
+	if _, err := a.parser.ParseWithClaims(tokenString, &claims, a.verificationKey); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, fmt.Errorf("%w: subject must be a user id", ErrInvalidToken)
+	}
+	return userID, nil
+}
+
+// verificationKey 는 토큰의 서명 방식에 맞는 검증 키를 반환한다.
+// 서명 방식은 parser 가 설정된 방식인지 먼저 확인하므로, 다른 방식의 키로 검증되는 일(algorithm confusion)은 없다.
+func (a *Authenticator) verificationKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case SigningHS256:
+		return a.secret, nil
+	case SigningRS256:
+		kid, _ := token.Header["kid"].(string)
+		return a.keys.key(kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+	}
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func newAuthRouter(auth *Authenticator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(auth.Authenticate(), RequireUser())
+	r.GET("/me", func(c *gin.Context) {
+		userID, _ := CurrentUserID(c)
+		c.String(http.StatusOK, "%d", userID)
+	})
+	return r
+}
+
+func getWithToken(r http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// writeJWKS: 공개 키를 kid 별로 JWKS 파일에 쓰고, 다시 읽히도록 수정 시각을 바꾸는 테스트 헬퍼
+func writeJWKS(t *testing.T, path string, modTime time.Time, keys map[string]*rsa.PublicKey) {
+	t.Helper()
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: SigningRS256,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, userID int64) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Subject:   fmt.Sprint(userID),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestAuthenticator(t *testing.T) {
+	t.Run("HS256 Token Sets User", func(t *testing.T) {
+		// Arrange
+		auth, err := NewAuthenticator(AuthConfig{Secret: testSecret, Issuer: "todo-api"})
+		require.NoError(t, err)
+		r := newAuthRouter(auth)
+		token, expiresAt, err := auth.IssueToken(42)
+		require.NoError(t, err)
+
+		// Act
+		w := getWithToken(r, token)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "42", w.Body.String())
+		assert.WithinDuration(t, time.Now().Add(defaultTokenTTL), expiresAt, time.Minute)
+	})
+
+	t.Run("Rejects Missing And Invalid Tokens", func(t *testing.T) {
+		// Arrange
+		auth, err := NewAuthenticator(AuthConfig{Secret: testSecret, Issuer: "todo-api"})
+		require.NoError(t, err)
+		r := newAuthRouter(auth)
+		sign := func(secret string, claims jwt.RegisteredClaims) string {
+			signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+			require.NoError(t, err)
+			return signed
+		}
+		valid := jwt.RegisteredClaims{Subject: "42", Issuer: "todo-api", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+		expired, otherIssuer, noExpiry, badSubject := valid, valid, valid, valid
+		expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		otherIssuer.Issuer = "someone-else"
+		noExpiry.ExpiresAt = nil
+		badSubject.Subject = "alice"
+
+		unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		for name, token := range map[string]string{
+			"Missing":        "",
+			"Malformed":      "not-a-jwt",
+			"Wrong Secret":   sign("fedcba9876543210fedcba9876543210", valid),
+			"Expired":        sign(testSecret, expired),
+			"Other Issuer":   sign(testSecret, otherIssuer),
+			"No Expiry":      sign(testSecret, noExpiry),
+			"Bad Subject":    sign(testSecret, badSubject),
+			"None Algorithm": unsigned,
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Act
+				w := getWithToken(r, token)
+
+				// Assert
+				assertProblem(t, w, http.StatusUnauthorized, CodeUnauthorized)
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+			})
+		}
+	})
+
+	t.Run("RS256 Keys Rotate Through JWKS File", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		privateKeyFile := filepath.Join(dir, "signing.pem")
+		pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(oldKey)})
+		require.NoError(t, os.WriteFile(privateKeyFile, pemBytes, 0o600))
+		jwksFile := filepath.Join(dir, "jwks.json")
+		modTime := time.Now().Add(-time.Hour)
+		writeJWKS(t, jwksFile, modTime, map[string]*rsa.PublicKey{"old": &oldKey.PublicKey})
+
+		auth, err := NewAuthenticator(AuthConfig{
+			SigningMethod:  SigningRS256,
+			PrivateKeyFile: privateKeyFile,
+			KeyID:          "old",
+			JWKSFile:       jwksFile,
+		})
+		require.NoError(t, err)
+		r := newAuthRouter(auth)
+		oldToken, _, err := auth.IssueToken(1)
+		require.NoError(t, err)
+		newToken := signRS256(t, newKey, "new", 2)
+
+		// Act & Assert: 새 키가 JWKS 에 추가되기 전에는 받지 않는다.
+		assert.Equal(t, http.StatusOK, getWithToken(r, oldToken).Code)
+		assert.Equal(t, http.StatusUnauthorized, getWithToken(r, newToken).Code)
+
+		// 새 키를 추가하면 모르는 kid 로 다시 읽어 바로 받는다.
+		writeJWKS(t, jwksFile, modTime.Add(time.Minute), map[string]*rsa.PublicKey{
+			"old": &oldKey.PublicKey,
+			"new": &newKey.PublicKey,
+		})
+		w := getWithToken(r, newToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Body.String())
+		assert.Equal(t, http.StatusOK, getWithToken(r, oldToken).Code)
+
+		// 옛 키를 빼면 확인 주기가 지난 뒤부터 옛 키의 토큰을 받지 않는다.
+		writeJWKS(t, jwksFile, modTime.Add(2*time.Minute), map[string]*rsa.PublicKey{"new": &newKey.PublicKey})
+		auth.keys.checkedAt = time.Now().Add(-defaultJWKSRefresh)
+		assert.Equal(t, http.StatusUnauthorized, getWithToken(r, oldToken).Code)
+		assert.Equal(t, http.StatusOK, getWithToken(r, newToken).Code)
+	})
+
+	t.Run("HS256 Token Is Not Verified With RSA Public Key", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		jwksFile := filepath.Join(dir, "jwks.json")
+		writeJWKS(t, jwksFile, time.Now(), map[string]*rsa.PublicKey{"k1": &key.PublicKey})
+		privateKeyFile := filepath.Join(dir, "signing.pem")
+		require.NoError(t, os.WriteFile(privateKeyFile,
+			pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600))
+		auth, err := NewAuthenticator(AuthConfig{
+			SigningMethod:  SigningRS256,
+			PrivateKeyFile: privateKeyFile,
+			KeyID:          "k1",
+			JWKSFile:       jwksFile,
+		})
+		require.NoError(t, err)
+
+		publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+			Subject:   "1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+		forged.Header["kid"] = "k1"
+		token, err := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+		require.NoError(t, err)
+
+		// Act
+		w := getWithToken(newAuthRouter(auth), token)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Rejects Invalid Config", func(t *testing.T) {
+		for name, config := range map[string]AuthConfig{
+			"Short Secret":              {Secret: "too short"},
+			"HS256 Without Secret":      {},
+			"Unknown Signing Method":    {SigningMethod: "ES256", Secret: testSecret},
+			"RS256 Without Signing Key": {SigningMethod: SigningRS256, Secret: testSecret},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Act
+				_, err := NewAuthenticator(config)
+
+				// Assert
+				assert.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("Config String Redacts Secret", func(t *testing.T) {
+		// Arrange
+		cfg := struct{ Auth AuthConfig }{Auth: AuthConfig{Secret: testSecret, Issuer: "todo-api"}}
+
+		// Act
+		logged := fmt.Sprint(&cfg)
+
+		// Assert
+		assert.NotContains(t, logged, testSecret)
+		assert.Contains(t, logged, "[REDACTED]")
+		assert.Contains(t, logged, "todo-api")
+	})
+}
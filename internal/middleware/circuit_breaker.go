@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker 는 연속 실패가 threshold 에 도달하면 cooldown 동안 호출을 차단한다.
+// cooldown 이 지나면 한 번의 시험 호출(half-open)을 허용하고, 성공하면 다시 닫힌다.
+// 시험 호출이 결과 없이 끝나면(클라이언트가 먼저 끊은 경우 등) cooldown 이 지난 뒤 새 시험 호출을 허용한다.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	state     circuitState
+	failures  int
+	openedAt  time.Time
+	trialAt   time.Time
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow 는 지금 호출을 시도해도 되는지 알려준다.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		// 시험 호출은 하나만 허용
+		b.state = circuitHalfOpen
+		b.trialAt = b.now()
+		return true
+	case circuitHalfOpen:
+		if b.now().Sub(b.trialAt) < b.cooldown {
+			return false
+		}
+		b.trialAt = b.now()
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = circuitClosed
+	b.failures = 0
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
@@ -0,0 +1,299 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"integration-test-example/internal/problem"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader 는 클라이언트가 재시도 시 같은 값을 보내는 요청 헤더
+	// (draft-ietf-httpapi-idempotency-key-header)
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 는 저장된 응답을 재생했을 때 설정하는 응답 헤더
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// Idempotency-Key 처리 실패 시의 에러 코드
+const (
+	CodeInvalidIdempotencyKey = "invalid_idempotency_key"
+	CodeRequestTooLarge       = "request_too_large"
+	CodeIdempotencyKeyReused  = "idempotency_key_reused"
+	CodeRequestInProgress     = "request_in_progress"
+)
+
+// IdempotencyConfig 는 config.json 의 idempotency 설정
+type IdempotencyConfig struct {
+	// TTLSeconds 동안 첫 응답을 저장하고 재시도에 재생한다. (기본 86400)
+	TTLSeconds int `json:"ttl_seconds"`
+	// LockTimeoutSeconds 는 처리 중인 요청의 lock 이 유지되는 최대 시간 (기본 30)
+	// 서버가 처리 도중 죽어도 이 시간이 지나면 같은 key 로 다시 시도할 수 있다.
+	LockTimeoutSeconds int `json:"lock_timeout_seconds"`
+}
+
+const (
+	defaultIdempotencyTTL         = 24 * time.Hour
+	defaultIdempotencyLockTimeout = 30 * time.Second
+
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodyBytes 는 fingerprint 를 계산하기 위해 미리 읽는 요청 바디의 최대 크기
+	maxIdempotentBodyBytes = 1 << 20
+)
+
+// replayedHeaders 는 응답 재생 시 함께 저장하는 헤더
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// Idempotency 는 Idempotency-Key 헤더가 있는 요청의 첫 응답을 Redis 에 저장하고 재시도에 재생한다.
+// 헤더가 없는 요청은 그대로 처리한다.
+type Idempotency struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+// idempotencyRecord 는 key 하나에 저장되는 상태.
+// 처리 중에는 Token 만 있고, 완료되면 응답이 채워진다.
+type idempotencyRecord struct {
+	Fingerprint string            `json:"fingerprint"`
+	Token       string            `json:"token,omitempty"`
+	Completed   bool              `json:"completed"`
+	Status      int               `json:"status,omitempty"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+}
+
+// completeIdempotencyScript 는 lock 을 잡은 요청(token 이 같은 경우)만 응답을 저장한다.
+// lock 이 만료되어 다른 요청이 key 를 가져간 뒤에는 덮어쓰지 않는다.
+//
+// KEYS[1] record 키
+// ARGV[1] lock token, ARGV[2] 완료된 record, ARGV[3] ttl(ms)
+var completeIdempotencyScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current or cjson.decode(current).token ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// releaseIdempotencyScript 는 응답을 저장하지 않을 때 자신이 잡은 lock 만 해제한다.
+var releaseIdempotencyScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and cjson.decode(current).token == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func NewIdempotency(redisClient *redis.Client, config IdempotencyConfig) *Idempotency {
+	ttl := time.Duration(config.TTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	lockTimeout := time.Duration(config.LockTimeoutSeconds) * time.Second
+	if lockTimeout <= 0 {
+		lockTimeout = defaultIdempotencyLockTimeout
+	}
+
+	return &Idempotency{
+		redisClient: redisClient,
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+	}
+}
+
+func (i *Idempotency) Idempotent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			problem.Write(c, problem.New(http.StatusBadRequest, CodeInvalidIdempotencyKey,
+				fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength)))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			problem.Write(c, problem.New(http.StatusRequestEntityTooLarge, CodeRequestTooLarge,
+				fmt.Sprintf("Request body must be at most %d bytes", maxIdempotentBodyBytes)))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		recordKey := idempotencyRecordKey(c, key)
+		fingerprint := requestFingerprint(c, body)
+		token := newLockToken()
+
+		ctx := c.Request.Context()
+		existing, err := i.acquire(ctx, recordKey, fingerprint, token)
+		if err != nil {
+			// Redis 에러 시 중복 방지 없이 처리 (rate limiter 와 같은 graceful degradation)
+			log.Printf("idempotency: %v", err)
+			c.Next()
+			return
+		}
+		if existing != nil {
+			i.respondExisting(c, existing, fingerprint)
+			return
+		}
+
+		// 처리가 끝나면 클라이언트가 끊었더라도 결과는 기록한다.
+		ctx = context.WithoutCancel(ctx)
+		defer func() {
+			// handler 가 panic 하면 lock 을 풀어 lock timeout 을 기다리지 않고 재시도할 수 있게 한다.
+			if p := recover(); p != nil {
+				_ = releaseIdempotencyScript.Run(ctx, i.redisClient, []string{recordKey}, token).Err()
+				panic(p)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if err := i.complete(ctx, recordKey, token, fingerprint, recorder); err != nil {
+			log.Printf("idempotency: %v", err)
+		}
+	}
+}
+
+// acquire 는 key 의 lock 을 잡는다. 이미 record 가 있으면 그 record 를 반환한다.
+func (i *Idempotency) acquire(ctx context.Context, recordKey, fingerprint, token string) (*idempotencyRecord, error) {
+	lock, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, Token: token})
+	if err != nil {
+		return nil, err
+	}
+
+	// lock 이 GET 직전에 만료되는 경우를 위해 한 번 더 시도한다.
+	for attempt := 0; attempt < 2; attempt++ {
+		acquired, err := i.redisClient.SetNX(ctx, recordKey, lock, i.lockTimeout).Result()
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			return nil, nil
+		}
+
+		data, err := i.redisClient.Get(ctx, recordKey).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var record idempotencyRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("decode record %s: %w", recordKey, err)
+		}
+		return &record, nil
+	}
+	return nil, fmt.Errorf("failed to acquire %s", recordKey)
+}
+
+// respondExisting 은 같은 key 의 이전 요청 상태에 따라 응답을 재생하거나 거부한다.
+func (i *Idempotency) respondExisting(c *gin.Context, record *idempotencyRecord, fingerprint string) {
+	switch {
+	case record.Fingerprint != fingerprint:
+		problem.Write(c, problem.New(http.StatusUnprocessableEntity, CodeIdempotencyKeyReused,
+			fmt.Sprintf("%s was already used for a different request", IdempotencyKeyHeader)))
+	case !record.Completed:
+		c.Header("Retry-After", "1")
+		problem.Write(c, problem.New(http.StatusConflict, CodeRequestInProgress,
+			fmt.Sprintf("A request with the same %s is still being processed", IdempotencyKeyHeader)))
+	default:
+		for name, value := range record.Header {
+			c.Header(name, value)
+		}
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Status(record.Status)
+		_, _ = c.Writer.Write(record.Body)
+		c.Abort()
+	}
+}
+
+// complete 는 처리 결과를 저장한다. 5xx 응답은 일시적인 실패일 수 있으므로 저장하지 않고 lock 만 해제해 재시도를 허용한다.
+func (i *Idempotency) complete(ctx context.Context, recordKey, token, fingerprint string, recorder *responseRecorder) error {
+	status := recorder.Status()
+	if status >= http.StatusInternalServerError {
+		return releaseIdempotencyScript.Run(ctx, i.redisClient, []string{recordKey}, token).Err()
+	}
+
+	header := make(map[string]string, len(replayedHeaders))
+	for _, name := range replayedHeaders {
+		if value := recorder.Header().Get(name); value != "" {
+			header[name] = value
+		}
+	}
+	record, err := json.Marshal(idempotencyRecord{
+		Fingerprint: fingerprint,
+		Completed:   true,
+		Status:      status,
+		Header:      header,
+		Body:        recorder.body.Bytes(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return completeIdempotencyScript.Run(ctx, i.redisClient, []string{recordKey},
+		token, record, i.ttl.Milliseconds()).Err()
+}
+
+// idempotencyRecordKey 는 route 와 요청자별로 key 공간을 나눈다.
+// 인증 정보가 없으면 같은 key 를 보낸 모든 클라이언트가 key 공간을 공유한다.
+func idempotencyRecordKey(c *gin.Context, key string) string {
+	owner := "anonymous"
+	for _, source := range []KeySource{KeyByUser, KeyByAPIKey} {
+		if value := identityValue(c, source); value != "" {
+			owner = string(source) + ":" + value
+			break
+		}
+	}
+	return fmt.Sprintf("idempotency:%s:%s:%s:%s", c.Request.Method, c.FullPath(), owner, key)
+}
+
+// requestFingerprint 는 같은 key 로 다른 요청을 보냈는지 판별하기 위한 요청 해시
+func requestFingerprint(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func newLockToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// responseRecorder 는 응답을 클라이언트에 쓰면서 바디를 함께 기록한다.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
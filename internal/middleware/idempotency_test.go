@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newIdempotentRouter(calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	idempotency := NewIdempotency(newUnavailableRedis(), IdempotencyConfig{})
+
+	r := gin.New()
+	r.POST("/todos", idempotency.Idempotent(), func(c *gin.Context) {
+		*calls++
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	return r
+}
+
+func postWithKey(r http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("Request Without Key Is Processed Normally", func(t *testing.T) {
+		// Arrange
+		calls := 0
+		r := newIdempotentRouter(&calls)
+
+		// Act
+		w := postWithKey(r, "", `{"title":"a"}`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Too Long Key Is Rejected", func(t *testing.T) {
+		// Arrange
+		calls := 0
+		r := newIdempotentRouter(&calls)
+
+		// Act
+		w := postWithKey(r, strings.Repeat("k", maxIdempotencyKeyLength+1), `{}`)
+
+		// Assert
+		assertProblem(t, w, http.StatusBadRequest, CodeInvalidIdempotencyKey)
+		assert.Zero(t, calls)
+	})
+
+	t.Run("Redis Unavailable Processes Request With Body Intact", func(t *testing.T) {
+		// Arrange
+		calls := 0
+		r := newIdempotentRouter(&calls)
+
+		// Act
+		w := postWithKey(r, "key-1", `{"title":"a"}`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"title":"a"}`, w.Body.String())
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestIdempotencyRecordKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newContext := func(header string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/todos", nil)
+		if header != "" {
+			c.Request.Header.Set(APIKeyHeader, header)
+		}
+		return c
+	}
+
+	t.Run("Keys Are Scoped By Requester", func(t *testing.T) {
+		anonymous := idempotencyRecordKey(newContext(""), "k")
+		withAPIKey := idempotencyRecordKey(newContext("secret"), "k")
+
+		userContext := newContext("secret")
+		userContext.Set(UserIDKey, 42)
+		withUser := idempotencyRecordKey(userContext, "k")
+
+		assert.Contains(t, anonymous, ":anonymous:k")
+		assert.Contains(t, withAPIKey, ":api_key:secret:k")
+		assert.Contains(t, withUser, ":user:42:k")
+	})
+
+	t.Run("Fingerprint Depends On Body", func(t *testing.T) {
+		c := newContext("")
+
+		assert.Equal(t, requestFingerprint(c, []byte("a")), requestFingerprint(c, []byte("a")))
+		assert.NotEqual(t, requestFingerprint(c, []byte("a")), requestFingerprint(c, []byte("b")))
+	})
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// jwk 는 JWKS 의 키 하나 (RFC 7517). RS256 서명용 RSA 키만 사용한다.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// keySet 은 JWKS 파일의 공개 키를 kid 로 찾는다. 파일이 바뀌면 다시 읽어 키 교체를 반영한다.
+type keySet struct {
+	path    string
+	refresh time.Duration
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+	// modTime, size 는 마지막으로 읽은 파일의 상태. 둘 다 같으면 다시 읽지 않는다.
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+func loadKeySet(path string, refresh time.Duration) (*keySet, error) {
+	s := &keySet{path: path, refresh: refresh}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// key 는 kid 의 공개 키를 반환한다. refresh 주기가 지났거나 모르는 kid 면 먼저 파일이 바뀌었는지 확인한다.
+// 다시 읽는 데 실패하면 마지막으로 읽은 키를 계속 사용한다.
+func (s *keySet) key(kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[kid]
+	if !ok || time.Since(s.checkedAt) >= s.refresh {
+		if err := s.reload(); err != nil {
+			log.Printf("Failed to reload jwks file %s: %v", s.path, err)
+		}
+		key, ok = s.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// reload 는 파일이 바뀌었을 때만 키를 다시 읽는다. 호출자가 mu 를 잡은 상태에서 호출한다.
+func (s *keySet) reload() error {
+	s.checkedAt = time.Now()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+	if s.keys != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	s.keys, s.modTime, s.size = keys, info.ModTime(), info.Size()
+	return nil
+}
+
+// parseJWKS 는 JWKS 문서에서 RS256 서명용 RSA 공개 키를 kid 별로 읽는다. 다른 용도의 키는 건너뛴다.
+func parseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") || (k.Alg != "" && k.Alg != SigningRS256) {
+			continue
+		}
+		if k.Kid == "" {
+			return nil, errors.New("jwks: RSA key without kid")
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwks: key %q has invalid modulus: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwks: key %q has invalid exponent: %w", k.Kid, err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("jwks: key %q has unsupported exponent", k.Kid)
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks: no RSA signing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// localBucketIdleTTL 동안 사용되지 않은 bucket 은 정리된다.
+const localBucketIdleTTL = 10 * time.Minute
+
+// localLimiter 는 Redis 장애 시 사용하는 인스턴스 로컬 token bucket 모음.
+// 인스턴스마다 따로 세므로 전체 허용량은 인스턴스 수만큼 늘어난다.
+type localLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*localBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type localBucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+func newLocalLimiter() *localLimiter {
+	return &localLimiter{
+		buckets: make(map[string]*localBucket),
+		now:     time.Now,
+	}
+}
+
+// localRate 는 정책을 token bucket 파라미터(초당 rate, burst)로 환산한다.
+// window 계열 정책은 window 당 limit 을 평균 rate 로, limit 을 burst 로 본다.
+func localRate(rule Rule) (float64, int) {
+	if rule.Algorithm == TokenBucket {
+		return rule.Rate, rule.Burst
+	}
+	return float64(rule.Limit) / rule.Window.Seconds(), rule.Limit
+}
+
+func (l *localLimiter) allow(key string, rule Rule) *rateLimitResult {
+	rate, burst := localRate(rule)
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &localBucket{tokens: float64(burst), ts: now}
+		l.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.ts).Seconds()
+	bucket.tokens = math.Min(float64(burst), bucket.tokens+elapsed*rate)
+	bucket.ts = now
+
+	result := &rateLimitResult{limit: burst}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.allowed = true
+	} else {
+		result.retryAfter = secondsToDuration((1 - bucket.tokens) / rate)
+	}
+	result.remaining = int(bucket.tokens)
+	result.resetAfter = secondsToDuration((float64(burst) - bucket.tokens) / rate)
+
+	return result
+}
+
+// sweep 은 호출자가 mu 를 잡은 상태에서 오래 쓰이지 않은 bucket 을 정리한다.
+func (l *localLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < localBucketIdleTTL {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.ts) > localBucketIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+	"time"
+)
+
+// KeySource 는 rate limit 을 적용할 요청자 식별 방법
+type KeySource string
+
+const (
+	KeyByIP     KeySource = "ip"
+	KeyByAPIKey KeySource = "api_key"
+	KeyByUser   KeySource = "user"
+)
+
+const (
+	// APIKeyHeader 는 API key 로 요청자를 식별할 때 읽는 헤더
+	APIKeyHeader = "X-API-Key"
+	// UserIDKey 는 인증 미들웨어가 인증된 사용자 ID 를 gin.Context 에 저장하는 키
+	UserIDKey = "user_id"
+)
+
+// PolicyConfig 는 config.json 의 rate limit 정책 하나
+type PolicyConfig struct {
+	Name string `json:"name"`
+	// RoutePrefix 는 gin route 경로(예: /api/v1/todos/:id)의 prefix. 비어 있으면 모든 route 에 적용된다.
+	RoutePrefix string `json:"route_prefix"`
+	// Methods 가 비어 있으면 모든 method 에 적용된다.
+	Methods []string `json:"methods"`
+	// KeyBy 는 순서대로 시도해 처음 식별되는 값으로 요청자를 구분한다. (기본 ["ip"])
+	KeyBy []KeySource `json:"key_by"`
+
+	Algorithm     Algorithm `json:"algorithm"`
+	Limit         int       `json:"limit"`
+	WindowSeconds int       `json:"window_seconds"`
+	Rate          float64   `json:"rate"`
+	Burst         int       `json:"burst"`
+}
+
+// RateLimitConfig 는 config.json 의 rate_limit 설정
+type RateLimitConfig struct {
+	// Policies 는 위에서부터 처음 일치하는 정책 하나만 적용된다.
+	Policies []PolicyConfig `json:"policies"`
+	// Allowlist 항목은 "ip:127.0.0.1", "api_key:xxx", "user:42" 형식이며 일치하는 요청은 제한하지 않는다.
+	Allowlist []string `json:"allowlist"`
+	// APIKeys 는 api_key 로 요청자를 식별할 때 인정하는 key 목록. (Allowlist 의 api_key 항목도 포함된다)
+	// 목록에 없는 X-API-Key 는 요청마다 바꿔 제한을 피할 수 있으므로 무시하고 다음 key source 로 식별한다.
+	APIKeys []string `json:"api_keys"`
+
+	// FailureMode 는 Redis 를 사용할 수 없을 때의 동작 (기본 "open")
+	FailureMode FailureMode `json:"failure_mode"`
+	// RedisTimeoutMs 는 판정 한 번에 Redis 를 기다리는 최대 시간 (기본 100ms)
+	RedisTimeoutMs int                  `json:"redis_timeout_ms"`
+	CircuitBreaker CircuitBreakerConfig `json:"circuit_breaker"`
+}
+
+// FailureMode 는 Redis 장애 시 rate limiter 의 동작
+type FailureMode string
+
+const (
+	// FailOpen 은 제한 없이 요청을 허용한다.
+	FailOpen FailureMode = "open"
+	// FailClosed 는 모든 요청을 503 으로 거부한다.
+	FailClosed FailureMode = "closed"
+	// FailLocal 은 인스턴스 로컬 token bucket 으로 대신 제한한다.
+	FailLocal FailureMode = "local"
+)
+
+// CircuitBreakerConfig 는 Redis 호출을 감싸는 circuit breaker 설정
+type CircuitBreakerConfig struct {
+	// FailureThreshold 번 연속 실패하면 circuit 이 열린다. (기본 5)
+	FailureThreshold int `json:"failure_threshold"`
+	// OpenSeconds 동안 Redis 를 호출하지 않고 바로 FailureMode 로 처리한다. (기본 10)
+	OpenSeconds int `json:"open_seconds"`
+}
+
+const (
+	defaultRedisTimeout            = 100 * time.Millisecond
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitOpenDuration     = 10 * time.Second
+)
+
+// DefaultRateLimitConfig 는 정책이 설정되지 않았을 때 사용한다.
+// 평균 120/분을 유지하되 앱 실행 직후 몰리는 요청은 burst 로 허용
+var DefaultRateLimitConfig = RateLimitConfig{
+	Policies: []PolicyConfig{
+		{
+			Name:      "default",
+			KeyBy:     []KeySource{KeyByIP},
+			Algorithm: TokenBucket,
+			Rate:      2,
+			Burst:     30,
+		},
+	},
+}
+
+// policy 는 검증이 끝난 PolicyConfig
+type policy struct {
+	name        string
+	routePrefix string
+	methods     map[string]bool
+	keyBy       []KeySource
+	rule        Rule
+	// apiKeys 는 식별에 쓸 수 있는 API key 집합 (모든 정책이 공유)
+	apiKeys map[string]bool
+}
+
+func newPolicy(cfg PolicyConfig, apiKeys map[string]bool) (*policy, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("rate limit policy requires a name")
+	}
+
+	p := &policy{
+		name:        cfg.Name,
+		routePrefix: cfg.RoutePrefix,
+		keyBy:       cfg.KeyBy,
+		apiKeys:     apiKeys,
+		rule: Rule{
+			Algorithm: cfg.Algorithm,
+			Limit:     cfg.Limit,
+			Window:    time.Duration(cfg.WindowSeconds) * time.Second,
+			Rate:      cfg.Rate,
+			Burst:     cfg.Burst,
+		},
+	}
+
+	if p.rule.Algorithm == "" {
+		p.rule.Algorithm = SlidingWindowCounter
+	}
+	if err := p.rule.validate(); err != nil {
+		return nil, fmt.Errorf("rate limit policy %q: %w", cfg.Name, err)
+	}
+
+	if len(p.keyBy) == 0 {
+		p.keyBy = []KeySource{KeyByIP}
+	}
+	for _, source := range p.keyBy {
+		switch source {
+		case KeyByIP, KeyByAPIKey, KeyByUser:
+		default:
+			return nil, fmt.Errorf("rate limit policy %q: unknown key source %q", cfg.Name, source)
+		}
+	}
+
+	if len(cfg.Methods) > 0 {
+		p.methods = make(map[string]bool, len(cfg.Methods))
+		for _, method := range cfg.Methods {
+			p.methods[strings.ToUpper(method)] = true
+		}
+	}
+
+	return p, nil
+}
+
+func (p *policy) matches(c *gin.Context) bool {
+	if p.methods != nil && !p.methods[c.Request.Method] {
+		return false
+	}
+	return strings.HasPrefix(c.FullPath(), p.routePrefix)
+}
+
+// identity 는 keyBy 순서대로 요청자를 식별한다. IP 는 항상 존재하므로 마지막 fallback 으로 쓰인다.
+// 알려지지 않은 API key 는 건너뛴다.
+func (p *policy) identity(c *gin.Context) (KeySource, string) {
+	for _, source := range p.keyBy {
+		value := identityValue(c, source)
+		if source == KeyByAPIKey && !p.apiKeys[value] {
+			continue
+		}
+		if value != "" {
+			return source, value
+		}
+	}
+	return KeyByIP, c.ClientIP()
+}
+
+func identityValue(c *gin.Context, source KeySource) string {
+	switch source {
+	case KeyByIP:
+		return c.ClientIP()
+	case KeyByAPIKey:
+		return c.GetHeader(APIKeyHeader)
+	case KeyByUser:
+		if userID, ok := c.Get(UserIDKey); ok {
+			return fmt.Sprint(userID)
+		}
+	}
+	return ""
+}
+
+// allowlist 는 "source:value" 항목의 집합
+type allowlist map[string]bool
+
+func newAllowlist(entries []string) (allowlist, error) {
+	list := make(allowlist, len(entries))
+	for _, entry := range entries {
+		source, value, ok := strings.Cut(entry, ":")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid rate limit allowlist entry %q", entry)
+		}
+		switch KeySource(source) {
+		case KeyByIP, KeyByAPIKey, KeyByUser:
+		default:
+			return nil, fmt.Errorf("invalid rate limit allowlist entry %q: unknown key source", entry)
+		}
+		list[entry] = true
+	}
+	return list, nil
+}
+
+// apiKeys 는 configured 와 allowlist 의 api_key 항목을 합친 API key 집합을 만든다.
+func (l allowlist) apiKeys(configured []string) map[string]bool {
+	keys := make(map[string]bool, len(configured))
+	for _, key := range configured {
+		if key != "" {
+			keys[key] = true
+		}
+	}
+	for entry := range l {
+		if key, ok := strings.CutPrefix(entry, string(KeyByAPIKey)+":"); ok {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+func (l allowlist) contains(c *gin.Context) bool {
+	if len(l) == 0 {
+		return false
+	}
+	for _, source := range []KeySource{KeyByIP, KeyByAPIKey, KeyByUser} {
+		if value := identityValue(c, source); value != "" && l[string(source)+":"+value] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serve: route 를 등록한 라우터로 요청을 보내고, handler 안에서 fn 을 실행하는 테스트 픽스쳐
+func serve(t *testing.T, method, route, path string, setup func(*http.Request), fn func(c *gin.Context)) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Handle(method, route, fn)
+
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	if setup != nil {
+		setup(req)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestRateLimitPolicy(t *testing.T) {
+	limiter, err := NewRateLimiter(nil, RateLimitConfig{
+		Policies: []PolicyConfig{
+			{
+				Name:        "writes",
+				RoutePrefix: "/api/v1/todos",
+				Methods:     []string{"post", "delete"},
+				KeyBy:       []KeySource{KeyByAPIKey, KeyByIP},
+				Algorithm:   TokenBucket,
+				Rate:        1,
+				Burst:       5,
+			},
+			{
+				Name:          "reads",
+				Algorithm:     FixedWindow,
+				Limit:         100,
+				WindowSeconds: 60,
+			},
+		},
+		Allowlist: []string{"api_key:internal"},
+		APIKeys:   []string{"client-key"},
+	})
+	require.NoError(t, err)
+
+	t.Run("First Matching Policy Wins", func(t *testing.T) {
+		serve(t, http.MethodDelete, "/api/v1/todos/:id", "/api/v1/todos/1", nil, func(c *gin.Context) {
+			assert.Equal(t, "writes", limiter.match(c).name)
+		})
+		serve(t, http.MethodGet, "/api/v1/todos/:id", "/api/v1/todos/1", nil, func(c *gin.Context) {
+			assert.Equal(t, "reads", limiter.match(c).name)
+		})
+	})
+
+	t.Run("Identity Falls Back Through Key Sources", func(t *testing.T) {
+		serve(t, http.MethodPost, "/api/v1/todos", "/api/v1/todos", func(req *http.Request) {
+			req.Header.Set(APIKeyHeader, "client-key")
+		}, func(c *gin.Context) {
+			source, identity := limiter.match(c).identity(c)
+			assert.Equal(t, KeyByAPIKey, source)
+			assert.Equal(t, "client-key", identity)
+		})
+		serve(t, http.MethodPost, "/api/v1/todos", "/api/v1/todos", func(req *http.Request) {
+			req.Header.Set(APIKeyHeader, "internal")
+		}, func(c *gin.Context) {
+			source, identity := limiter.match(c).identity(c)
+			assert.Equal(t, KeyByAPIKey, source, "allowlisted api keys are known")
+			assert.Equal(t, "internal", identity)
+		})
+		serve(t, http.MethodPost, "/api/v1/todos", "/api/v1/todos", nil, func(c *gin.Context) {
+			source, identity := limiter.match(c).identity(c)
+			assert.Equal(t, KeyByIP, source)
+			assert.Equal(t, "10.0.0.1", identity)
+		})
+	})
+
+	t.Run("Unknown API Key Falls Back To IP", func(t *testing.T) {
+		serve(t, http.MethodPost, "/api/v1/todos", "/api/v1/todos", func(req *http.Request) {
+			req.Header.Set(APIKeyHeader, "made-up-key")
+		}, func(c *gin.Context) {
+			source, identity := limiter.match(c).identity(c)
+			assert.Equal(t, KeyByIP, source)
+			assert.Equal(t, "10.0.0.1", identity)
+		})
+	})
+
+	t.Run("Allowlisted Identity Is Exempt", func(t *testing.T) {
+		serve(t, http.MethodPost, "/api/v1/todos", "/api/v1/todos", func(req *http.Request) {
+			req.Header.Set(APIKeyHeader, "internal")
+		}, func(c *gin.Context) {
+			assert.True(t, limiter.allowlist.contains(c))
+		})
+	})
+
+	t.Run("Invalid Config Is Rejected", func(t *testing.T) {
+		invalidConfigs := map[string]RateLimitConfig{
+			"missing name":       {Policies: []PolicyConfig{{Algorithm: FixedWindow, Limit: 1, WindowSeconds: 1}}},
+			"missing window":     {Policies: []PolicyConfig{{Name: "p", Algorithm: FixedWindow, Limit: 1}}},
+			"unknown key source": {Policies: []PolicyConfig{{Name: "p", KeyBy: []KeySource{"cookie"}, Algorithm: TokenBucket, Rate: 1, Burst: 1}}},
+			"duplicate name": {Policies: []PolicyConfig{
+				{Name: "p", Algorithm: TokenBucket, Rate: 1, Burst: 1},
+				{Name: "p", Algorithm: TokenBucket, Rate: 1, Burst: 1},
+			}},
+			"bad allowlist": {Allowlist: []string{"127.0.0.1"}},
+		}
+		for name, config := range invalidConfigs {
+			t.Run(name, func(t *testing.T) {
+				_, err := NewRateLimiter(nil, config)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
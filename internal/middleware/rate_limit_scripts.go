@@ -0,0 +1,136 @@
+package middleware
+
+import "github.com/redis/go-redis/v9"
+
+// 모든 스크립트는 Redis 서버 시간(TIME)을 기준으로 동작하므로 앱 인스턴스 간 시계 차이의 영향을 받지 않는다.
+// 반환값: {allowed(0|1), remaining, reset_after_ms, retry_after_ms}
+
+// fixedWindowScript: window 단위 카운터. INCR 과 PEXPIRE 를 한 번에 실행해 TTL 없는 키가 남지 않는다.
+//
+// KEYS[1] 카운터 키
+// ARGV[1] limit, ARGV[2] window(ms)
+var fixedWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local count = redis.call('INCR', KEYS[1])
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+	redis.call('PEXPIRE', KEYS[1], window)
+	ttl = window
+end
+
+if count > limit then
+	return {0, 0, ttl, ttl}
+end
+return {1, limit - count, ttl, 0}
+`)
+
+// slidingWindowLogScript: 요청 시각을 sorted set 에 기록하고 window 안의 요청 수를 센다.
+// 정확하지만 limit 만큼의 항목을 저장한다.
+//
+// KEYS[1] sorted set 키
+// ARGV[1] limit, ARGV[2] window(ms), ARGV[3] 요청 고유 ID
+var slidingWindowLogScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	redis.call('PEXPIRE', KEYS[1], window)
+	count = count + 1
+	allowed = 1
+end
+
+-- 가장 오래된 요청이 window 밖으로 나가는 시점에 한 자리가 비워진다.
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+if allowed == 0 then
+	return {0, 0, reset, reset}
+end
+return {1, limit - count, reset, 0}
+`)
+
+// slidingWindowCounterScript: 현재/이전 window 카운터를 경과 비율로 가중 합산해 근사한다.
+// 키 두 개만 사용하므로 메모리가 일정하다.
+//
+// KEYS[1] 카운터 키 prefix (window 번호가 뒤에 붙는다. 같은 slot 에 두기 위해 hash tag 를 쓴다)
+// ARGV[1] limit, ARGV[2] window(ms)
+var slidingWindowCounterScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+
+local current = math.floor(now / window)
+local elapsed = now - current * window
+local current_key = KEYS[1] .. ':' .. current
+local previous_key = KEYS[1] .. ':' .. (current - 1)
+
+local current_count = tonumber(redis.call('GET', current_key) or '0')
+local previous_count = tonumber(redis.call('GET', previous_key) or '0')
+local weight = (window - elapsed) / window
+local estimated = previous_count * weight + current_count
+
+if estimated + 1 > limit then
+	-- 이전 window 의 가중치가 충분히 줄어드는 시점, 불가능하면 다음 window 시작까지 대기
+	local retry = window - elapsed
+	if previous_count > 0 and current_count + 1 <= limit then
+		local target = window - (limit - current_count - 1) * window / previous_count
+		retry = math.max(1, math.ceil(target - elapsed))
+	end
+	return {0, 0, retry, retry}
+end
+
+redis.call('INCR', current_key)
+redis.call('PEXPIRE', current_key, window * 2)
+
+return {1, math.floor(limit - estimated - 1), window - elapsed, 0}
+`)
+
+// tokenBucketScript: 경과 시간만큼 토큰을 채우고 요청마다 토큰 하나를 소비한다.
+// burst 만큼 한 번에 몰리는 요청을 허용하면서 평균 rate 를 유지한다.
+//
+// KEYS[1] bucket 해시 키 (tokens, ts)
+// ARGV[1] rate(토큰/ms), ARGV[2] burst
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+-- 가득 찬 bucket 은 저장할 필요가 없으므로 가득 차는 시점에 만료시킨다.
+local full_after = math.ceil((burst - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.max(1, full_after))
+
+return {allowed, math.floor(tokens), full_after, retry}
+`)
@@ -1,102 +1,294 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
+	"integration-test-example/internal/problem"
+	"math"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 )
 
+// Algorithm 은 rate limit 계산 방식
+type Algorithm string
+
+const (
+	// FixedWindow 는 window 단위로 카운터를 초기화한다. window 경계에서 최대 2배까지 몰릴 수 있다.
+	FixedWindow Algorithm = "fixed_window"
+	// SlidingWindowLog 는 요청 시각을 모두 기록해 정확히 센다.
+	SlidingWindowLog Algorithm = "sliding_window_log"
+	// SlidingWindowCounter 는 이전/현재 window 카운터의 가중 합으로 근사한다.
+	SlidingWindowCounter Algorithm = "sliding_window_counter"
+	// TokenBucket 은 초당 Rate 개씩 채워지는 최대 Burst 개의 토큰으로 순간적인 몰림을 허용한다.
+	TokenBucket Algorithm = "token_bucket"
+)
+
+// rate limit 으로 거부된 요청의 에러 코드
+const (
+	CodeRateLimitExceeded      = "rate_limit_exceeded"
+	CodeRateLimiterUnavailable = "rate_limiter_unavailable"
+)
+
+// Rule 은 하나의 limiter 설정
+type Rule struct {
+	Algorithm Algorithm
+	// Limit 은 Window 동안 허용하는 요청 수 (window 계열 알고리즘)
+	Limit  int
+	Window time.Duration
+	// Rate 는 초당 채워지는 토큰 수, Burst 는 bucket 크기 (TokenBucket)
+	Rate  float64
+	Burst int
+}
+
+func (r Rule) validate() error {
+	switch r.Algorithm {
+	case FixedWindow, SlidingWindowLog, SlidingWindowCounter:
+		if r.Limit <= 0 || r.Window < time.Millisecond {
+			return fmt.Errorf("%s requires a positive limit and window", r.Algorithm)
+		}
+	case TokenBucket:
+		if r.Rate <= 0 || r.Burst <= 0 {
+			return fmt.Errorf("%s requires a positive rate and burst", r.Algorithm)
+		}
+	default:
+		return fmt.Errorf("unknown rate limit algorithm: %q", r.Algorithm)
+	}
+	return nil
+}
+
+// quota 는 RateLimit-Policy 에 노출할 허용량과 그 기준 시간
+func (r Rule) quota() (int, time.Duration) {
+	if r.Algorithm == TokenBucket {
+		// bucket 이 비었다가 가득 차기까지 걸리는 시간
+		return r.Burst, time.Duration(float64(r.Burst) / r.Rate * float64(time.Second))
+	}
+	return r.Limit, r.Window
+}
+
+// RateLimiter 는 요청마다 처음 일치하는 정책 하나로 요청자별 rate limit 을 적용한다.
 type RateLimiter struct {
 	redisClient *redis.Client
-	limit       int
-	window      time.Duration
+	policies    []*policy
+	allowlist   allowlist
+
+	failureMode  FailureMode
+	redisTimeout time.Duration
+	breaker      *circuitBreaker
+	local        *localLimiter
 }
 
-func NewRateLimiter(redisClient *redis.Client, limit int, window time.Duration) *RateLimiter {
-	return &RateLimiter{
-		redisClient: redisClient,
-		limit:       limit,
-		window:      window,
+var (
+	errCircuitOpen = errors.New("rate limiter circuit is open")
+)
+
+// rateLimitResult 는 Lua 스크립트 한 번의 판정 결과
+type rateLimitResult struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	resetAfter time.Duration
+	retryAfter time.Duration
+}
+
+// requestSeq 는 sliding window log 에서 같은 ms 에 들어온 요청을 구분하기 위한 일련번호
+var requestSeq atomic.Uint64
+
+func NewRateLimiter(redisClient *redis.Client, config RateLimitConfig) (*RateLimiter, error) {
+	if len(config.Policies) == 0 {
+		config.Policies = DefaultRateLimitConfig.Policies
+	}
+
+	list, err := newAllowlist(config.Allowlist)
+	if err != nil {
+		return nil, err
+	}
+	apiKeys := list.apiKeys(config.APIKeys)
+
+	policies := make([]*policy, 0, len(config.Policies))
+	names := make(map[string]bool, len(config.Policies))
+	for _, policyConfig := range config.Policies {
+		p, err := newPolicy(policyConfig, apiKeys)
+		if err != nil {
+			return nil, err
+		}
+		if names[p.name] {
+			return nil, fmt.Errorf("duplicate rate limit policy %q", p.name)
+		}
+		names[p.name] = true
+		policies = append(policies, p)
+	}
+
+	switch config.FailureMode {
+	case "":
+		config.FailureMode = FailOpen
+	case FailOpen, FailClosed, FailLocal:
+	default:
+		return nil, fmt.Errorf("unknown rate limit failure mode %q", config.FailureMode)
+	}
+
+	redisTimeout := time.Duration(config.RedisTimeoutMs) * time.Millisecond
+	if redisTimeout <= 0 {
+		redisTimeout = defaultRedisTimeout
+	}
+
+	threshold := config.CircuitBreaker.FailureThreshold
+	if threshold <= 0 {
+		threshold = defaultCircuitFailureThreshold
+	}
+	cooldown := time.Duration(config.CircuitBreaker.OpenSeconds) * time.Second
+	if cooldown <= 0 {
+		cooldown = defaultCircuitOpenDuration
 	}
+
+	return &RateLimiter{
+		redisClient:  redisClient,
+		policies:     policies,
+		allowlist:    list,
+		failureMode:  config.FailureMode,
+		redisTimeout: redisTimeout,
+		breaker:      newCircuitBreaker(threshold, cooldown),
+		local:        newLocalLimiter(),
+	}, nil
 }
 
 func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		key := fmt.Sprintf("rate_limit:%s", clientIP)
-
-		ctx := c.Request.Context()
+		if rl.allowlist.contains(c) {
+			c.Next()
+			return
+		}
 
-		val, err := rl.redisClient.Get(ctx, key).Result()
-		if err != nil && err != redis.Nil {
-			// Redis 에러 시 요청 허용 (graceful degradation)
+		p := rl.match(c)
+		if p == nil {
 			c.Next()
 			return
 		}
 
-		// 첫 요청인 경우
-		if err == redis.Nil {
-			// 카운터 초기화 (1로 설정하고 TTL 적용)
-			pipe := rl.redisClient.Pipeline()
-			pipe.Set(ctx, key, 1, rl.window)
-			_, err := pipe.Exec(ctx)
-			if err != nil {
+		source, identity := p.identity(c)
+		// 정책마다 독립된 카운터를 사용한다.
+		subject := fmt.Sprintf("%s:%s:%s", p.name, source, identity)
+
+		result, err := rl.check(c.Request.Context(), p.rule, subject)
+		if err != nil {
+			switch rl.failureMode {
+			case FailClosed:
+				problem.Write(c, problem.New(http.StatusServiceUnavailable, CodeRateLimiterUnavailable,
+					"Requests are temporarily rejected because the rate limiter is unavailable."))
+				return
+			case FailLocal:
+				result = rl.local.allow(subject, p.rule)
+			default:
+				// Redis 에러 시 요청 허용 (graceful degradation)
 				c.Next()
 				return
 			}
+		}
 
-			c.Header("X-RateLimit-Limit", strconv.Itoa(rl.limit))
-			c.Header("X-RateLimit-Remaining", strconv.Itoa(rl.limit-1))
-			c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(rl.window).Unix(), 10))
+		setRateLimitHeaders(c, p, result)
 
-			c.Next()
+		if !result.allowed {
+			retryAfter := int(math.Ceil(result.retryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+			_, window := p.rule.quota()
+			problem.Write(c, problem.New(http.StatusTooManyRequests, CodeRateLimitExceeded,
+				fmt.Sprintf("Too many requests. Limit: %d per %v. Retry after %d seconds.", result.limit, window, retryAfter)))
 			return
 		}
 
-		currentCount, err := strconv.Atoi(val)
-		if err != nil {
-			c.Next()
-			return
+		c.Next()
+	}
+}
+
+// match 는 요청에 적용할 첫 번째 정책을 찾는다. 없으면 nil
+func (rl *RateLimiter) match(c *gin.Context) *policy {
+	for _, p := range rl.policies {
+		if p.matches(c) {
+			return p
 		}
+	}
+	return nil
+}
 
-		if currentCount >= rl.limit {
-			ttl, _ := rl.redisClient.TTL(ctx, key).Result()
-			resetTime := time.Now().Add(ttl).Unix()
+// setRateLimitHeaders 는 X-RateLimit-* 헤더와 IETF RateLimit-Policy / RateLimit 헤더를 설정한다.
+// (draft-ietf-httpapi-ratelimit-headers)
+func setRateLimitHeaders(c *gin.Context, p *policy, result *rateLimitResult) {
+	resetAt := time.Now().Add(result.resetAfter)
+	resetSeconds := int64(math.Ceil(result.resetAfter.Seconds()))
+	quota, window := p.rule.quota()
 
-			c.Header("X-RateLimit-Limit", strconv.Itoa(rl.limit))
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", strconv.FormatInt(resetTime, 10))
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(int64(math.Ceil(float64(resetAt.UnixMilli())/1000)), 10))
 
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":       "Rate limit exceeded",
-				"message":     fmt.Sprintf("Too many requests. Limit: %d per %v", rl.limit, rl.window),
-				"retry_after": int(ttl.Seconds()),
-			})
-			c.Abort()
-			return
-		}
+	c.Header("RateLimit-Policy", fmt.Sprintf("%q;q=%d;w=%d", p.name, quota, int64(math.Ceil(window.Seconds()))))
+	c.Header("RateLimit", fmt.Sprintf("%q;r=%d;t=%d", p.name, result.remaining, resetSeconds))
+}
 
-		newCount, err := rl.redisClient.Incr(ctx, key).Result()
-		if err != nil {
-			c.Next()
-			return
-		}
+// check 는 circuit breaker 와 timeout 을 거쳐 Redis 로 판정한다.
+// circuit 이 열려 있으면 Redis 를 호출하지 않고 바로 errCircuitOpen 을 반환한다.
+func (rl *RateLimiter) check(ctx context.Context, rule Rule, subject string) (*rateLimitResult, error) {
+	if !rl.breaker.allow() {
+		return nil, errCircuitOpen
+	}
+
+	redisCtx, cancel := context.WithTimeout(ctx, rl.redisTimeout)
+	defer cancel()
 
-		remaining := rl.limit - int(newCount)
-		if remaining < 0 {
-			remaining = 0
+	result, err := rl.allow(redisCtx, rule, subject)
+	if err != nil {
+		// 클라이언트가 먼저 끊은 경우는 Redis 장애가 아니다.
+		if ctx.Err() == nil {
+			rl.breaker.failure()
 		}
+		return nil, err
+	}
 
-		c.Header("X-RateLimit-Limit", strconv.Itoa(rl.limit))
-		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	rl.breaker.success()
+	return result, nil
+}
 
-		if rl.redisClient.TTL(ctx, key).Val() == -1 {
-			rl.redisClient.Expire(ctx, key, rl.window)
-		}
+// allow 는 rule 에 따라 subject 의 요청 하나를 원자적으로 판정하고 기록한다.
+func (rl *RateLimiter) allow(ctx context.Context, rule Rule, subject string) (*rateLimitResult, error) {
+	window := rule.Window.Milliseconds()
 
-		c.Next()
+	var (
+		values []int64
+		err    error
+	)
+	switch rule.Algorithm {
+	case FixedWindow:
+		key := fmt.Sprintf("rate_limit:fw:%s", subject)
+		values, err = fixedWindowScript.Run(ctx, rl.redisClient, []string{key}, rule.Limit, window).Int64Slice()
+	case SlidingWindowLog:
+		key := fmt.Sprintf("rate_limit:swl:%s", subject)
+		member := fmt.Sprintf("%d-%d", time.Now().UnixNano(), requestSeq.Add(1))
+		values, err = slidingWindowLogScript.Run(ctx, rl.redisClient, []string{key}, rule.Limit, window, member).Int64Slice()
+	case SlidingWindowCounter:
+		key := fmt.Sprintf("rate_limit:swc:{%s}", subject)
+		values, err = slidingWindowCounterScript.Run(ctx, rl.redisClient, []string{key}, rule.Limit, window).Int64Slice()
+	case TokenBucket:
+		key := fmt.Sprintf("rate_limit:tb:%s", subject)
+		ratePerMs := rule.Rate / 1000
+		values, err = tokenBucketScript.Run(ctx, rl.redisClient, []string{key}, ratePerMs, rule.Burst).Int64Slice()
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm: %q", rule.Algorithm)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	limit, _ := rule.quota()
+	return &rateLimitResult{
+		allowed:    values[0] == 1,
+		limit:      limit,
+		remaining:  int(values[1]),
+		resetAfter: time.Duration(values[2]) * time.Millisecond,
+		retryAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
 }
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/problem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newUnavailableRedis: 연결이 즉시 거부되는 Redis 클라이언트 테스트 픽스쳐
+func newUnavailableRedis() *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:       "127.0.0.1:1",
+		MaxRetries: -1,
+	})
+}
+
+// assertProblem 은 middleware 가 handler 와 같은 problem+json 형식으로 거부했는지 확인한다.
+func assertProblem(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+
+	assert.Equal(t, status, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	var body problem.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, status, body.Status)
+	assert.Equal(t, code, body.Code)
+	assert.NotEmpty(t, body.Detail)
+}
+
+func newLimitedRouter(t *testing.T, config RateLimitConfig) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	limiter, err := NewRateLimiter(newUnavailableRedis(), config)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(limiter.RateLimit())
+	r.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func get(r http.Handler) *httptest.ResponseRecorder {
+	return getWithAPIKey(r, "")
+}
+
+func getWithAPIKey(r http.Handler, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiterWhenRedisIsUnavailable(t *testing.T) {
+	policies := []PolicyConfig{{Name: "default", Algorithm: TokenBucket, Rate: 1, Burst: 2}}
+
+	t.Run("Fail Open Allows Requests", func(t *testing.T) {
+		r := newLimitedRouter(t, RateLimitConfig{Policies: policies, FailureMode: FailOpen})
+
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusOK, get(r).Code)
+		}
+	})
+
+	t.Run("Fail Closed Rejects Requests", func(t *testing.T) {
+		r := newLimitedRouter(t, RateLimitConfig{Policies: policies, FailureMode: FailClosed})
+
+		assertProblem(t, get(r), http.StatusServiceUnavailable, CodeRateLimiterUnavailable)
+	})
+
+	t.Run("Fail Local Falls Back To In-Process Token Bucket", func(t *testing.T) {
+		r := newLimitedRouter(t, RateLimitConfig{Policies: policies, FailureMode: FailLocal})
+
+		assert.Equal(t, http.StatusOK, get(r).Code)
+		assert.Equal(t, http.StatusOK, get(r).Code)
+		w := get(r)
+		assertProblem(t, w, http.StatusTooManyRequests, CodeRateLimitExceeded)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("Rotating Unknown API Keys Does Not Reset Quota", func(t *testing.T) {
+		r := newLimitedRouter(t, RateLimitConfig{
+			Policies: []PolicyConfig{{
+				Name:      "default",
+				KeyBy:     []KeySource{KeyByAPIKey, KeyByIP},
+				Algorithm: TokenBucket,
+				Rate:      1,
+				Burst:     2,
+			}},
+			APIKeys:     []string{"client-key"},
+			FailureMode: FailLocal,
+		})
+
+		assert.Equal(t, http.StatusOK, getWithAPIKey(r, "random-1").Code)
+		assert.Equal(t, http.StatusOK, getWithAPIKey(r, "random-2").Code)
+		assert.Equal(t, http.StatusTooManyRequests, getWithAPIKey(r, "random-3").Code)
+		// 설정된 API key 는 IP 와 별개의 quota 를 갖는다.
+		assert.Equal(t, http.StatusOK, getWithAPIKey(r, "client-key").Code)
+	})
+}
+
+func TestRateLimiterCancelledTrialDoesNotKeepCircuitHalfOpen(t *testing.T) {
+	// Arrange
+	limiter, err := NewRateLimiter(newUnavailableRedis(), RateLimitConfig{
+		FailureMode:    FailLocal,
+		CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 1, OpenSeconds: 10},
+	})
+	require.NoError(t, err)
+	now := time.Now()
+	limiter.breaker.now = func() time.Time { return now }
+	rule := Rule{Algorithm: TokenBucket, Rate: 1, Burst: 1}
+	_, err = limiter.check(context.Background(), rule, "subject")
+	require.Error(t, err)
+	_, err = limiter.check(context.Background(), rule, "subject")
+	require.ErrorIs(t, err, errCircuitOpen)
+
+	// Act: cooldown 이후 시험 호출 도중 클라이언트가 끊는다.
+	now = now.Add(10 * time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, trialErr := limiter.check(ctx, rule, "subject")
+
+	// Assert: 결과가 없는 시험 호출은 cooldown 동안만 다른 시험 호출을 막는다.
+	require.Error(t, trialErr)
+	assert.NotErrorIs(t, trialErr, errCircuitOpen)
+	_, err = limiter.check(context.Background(), rule, "subject")
+	assert.ErrorIs(t, err, errCircuitOpen)
+
+	now = now.Add(10 * time.Second)
+	_, err = limiter.check(context.Background(), rule, "subject")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, errCircuitOpen, "a new trial reaches Redis")
+	assert.False(t, limiter.breaker.allow(), "the failed trial opens the circuit again")
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	breaker := newCircuitBreaker(2, 10*time.Second)
+	breaker.now = func() time.Time { return now }
+
+	// Act & Assert: 연속 실패가 threshold 에 도달하면 열린다.
+	assert.True(t, breaker.allow())
+	breaker.failure()
+	assert.True(t, breaker.allow())
+	breaker.failure()
+	assert.False(t, breaker.allow())
+
+	// cooldown 이후 시험 호출은 하나만 허용된다.
+	now = now.Add(10 * time.Second)
+	assert.True(t, breaker.allow())
+	assert.False(t, breaker.allow())
+
+	// 시험 호출이 실패하면 다시 열린다.
+	breaker.failure()
+	assert.False(t, breaker.allow())
+
+	// 시험 호출이 성공하면 닫힌다.
+	now = now.Add(10 * time.Second)
+	assert.True(t, breaker.allow())
+	breaker.success()
+	assert.True(t, breaker.allow())
+	assert.True(t, breaker.allow())
+}
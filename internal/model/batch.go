@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// Batch 실행 방식
+const (
+	// BatchAtomic 은 하나라도 실패하면 전체를 롤백한다. (기본값)
+	BatchAtomic = "atomic"
+	// BatchIndependent 는 실패한 operation 만 롤백하고 항목별 결과를 반환한다.
+	BatchIndependent = "independent"
+)
+
+// Batch operation 종류
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchTodoRequest represents the request body for POST /todos:batch
+type BatchTodoRequest struct {
+	Mode       string               `json:"mode" binding:"omitempty,oneof=atomic independent"`
+	Operations []BatchTodoOperation `json:"operations" binding:"required,min=1,dive"`
+}
+
+// BatchTodoOperation 은 batch 안의 변경 하나.
+// update 는 PUT 과 같이 변경 가능한 필드 전체를 교체한다.
+type BatchTodoOperation struct {
+	Op string `json:"op" binding:"required,oneof=create update delete"`
+	// ID 는 update / delete 대상
+	ID int64 `json:"id" binding:"required_unless=Op create,omitempty,min=1"`
+	// Version 이 0 이 아니면 If-Match 와 같이 저장된 version 이 같을 때만 변경한다.
+	Version      int64      `json:"version" binding:"omitempty,min=1"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Completed    bool       `json:"completed"`
+	Priority     Priority   `json:"priority" binding:"omitempty,oneof=low normal high urgent"`
+	ListID       *int64     `json:"list_id" binding:"omitempty,min=1"`
+	AutoComplete bool       `json:"auto_complete"`
+	DueAt        *time.Time `json:"due_at" binding:"required_with=RRule"`
+	RemindAt     *time.Time `json:"remind_at" binding:"omitempty,notafter=due_at"`
+	RRule        string     `json:"rrule" binding:"omitempty,rrule"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Todo 도메인 이벤트 종류
+const (
+	EventTodoCreated   = "todo_created"
+	EventTodoUpdated   = "todo_updated"
+	EventTodoCompleted = "todo_completed"
+	EventTodoDeleted   = "todo_deleted"
+	EventTodoRestored  = "todo_restored"
+	// EventTodoReminder 는 todo 의 알림 시각(remind_at)이 되었을 때 ReminderScheduler 가 기록한다.
+	EventTodoReminder = "todo_reminder"
+)
+
+// OutboxEvent represents a todo domain event stored in the outbox table.
+// Payload 는 이벤트 발생 시점의 Todo 스냅샷(JSON)이다.
+type OutboxEvent struct {
+	ID           int64      `json:"id" db:"id"`
+	EventType    string     `json:"event_type" db:"event_type"`
+	TodoID       int64      `json:"todo_id" db:"todo_id"`
+	Payload      []byte     `json:"payload" db:"payload"`
+	Attempts     int        `json:"attempts" db:"attempts"`
+	LastError    string     `json:"last_error" db:"last_error"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	AvailableAt  time.Time  `json:"available_at" db:"available_at"`
+	DispatchedAt *time.Time `json:"dispatched_at" db:"dispatched_at"`
+}
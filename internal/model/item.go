@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// TodoItem 은 todo 를 나눈 하위 항목(체크리스트). todo 가 휴지통으로 이동되면 함께 숨겨지고, 영구 삭제되면 함께 삭제된다.
+// Position 은 같은 todo 의 item 사이에서의 순서를 나타내는 fractional index 키이며, move 로만 바꿀 수 있다.
+type TodoItem struct {
+	ID        int64     `json:"id" db:"id"`
+	TodoID    int64     `json:"todo_id" db:"todo_id"`
+	Title     string    `json:"title" db:"title" validate:"required,notblank,max=255,singleline"`
+	Completed bool      `json:"completed" db:"completed"`
+	Position  string    `json:"position" db:"position"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TodoItemRequest represents the request body for creating or replacing a todo item
+type TodoItemRequest struct {
+	Title     string `json:"title" binding:"required,notblank,max=255,singleline"`
+	Completed bool   `json:"completed"`
+}
+
+// TodoProgress 는 todo 의 item 완료 현황. Percent 는 완료 비율(%)의 내림 값이다.
+type TodoProgress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+	Percent   int `json:"percent"`
+}
+
+// NewTodoProgress 는 item 수로 완료 현황을 계산한다. item 이 없으면 nil 이다.
+func NewTodoProgress(completed, total int) *TodoProgress {
+	if total == 0 {
+		return nil
+	}
+	return &TodoProgress{
+		Completed: completed,
+		Total:     total,
+		Percent:   completed * 100 / total,
+	}
+}
+
+// ProgressOf 는 items 의 완료 현황을 계산한다.
+func ProgressOf(items []*TodoItem) *TodoProgress {
+	completed := 0
+	for _, item := range items {
+		if item.Completed {
+			completed++
+		}
+	}
+	return NewTodoProgress(completed, len(items))
+}
+
+// Done 은 item 이 하나 이상 있고 모두 완료되었는지 확인한다.
+func (p *TodoProgress) Done() bool {
+	return p != nil && p.Completed == p.Total
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// MaxListNameLength 는 list 이름의 최대 문자(rune) 수
+const MaxListNameLength = 100
+
+// List 는 todo 를 묶는 프로젝트/목록. todo 는 ListID 로 최대 하나의 list 에 속한다.
+// UserID 는 list 의 소유자이며 응답에는 포함하지 않는다.
+type List struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    *int64    `json:"-" db:"user_id"`
+	Name      string    `json:"name" db:"name" validate:"required,notblank,max=100,singleline"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ListRequest represents the request body for creating or renaming a list
+type ListRequest struct {
+	Name string `json:"name" binding:"required,notblank,max=100,singleline"`
+}
+
+// list 삭제 시 속한 todo 의 처리 방식
+const (
+	// ListCascadeArchive 는 todo 를 휴지통으로 옮긴다. 휴지통에서 복원하면 list 없이 복원된다.
+	ListCascadeArchive = "archive"
+	// ListCascadeDelete 는 휴지통의 todo 를 포함해 todo 를 영구 삭제한다.
+	ListCascadeDelete = "delete"
+)
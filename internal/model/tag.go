@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// MaxTagNameLength 는 태그 이름의 최대 문자(rune) 수
+const MaxTagNameLength = 50
+
+// Tag 는 todo 를 분류하는 라벨. 이름은 사용자마다 대소문자 구분 없이 유일하다.
+// UserID 는 태그의 소유자이며 응답에는 포함하지 않는다.
+type Tag struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    *int64    `json:"-" db:"user_id"`
+	Name      string    `json:"name" db:"name" validate:"required,notblank,max=50,singleline"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TagRequest represents the request body for creating or renaming a tag
+type TagRequest struct {
+	Name string `json:"name" binding:"required,notblank,max=50,singleline"`
+}
+
+// 태그 필터 일치 방식
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// MaxTagFilters 는 목록 조회에서 한 번에 지정할 수 있는 태그 필터 수
+const MaxTagFilters = 10
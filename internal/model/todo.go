@@ -2,24 +2,191 @@ package model
 
 import "time"
 
+// Todo 필드 제약. 길이는 byte 가 아닌 문자(rune) 수 기준이다.
+// (title 은 VARCHAR(255), description 은 TEXT 컬럼 크기 안에 들어가도록 제한)
+const (
+	MaxTodoTitleLength       = 255
+	MaxTodoDescriptionLength = 10000
+)
+
+// validate 태그는 service 에서, binding 태그는 handler 의 요청 바인딩에서 검사한다. 두 규칙은 같게 유지한다.
+//
+//   - notblank: 앞뒤 공백을 제거해도 비어 있지 않아야 한다.
+//   - singleline: 줄바꿈, 탭을 포함한 제어 문자를 허용하지 않는다.
+//   - nocontrol: 줄바꿈과 탭을 제외한 제어 문자를 허용하지 않는다.
+//   - notafter: 시각이 지정한 필드의 시각보다 늦지 않아야 한다. (알림은 마감 전에)
+//
+// Version 은 변경될 때마다 1 씩 증가하며 ETag 로 노출된다.
+// DeletedAt 은 휴지통으로 이동된(soft delete) 시각이며, 활성 todo 는 nil 이다.
+// Match 는 검색(q) 결과로 조회된 경우에만 채워진다.
+//
+// DueAt 은 마감 시각, RemindAt 은 알림을 보낼 시각이다. RemindedAt 은 알림이 발송된 시각이며,
+// RemindAt 이 바뀌면 다시 비워져 새 시각에 알림이 발송된다.
+//
+// Position 은 수동 정렬(sort=position) 순서를 나타내는 fractional index 키이며, move 로만 바꿀 수 있다.
+// Tags 는 이름 순으로 정렬된 todo 의 태그이며, 태그 붙이기/떼기로만 바꿀 수 있다.
+//
+// UserID 는 todo 의 소유자이며 응답에는 포함하지 않는다. 저장소가 소유자로 범위가 정해지면 소유자의 todo 만 다룬다.
+//
+// ListID 는 todo 가 속한 list 이며, list 에 속하지 않으면 nil 이다.
+//
+// Progress 는 하위 항목(item)의 완료 현황이며 item 이 없으면 nil 이다.
+// AutoComplete 가 true 면 item 변경으로 모든 item 이 완료되었을 때 todo 도 완료된다.
+//
+// RRule 은 반복 일정(RFC 5545 RRULE)이며 DueAt 을 첫 발생 시각(DTSTART)으로 삼는다. 반복 todo 가 완료되면
+// 다음 발생 시각을 마감으로 하는 새 todo 가 만들어지고 반복은 새 todo 로 넘어간다.
 type Todo struct {
-	ID          int64     `json:"id" db:"id"`
-	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description" db:"description"`
-	Completed   bool      `json:"completed" db:"completed"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID           int64         `json:"id" db:"id"`
+	UserID       *int64        `json:"-" db:"user_id"`
+	Title        string        `json:"title" db:"title" validate:"required,notblank,max=255,singleline"`
+	Description  string        `json:"description" db:"description" validate:"max=10000,nocontrol"`
+	Completed    bool          `json:"completed" db:"completed"`
+	ListID       *int64        `json:"list_id" db:"list_id" validate:"omitempty,min=1"`
+	AutoComplete bool          `json:"auto_complete" db:"auto_complete"`
+	Priority     Priority      `json:"priority" db:"priority" validate:"required,oneof=low normal high urgent"`
+	Position     string        `json:"position" db:"position"`
+	Version      int64         `json:"version" db:"version"`
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at" db:"updated_at"`
+	DueAt        *time.Time    `json:"due_at" db:"due_at" validate:"required_with=RRule"`
+	RemindAt     *time.Time    `json:"remind_at" db:"remind_at" validate:"omitempty,notafter=due_at"`
+	RRule        string        `json:"rrule" db:"rrule" validate:"omitempty,rrule"`
+	RemindedAt   *time.Time    `json:"reminded_at,omitempty" db:"reminded_at"`
+	DeletedAt    *time.Time    `json:"deleted_at,omitempty" db:"deleted_at"`
+	Tags         []Tag         `json:"tags" db:"-"`
+	Progress     *TodoProgress `json:"progress" db:"-"`
+	Match        *TodoMatch    `json:"match,omitempty" db:"-"`
+}
+
+// Priority 는 todo 의 우선순위. 저장소에는 Rank 로 저장되어 낮음 → 긴급 순으로 정렬된다.
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// priorities 는 Rank 순서의 우선순위 목록
+var priorities = []Priority{PriorityLow, PriorityNormal, PriorityHigh, PriorityUrgent}
+
+// Rank 는 우선순위의 정렬 순서 (low=0 ~ urgent=3). 알 수 없는 값은 -1 이다.
+func (p Priority) Rank() int {
+	for rank, priority := range priorities {
+		if priority == p {
+			return rank
+		}
+	}
+	return -1
+}
+
+// PriorityFromRank 는 Rank 에 해당하는 우선순위를 반환한다.
+func PriorityFromRank(rank int) (Priority, bool) {
+	if rank < 0 || rank >= len(priorities) {
+		return "", false
+	}
+	return priorities[rank], true
+}
+
+// TodoMatch 는 검색어와 todo 가 일치한 정도와 위치를 나타낸다.
+// Highlights 는 필드 이름별로 일치한 부분을 <mark> 로 감싼 HTML 조각이며, 일치하지 않은 필드는 포함하지 않는다.
+type TodoMatch struct {
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights,omitempty"`
 }
 
 // CreateTodoRequest represents the request body for creating a todo
 type CreateTodoRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description"`
+	Title        string     `json:"title" binding:"required,notblank,max=255,singleline"`
+	Description  string     `json:"description" binding:"max=10000,nocontrol"`
+	Priority     Priority   `json:"priority" binding:"omitempty,oneof=low normal high urgent"`
+	ListID       *int64     `json:"list_id" binding:"omitempty,min=1"`
+	AutoComplete bool       `json:"auto_complete"`
+	DueAt        *time.Time `json:"due_at" binding:"required_with=RRule"`
+	RemindAt     *time.Time `json:"remind_at" binding:"omitempty,notafter=due_at"`
+	RRule        string     `json:"rrule" binding:"omitempty,rrule"`
+}
+
+// ReplaceTodoRequest represents the request body for replacing a todo.
+// 생략된 필드는 빈 값으로 교체된다. 일부 필드만 바꾸려면 PATCH 를 사용한다.
+type ReplaceTodoRequest struct {
+	Title        string     `json:"title" binding:"required,notblank,max=255,singleline"`
+	Description  string     `json:"description" binding:"max=10000,nocontrol"`
+	Completed    bool       `json:"completed"`
+	Priority     Priority   `json:"priority" binding:"omitempty,oneof=low normal high urgent"`
+	ListID       *int64     `json:"list_id" binding:"omitempty,min=1"`
+	AutoComplete bool       `json:"auto_complete"`
+	DueAt        *time.Time `json:"due_at" binding:"required_with=RRule"`
+	RemindAt     *time.Time `json:"remind_at" binding:"omitempty,notafter=due_at"`
+	RRule        string     `json:"rrule" binding:"omitempty,rrule"`
+}
+
+// MoveTodoRequest represents the request body for moving a todo.
+// after_id 또는 before_id 중 하나로 기준 todo 를 지정하면 그 바로 뒤(앞)로 옮긴다.
+type MoveTodoRequest struct {
+	AfterID  int64 `json:"after_id" binding:"omitempty,min=1"`
+	BeforeID int64 `json:"before_id" binding:"omitempty,min=1"`
+}
+
+// Todo 목록 정렬 기준
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortTitle     = "title"
+	// SortDeletedAt 은 휴지통 목록에서만 사용할 수 있다.
+	SortDeletedAt = "deleted_at"
+	// SortPriority 는 우선순위 Rank 기준, SortPosition 은 수동 정렬 순서 기준
+	SortPriority = "priority"
+	SortPosition = "position"
+	// SortRelevance 는 검색(q) 과 함께만 사용할 수 있으며, q 가 있을 때의 기본 정렬이다.
+	SortRelevance = "relevance"
+)
+
+// Todo 목록 정렬 방향
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+const (
+	DefaultTodoListLimit = 20
+	MaxTodoListLimit     = 100
+)
+
+// 반복 todo 의 발생 시각 미리보기 개수
+const (
+	DefaultOccurrenceLimit = 10
+	MaxOccurrenceLimit     = 100
+)
+
+// TodoListQuery represents the filter, sort and paging options for listing todos
+type TodoListQuery struct {
+	Limit     int
+	Cursor    string
+	Completed *bool
+	Sort      string
+	Order     string
+	// Overdue 가 true 면 마감이 지난 미완료 todo 만, false 면 그 외의 todo 만 조회한다.
+	Overdue *bool
+	// DueBefore / DueAfter 는 마감 시각 범위 (경계 미포함). 마감이 없는 todo 는 제외된다.
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	// Now 는 Overdue 판단 기준 시각. service 에서 채운다.
+	Now time.Time
+	// Q 는 title, description 전문 검색어. 공백으로 나뉜 검색어를 모두 포함하는 todo 만 조회한다.
+	Q string
+	// Trashed 가 true 면 활성 todo 대신 휴지통의 todo 를 조회한다.
+	Trashed bool
+	// ListID 가 있으면 그 list 에 속한 todo 만 조회한다.
+	ListID *int64
+	// Tags 는 태그 이름 필터. TagMatch 가 TagMatchAny 면 하나라도, TagMatchAll 이면 모두 붙은 todo 만 조회한다.
+	Tags     []string
+	TagMatch string
 }
 
-// UpdateTodoRequest represents the request body for updating a todo
-type UpdateTodoRequest struct {
-	Title       *string `json:"title,omitempty"`
-	Description *string `json:"description,omitempty"`
-	Completed   *bool   `json:"completed,omitempty"`
+// TodoPage represents a single page of todos
+type TodoPage struct {
+	Todos      []*Todo `json:"todos"`
+	NextCursor string  `json:"next_cursor"`
 }
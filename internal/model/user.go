@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// 사용자 필드 제약. bcrypt 는 72 byte 까지만 해시에 반영하므로 비밀번호 길이를 제한한다.
+const (
+	MaxEmailLength    = 254
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// User 는 todo 의 소유자. 이메일은 대소문자 구분 없이 유일하며 소문자로 저장된다.
+// PasswordHash 는 bcrypt 해시이며 응답에 포함하지 않는다.
+type User struct {
+	ID           int64     `json:"id" db:"id"`
+	Email        string    `json:"email" db:"email" validate:"required,max=254,email"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// RegisterRequest represents the request body for registering a user
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required,max=254,email"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// LoginRequest represents the request body for logging in
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
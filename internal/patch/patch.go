@@ -0,0 +1,80 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PATCH 요청에서 지원하는 media type
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch 는 patch 문서가 잘못되었거나 대상 문서에 적용할 수 없을 때 반환된다.
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrTestFailed 는 JSON Patch 의 test 연산이 일치하지 않을 때 반환된다.
+	ErrTestFailed = errors.New("patch test failed")
+)
+
+// Patch 는 JSON 문서에 적용할 수 있는 변경 사항
+type Patch interface {
+	Apply(doc []byte) ([]byte, error)
+}
+
+// Decode 는 contentType 에 맞는 patch 를 읽는다. 지원하지 않는 media type 이면 ok 가 false
+func Decode(contentType string, data []byte) (p Patch, ok bool, err error) {
+	switch contentType {
+	case MergePatchContentType:
+		p, err = DecodeMergePatch(data)
+	case JSONPatchContentType:
+		p, err = DecodeJSONPatch(data)
+	default:
+		return nil, false, nil
+	}
+	return p, true, err
+}
+
+// MergePatch 는 RFC 7396 JSON Merge Patch.
+// null 값은 대상 멤버를 제거하고, object 가 아닌 값은 그대로 대체한다.
+type MergePatch struct {
+	patch interface{}
+}
+
+func DecodeMergePatch(data []byte) (*MergePatch, error) {
+	var p interface{}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return &MergePatch{patch: p}, nil
+}
+
+func (p *MergePatch) Apply(doc []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(target, p.patch))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{}, len(patchObject))
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
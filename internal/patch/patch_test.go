@@ -0,0 +1,108 @@
+package patch
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	cases := map[string]struct {
+		doc, patch, expected string
+	}{
+		"replaces member":       {`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		"adds member":           {`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		"null removes member":   {`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		"merges nested objects": {`{"a":{"b":"c","d":"e"}}`, `{"a":{"d":null,"f":"g"}}`, `{"a":{"b":"c","f":"g"}}`},
+		"replaces arrays":       {`{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`},
+		"non object replaces":   {`{"a":"b"}`, `"c"`, `"c"`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			p, err := DecodeMergePatch([]byte(tc.patch))
+			require.NoError(t, err)
+
+			// Act
+			result, err := p.Apply([]byte(tc.doc))
+
+			// Assert
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	cases := map[string]struct {
+		doc, patch, expected string
+	}{
+		"add member":             {`{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		"add array element":      {`{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`},
+		"append array element":   {`{"a":[1]}`, `[{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+		"remove array element":   {`{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/0"}]`, `{"a":[2,3]}`},
+		"replace member":         {`{"a":1}`, `[{"op":"replace","path":"/a","value":"x"}]`, `{"a":"x"}`},
+		"move member":            {`{"a":1}`, `[{"op":"move","from":"/a","path":"/b"}]`, `{"b":1}`},
+		"copy member":            {`{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`},
+		"escaped pointer":        {`{"a/b":1,"m~n":2}`, `[{"op":"remove","path":"/a~1b"},{"op":"remove","path":"/m~0n"}]`, `{}`},
+		"test then replace":      {`{"a":[1,{"b":true}]}`, `[{"op":"test","path":"/a","value":[1,{"b":true}]},{"op":"replace","path":"/a/1/b","value":false}]`, `{"a":[1,{"b":false}]}`},
+		"replace whole document": {`{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			p, err := DecodeJSONPatch([]byte(tc.patch))
+			require.NoError(t, err)
+
+			// Act
+			result, err := p.Apply([]byte(tc.doc))
+
+			// Assert
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+
+	t.Run("Failed Test Operation", func(t *testing.T) {
+		p, err := DecodeJSONPatch([]byte(`[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`))
+		require.NoError(t, err)
+
+		_, err = p.Apply([]byte(`{"a":1}`))
+
+		assert.ErrorIs(t, err, ErrTestFailed)
+	})
+
+	t.Run("Invalid Operations", func(t *testing.T) {
+		applyErrors := map[string]string{
+			"missing path":          `[{"op":"remove","path":"/missing"}]`,
+			"index out of range":    `[{"op":"add","path":"/a/5","value":1}]`,
+			"leading zero index":    `[{"op":"remove","path":"/a/01"}]`,
+			"move into own child":   `[{"op":"move","from":"/a","path":"/a/0"}]`,
+			"replace missing field": `[{"op":"replace","path":"/b","value":1}]`,
+		}
+		for name, data := range applyErrors {
+			t.Run(name, func(t *testing.T) {
+				p, err := DecodeJSONPatch([]byte(data))
+				require.NoError(t, err)
+
+				_, err = p.Apply([]byte(`{"a":[1,2]}`))
+
+				assert.ErrorIs(t, err, ErrInvalidPatch)
+			})
+		}
+
+		decodeErrors := map[string]string{
+			"not an array":    `{"op":"remove","path":"/a"}`,
+			"unknown op":      `[{"op":"merge","path":"/a"}]`,
+			"missing value":   `[{"op":"add","path":"/a"}]`,
+			"invalid pointer": `[{"op":"remove","path":"a"}]`,
+		}
+		for name, data := range decodeErrors {
+			t.Run(name, func(t *testing.T) {
+				_, err := DecodeJSONPatch([]byte(data))
+
+				assert.ErrorIs(t, err, ErrInvalidPatch)
+			})
+		}
+	})
+}
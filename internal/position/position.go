@@ -0,0 +1,233 @@
+// Package position 은 todo 의 수동 정렬 순서를 위한 fractional index 키를 만든다.
+//
+// 키는 byte 순서로 비교되는 base62 문자열이며, 두 키 사이에는 항상 새 키를 만들 수 있으므로
+// todo 하나를 옮길 때 다른 todo 의 키를 다시 쓰지 않아도 된다.
+//
+// 키는 정수부와 소수부로 이루어진다. 정수부의 첫 글자는 정수부의 길이를 나타내며(a-z 는 양수, A-Z 는 음수),
+// 끝에 추가할 때는 정수부를 1 씩 늘려 키 길이가 로그 수준으로만 늘어나도록 한다.
+// (https://github.com/rocicorp/fractional-indexing 와 같은 방식)
+package position
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// digits 는 ASCII 순서로 정렬된 base62 숫자
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// smallestInteger 는 표현할 수 있는 가장 작은 정수부
+const smallestInteger = "A00000000000000000000000000"
+
+var ErrInvalidKey = errors.New("invalid position key")
+
+// First 는 빈 목록의 첫 번째 키
+const First = "a0"
+
+// Between 은 a 와 b 사이에 정렬되는 새 키를 반환한다.
+// a 가 빈 문자열이면 b 보다 앞, b 가 빈 문자열이면 a 보다 뒤의 키를 만든다. a 는 b 보다 작아야 한다.
+func Between(a, b string) (string, error) {
+	if a != "" {
+		if err := validate(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if err := validate(b); err != nil {
+			return "", err
+		}
+	}
+	if a != "" && b != "" && a >= b {
+		return "", fmt.Errorf("%w: %q is not before %q", ErrInvalidKey, a, b)
+	}
+
+	switch {
+	case a == "" && b == "":
+		return First, nil
+	case a == "":
+		ib := integerPart(b)
+		fb := b[len(ib):]
+		if ib == smallestInteger {
+			return ib + midpoint("", fb), nil
+		}
+		if ib < b {
+			return ib, nil
+		}
+		key, ok := decrementInteger(ib)
+		if !ok {
+			return "", fmt.Errorf("%w: cannot decrement %q", ErrInvalidKey, b)
+		}
+		return key, nil
+	case b == "":
+		ia := integerPart(a)
+		fa := a[len(ia):]
+		key, ok := incrementInteger(ia)
+		if !ok {
+			return ia + midpoint(fa, ""), nil
+		}
+		return key, nil
+	}
+
+	ia, ib := integerPart(a), integerPart(b)
+	fa, fb := a[len(ia):], b[len(ib):]
+	if ia == ib {
+		return ia + midpoint(fa, fb), nil
+	}
+	key, ok := incrementInteger(ia)
+	if !ok {
+		return "", fmt.Errorf("%w: cannot increment %q", ErrInvalidKey, a)
+	}
+	if key < b {
+		return key, nil
+	}
+	return ia + midpoint(fa, ""), nil
+}
+
+// midpoint 는 소수부 a, b 사이의 소수부를 반환한다. b 가 빈 문자열이면 상한이 없다.
+// a, b 는 '0' 으로 끝나지 않아야 한다.
+func midpoint(a, b string) string {
+	if b != "" {
+		// 공통 접두사는 그대로 두고 나머지에서 중간값을 찾는다. (a 의 빈 자리는 '0')
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(digits, a[0])
+	}
+	digitB := len(digits)
+	if b != "" {
+		digitB = strings.IndexByte(digits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		return string(digits[(digitA+digitB+1)/2])
+	}
+	// 연속된 숫자 사이에는 자리수를 늘린다.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[digitA]) + midpoint(rest, "")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return '0'
+}
+
+// integerLength 는 정수부 첫 글자로부터 정수부 길이를 계산한다.
+func integerLength(head byte) (int, bool) {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2, true
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2, true
+	}
+	return 0, false
+}
+
+func integerPart(key string) string {
+	n, _ := integerLength(key[0])
+	return key[:n]
+}
+
+func validate(key string) error {
+	if key == smallestInteger {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	n, ok := integerLength(key[0])
+	if !ok || n > len(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for i := 1; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	if strings.HasSuffix(key[n:], "0") {
+		return fmt.Errorf("%w: %q has a trailing zero", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// incrementInteger 는 정수부에 1 을 더한다. 더 큰 정수부를 표현할 수 없으면 false 를 반환한다.
+func incrementInteger(x string) (string, bool) {
+	head, ds := x[0], []byte(x[1:])
+	carry := true
+	for i := len(ds) - 1; carry && i >= 0; i-- {
+		d := strings.IndexByte(digits, ds[i]) + 1
+		if d == len(digits) {
+			ds[i] = '0'
+		} else {
+			ds[i] = digits[d]
+			carry = false
+		}
+	}
+	if !carry {
+		return string(head) + string(ds), true
+	}
+
+	// 자리 올림: 정수부 길이가 1 늘어난다.
+	switch head {
+	case 'Z':
+		return "a0", true
+	case 'z':
+		return "", false
+	}
+	h := head + 1
+	if h > 'a' {
+		ds = append(ds, '0')
+	} else {
+		ds = ds[:len(ds)-1]
+	}
+	return string(h) + string(ds), true
+}
+
+// decrementInteger 는 정수부에서 1 을 뺀다. 더 작은 정수부를 표현할 수 없으면 false 를 반환한다.
+func decrementInteger(x string) (string, bool) {
+	head, ds := x[0], []byte(x[1:])
+	borrow := true
+	for i := len(ds) - 1; borrow && i >= 0; i-- {
+		d := strings.IndexByte(digits, ds[i]) - 1
+		if d == -1 {
+			ds[i] = digits[len(digits)-1]
+		} else {
+			ds[i] = digits[d]
+			borrow = false
+		}
+	}
+	if !borrow {
+		return string(head) + string(ds), true
+	}
+
+	switch head {
+	case 'a':
+		return "Z" + string(digits[len(digits)-1]), true
+	case 'A':
+		return "", false
+	}
+	h := head - 1
+	if h < 'Z' {
+		ds = append(ds, digits[len(digits)-1])
+	} else {
+		ds = ds[:len(ds)-1]
+	}
+	return string(h) + string(ds), true
+}
@@ -0,0 +1,94 @@
+package position
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	t.Run("Known Keys", func(t *testing.T) {
+		cases := []struct {
+			a, b, want string
+		}{
+			{"", "", "a0"},
+			{"a0", "", "a1"},
+			{"", "a0", "Zz"},
+			{"a0", "a1", "a0V"},
+			{"a1", "a2", "a1V"},
+			{"a0V", "a1", "a0l"},
+			{"az", "", "b00"},
+			{"Zz", "a0", "ZzV"},
+			{"a0", "a0V", "a0G"},
+		}
+		for _, tc := range cases {
+			// Act
+			got, err := Between(tc.a, tc.b)
+
+			// Assert
+			require.NoError(t, err, "%q, %q", tc.a, tc.b)
+			assert.Equal(t, tc.want, got, "%q, %q", tc.a, tc.b)
+		}
+	})
+
+	t.Run("Rejects Invalid Bounds", func(t *testing.T) {
+		for _, bounds := range [][2]string{{"a1", "a0"}, {"a0", "a0"}, {"a00", ""}, {"", "!"}, {"b0", ""}} {
+			_, err := Between(bounds[0], bounds[1])
+			assert.ErrorIs(t, err, ErrInvalidKey, "%q, %q", bounds[0], bounds[1])
+		}
+	})
+
+	t.Run("Random Inserts Stay Ordered And Short", func(t *testing.T) {
+		// Arrange
+		rnd := rand.New(rand.NewSource(1))
+		keys := []string{}
+
+		// Act: 맨 앞, 맨 뒤, 임의의 두 키 사이에 반복해서 추가한다.
+		for i := 0; i < 2000; i++ {
+			var a, b string
+			switch n := rnd.Intn(len(keys) + 2); {
+			case n == 0 && len(keys) > 0:
+				b = keys[0]
+			case n > len(keys) || len(keys) == 0:
+				if len(keys) > 0 {
+					a = keys[len(keys)-1]
+				}
+			default:
+				a = keys[n-1]
+				if n < len(keys) {
+					b = keys[n]
+				}
+			}
+			key, err := Between(a, b)
+			require.NoError(t, err, "%q, %q", a, b)
+			require.Greater(t, key, a)
+			if b != "" {
+				require.Less(t, key, b)
+			}
+			keys = append(keys, key)
+			sort.Strings(keys)
+		}
+
+		// Assert
+		for i := 1; i < len(keys); i++ {
+			assert.NotEqual(t, keys[i-1], keys[i])
+		}
+	})
+
+	t.Run("Appending Grows Keys Slowly", func(t *testing.T) {
+		// Arrange
+		key := ""
+
+		// Act
+		for i := 0; i < 10000; i++ {
+			next, err := Between(key, "")
+			require.NoError(t, err)
+			key = next
+		}
+
+		// Assert
+		assert.LessOrEqual(t, len(key), 4)
+	})
+}
@@ -0,0 +1,43 @@
+// Package problem 은 handler 와 middleware 가 함께 쓰는 RFC 9457 problem details 응답을 제공한다.
+package problem
+
+import (
+	"github.com/gin-gonic/gin"
+	"integration-test-example/internal/validation"
+	"net/http"
+	"strings"
+)
+
+// ContentType 은 RFC 9457 problem details 응답의 media type
+const ContentType = "application/problem+json"
+
+// Problem represents an RFC 9457 problem details object
+type Problem struct {
+	Type     string                  `json:"type"`
+	Title    string                  `json:"title"`
+	Status   int                     `json:"status"`
+	Detail   string                  `json:"detail,omitempty"`
+	Instance string                  `json:"instance,omitempty"`
+	Code     string                  `json:"code"`
+	Errors   []validation.FieldError `json:"errors,omitempty"`
+}
+
+// New 는 에러 코드로 type URI 를 만든 problem 을 생성한다.
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "/problems/" + strings.ReplaceAll(code, "_", "-"),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Write 는 p 를 application/problem+json 으로 응답하고 요청 처리를 중단한다.
+func Write(c *gin.Context, p *Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
@@ -0,0 +1,477 @@
+// Package recurrence 는 반복 todo 의 일정을 RFC 5545 RRULE 로 해석하고 발생 시각을 계산한다.
+//
+// 지원하는 규칙은 RRULE 의 일부이다.
+//
+//   - FREQ: DAILY, WEEKLY, MONTHLY, YEARLY (필수)
+//   - INTERVAL, COUNT, UNTIL (COUNT 와 UNTIL 은 함께 쓸 수 없다)
+//   - BYMONTH, BYMONTHDAY (WEEKLY 제외)
+//   - BYDAY: MONTHLY 와 YEARLY(BYMONTH 와 함께)에서는 "1MO", "-1FR" 같은 순번을 쓸 수 있다.
+//
+// 주는 월요일에 시작한다. (WKST=MO) 그 밖의 규칙(BYSETPOS, BYHOUR 등)은 ErrUnsupported 로 거부한다.
+//
+// 발생 시각은 시작 시각(DTSTART)의 시:분:초와 time zone 을 따르며, 시작 시각은 항상 첫 번째 발생으로 센다.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid recurrence rule")
+	ErrUnsupported = errors.New("unsupported recurrence rule")
+)
+
+// MaxRuleLength 는 RRULE 문자열의 최대 길이
+const MaxRuleLength = 255
+
+// Frequency 는 반복 단위
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// untilLayout 은 UTC 로 표기한 UNTIL 의 형식
+const untilLayout = "20060102T150405Z"
+
+// maxPeriods 는 발생 시각을 찾기 위해 검사하는 최대 반복 단위 수.
+// 2월 30일처럼 발생하지 않는 규칙에서 끝없이 찾지 않도록 한다.
+const maxPeriods = 5000
+
+// WeekdayNum 은 BYDAY 의 요일 하나. N 이 0 이 아니면 기간 안에서 N 번째(음수면 뒤에서 N 번째) 요일이다.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Rule 은 해석된 RRULE
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByMonth    []time.Month
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Parse 는 "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR" 같은 RRULE 을 해석한다. "RRULE:" 접두사는 생략할 수 있다.
+func Parse(s string) (*Rule, error) {
+	if len(s) > MaxRuleLength {
+		return nil, fmt.Errorf("%w: must be at most %d characters", ErrInvalidRule, MaxRuleLength)
+	}
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s is given more than once", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			switch rule.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("%w: FREQ=%s", ErrUnsupported, value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseInt(name, value, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseInt(name, value, 1, 1000)
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "BYMONTH":
+			rule.ByMonth, err = parseMonths(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseMonthDays(value)
+		case "BYDAY":
+			rule.ByDay, err = parseWeekdays(value)
+		default:
+			err = fmt.Errorf("%w: %s", ErrUnsupported, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rule.check(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// check 는 부분 사이의 조합이 올바른지 검사한다.
+func (r *Rule) check() error {
+	if r.Freq == "" {
+		return fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return fmt.Errorf("%w: COUNT and UNTIL cannot be used together", ErrInvalidRule)
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return fmt.Errorf("%w: BYMONTHDAY cannot be used with FREQ=WEEKLY", ErrInvalidRule)
+	}
+	for _, day := range r.ByDay {
+		if day.N == 0 {
+			continue
+		}
+		switch {
+		case r.Freq == Daily || r.Freq == Weekly:
+			return fmt.Errorf("%w: BYDAY ordinals require FREQ=MONTHLY or YEARLY", ErrInvalidRule)
+		case r.Freq == Yearly && len(r.ByMonth) == 0:
+			return fmt.Errorf("%w: BYDAY ordinals with FREQ=YEARLY require BYMONTH", ErrUnsupported)
+		case day.N < -5 || day.N > 5:
+			return fmt.Errorf("%w: BYDAY ordinal must be between -5 and 5", ErrInvalidRule)
+		}
+	}
+	return nil
+}
+
+func parseInt(name, value string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%w: %s must be an integer between %d and %d", ErrInvalidRule, name, lo, hi)
+	}
+	return n, nil
+}
+
+func parseUntil(value string) (*time.Time, error) {
+	if t, err := time.Parse(untilLayout, value); err == nil {
+		return &t, nil
+	}
+	// 날짜만 주어지면 그날 하루를 포함한다.
+	if t, err := time.Parse("20060102", value); err == nil {
+		end := t.Add(24*time.Hour - time.Second)
+		return &end, nil
+	}
+	return nil, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ", ErrInvalidRule)
+}
+
+func parseMonths(value string) ([]time.Month, error) {
+	var months []time.Month
+	for _, v := range strings.Split(value, ",") {
+		n, err := parseInt("BYMONTH", v, 1, 12)
+		if err != nil {
+			return nil, err
+		}
+		months = append(months, time.Month(n))
+	}
+	return months, nil
+}
+
+func parseMonthDays(value string) ([]int, error) {
+	var days []int
+	for _, v := range strings.Split(value, ",") {
+		n, err := parseInt("BYMONTHDAY", v, -31, 31)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("%w: BYMONTHDAY must not be 0", ErrInvalidRule)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
+
+func parseWeekdays(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, v := range strings.Split(value, ",") {
+		if len(v) < 2 {
+			return nil, fmt.Errorf("%w: BYDAY %q", ErrInvalidRule, v)
+		}
+		weekday, ok := weekdayNames[v[len(v)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("%w: BYDAY %q", ErrInvalidRule, v)
+		}
+		day := WeekdayNum{Weekday: weekday}
+		if ordinal := v[:len(v)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 {
+				return nil, fmt.Errorf("%w: BYDAY %q", ErrInvalidRule, v)
+			}
+			day.N = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// String 은 규칙을 정해진 순서의 RRULE 로 나타낸다.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, month := range r.ByMonth {
+			months[i] = strconv.Itoa(int(month))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = strings.ToUpper(day.Weekday.String()[:2])
+			if day.N != 0 {
+				days[i] = strconv.Itoa(day.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences 는 start 에 시작하는 반복의 발생 시각을 처음부터 최대 n 개 반환한다. 첫 번째는 항상 start 이다.
+// COUNT 나 UNTIL 로 반복이 끝나면 n 개보다 적을 수 있다.
+func (r *Rule) Occurrences(start time.Time, n int) []time.Time {
+	if n <= 0 {
+		return nil
+	}
+	if r.Until != nil && start.After(*r.Until) {
+		return nil
+	}
+
+	occurrences := []time.Time{start}
+	limit := n
+	if r.Count > 0 && r.Count < limit {
+		limit = r.Count
+	}
+
+	for period := 0; period < maxPeriods && len(occurrences) < limit; period++ {
+		for _, t := range r.expand(start, period*r.Interval) {
+			if !t.After(start) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return occurrences
+			}
+			occurrences = append(occurrences, t)
+			if len(occurrences) == limit {
+				break
+			}
+		}
+	}
+	return occurrences
+}
+
+// Next 는 start 다음 발생 시각과, start 부터 시작한 반복의 남은 부분을 나타내는 규칙을 반환한다.
+// 다음 발생부터 다시 세도 같은 시각이 나오도록 COUNT 는 1 줄어든다. 반복이 끝났으면 false 를 반환한다.
+func (r *Rule) Next(start time.Time) (time.Time, *Rule, bool) {
+	occurrences := r.Occurrences(start, 2)
+	if len(occurrences) < 2 {
+		return time.Time{}, nil, false
+	}
+
+	rest := *r
+	if rest.Count > 0 {
+		rest.Count--
+	}
+	return occurrences[1], &rest, true
+}
+
+// expand 는 start 가 속한 기간에서 offset 만큼 떨어진 기간(일/주/월/년)의 후보 시각을 시간 순으로 반환한다.
+func (r *Rule) expand(start time.Time, offset int) []time.Time {
+	year, month, day := start.Date()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	}
+
+	var candidates []time.Time
+	switch r.Freq {
+	case Daily:
+		candidates = []time.Time{at(year, month, day+offset)}
+	case Weekly:
+		// 주는 월요일에 시작한다.
+		monday := day - (int(start.Weekday())+6)%7 + offset*7
+		if len(r.ByDay) == 0 {
+			candidates = []time.Time{at(year, month, day+offset*7)}
+		}
+		for _, wd := range r.ByDay {
+			candidates = append(candidates, at(year, month, monday+(int(wd.Weekday)+6)%7))
+		}
+	case Monthly:
+		first := at(year, month+time.Month(offset), 1)
+		candidates = r.expandMonth(first.Year(), first.Month(), day, at)
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{month}
+		}
+		for _, m := range months {
+			candidates = append(candidates, r.expandMonth(year+offset, m, day, at)...)
+		}
+	}
+
+	filtered := candidates[:0]
+	for _, t := range candidates {
+		if r.matches(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Before(filtered[j]) })
+	return dedupe(filtered)
+}
+
+// expandMonth 는 y 년 m 월 안의 후보 시각을 반환한다. BYMONTHDAY 와 BYDAY 가 모두 없으면 시작 날짜(day)와 같은 날이다.
+func (r *Rule) expandMonth(y int, m time.Month, day int, at func(int, time.Month, int) time.Time) []time.Time {
+	last := daysIn(y, m)
+
+	var days []int
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = last + d + 1
+			}
+			if d >= 1 && d <= last {
+				days = append(days, d)
+			}
+		}
+	case len(r.ByDay) > 0:
+		for _, wd := range r.ByDay {
+			days = append(days, weekdaysInMonth(y, m, wd)...)
+		}
+	case day <= last:
+		// 31일 같이 해당 월에 없는 날은 건너뛴다.
+		days = []int{day}
+	}
+
+	candidates := make([]time.Time, 0, len(days))
+	for _, d := range days {
+		candidates = append(candidates, at(y, m, d))
+	}
+	return candidates
+}
+
+// weekdaysInMonth 는 y 년 m 월에서 wd 에 해당하는 날짜들을 반환한다.
+func weekdaysInMonth(y int, m time.Month, wd WeekdayNum) []int {
+	firstWeekday := time.Date(y, m, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	first := 1 + (int(wd.Weekday)-int(firstWeekday)+7)%7
+
+	var days []int
+	for d := first; d <= daysIn(y, m); d += 7 {
+		days = append(days, d)
+	}
+	switch {
+	case wd.N > 0 && wd.N <= len(days):
+		return days[wd.N-1 : wd.N]
+	case wd.N < 0 && -wd.N <= len(days):
+		return days[len(days)+wd.N : len(days)+wd.N+1]
+	case wd.N == 0:
+		return days
+	}
+	return nil
+}
+
+// matches 는 expand 로 만든 후보가 확장에 쓰이지 않은 BY 규칙을 만족하는지 확인한다.
+func (r *Rule) matches(t time.Time) bool {
+	if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, t.Month()) {
+		return false
+	}
+	if len(r.ByDay) > 0 {
+		// MONTHLY / YEARLY 에서 BYMONTHDAY 가 없으면 BYDAY 로 확장했으므로 다시 확인할 필요가 없다.
+		expandedByDay := (r.Freq == Monthly || r.Freq == Yearly) && len(r.ByMonthDay) == 0
+		if !expandedByDay && !r.matchesWeekday(t) {
+			return false
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == Daily && !matchesMonthDay(r.ByMonthDay, t) {
+		return false
+	}
+	return true
+}
+
+func (r *Rule) matchesWeekday(t time.Time) bool {
+	for _, wd := range r.ByDay {
+		if wd.Weekday != t.Weekday() {
+			continue
+		}
+		if wd.N == 0 {
+			return true
+		}
+		for _, d := range weekdaysInMonth(t.Year(), t.Month(), wd) {
+			if d == t.Day() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesMonthDay(days []int, t time.Time) bool {
+	last := daysIn(t.Year(), t.Month())
+	for _, d := range days {
+		if d == t.Day() || (d < 0 && last+d+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMonth(months []time.Month, m time.Month) bool {
+	for _, month := range months {
+		if month == m {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func dedupe(times []time.Time) []time.Time {
+	result := times[:0]
+	for i, t := range times {
+		if i == 0 || !t.Equal(times[i-1]) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,200 @@
+package recurrence
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Canonical Form", func(t *testing.T) {
+		cases := []struct {
+			rule, want string
+		}{
+			{"FREQ=DAILY", "FREQ=DAILY"},
+			{"rrule:freq=weekly;byday=mo,tu,we,th,fr", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+			{"FREQ=MONTHLY;BYDAY=1MO", "FREQ=MONTHLY;BYDAY=1MO"},
+			{"COUNT=5;INTERVAL=2;FREQ=MONTHLY;BYMONTHDAY=-1", "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=-1;COUNT=5"},
+			{"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH"},
+			{"FREQ=DAILY;INTERVAL=1;UNTIL=20261231", "FREQ=DAILY;UNTIL=20261231T235959Z"},
+		}
+		for _, tc := range cases {
+			// Act
+			rule, err := Parse(tc.rule)
+
+			// Assert
+			require.NoError(t, err, tc.rule)
+			assert.Equal(t, tc.want, rule.String(), tc.rule)
+		}
+	})
+
+	t.Run("Rejects Invalid Rules", func(t *testing.T) {
+		for _, rule := range []string{
+			"",
+			"INTERVAL=2",
+			"FREQ=DAILY;FREQ=WEEKLY",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=DAILY;COUNT=2;UNTIL=20261231",
+			"FREQ=WEEKLY;BYDAY=1MO",
+			"FREQ=WEEKLY;BYMONTHDAY=1",
+			"FREQ=MONTHLY;BYMONTHDAY=0",
+			"FREQ=MONTHLY;BYDAY=XX",
+			"FREQ=DAILY;UNTIL=tomorrow",
+			"FREQ",
+		} {
+			_, err := Parse(rule)
+			assert.ErrorIs(t, err, ErrInvalidRule, rule)
+		}
+	})
+
+	t.Run("Rejects Unsupported Rules", func(t *testing.T) {
+		for _, rule := range []string{
+			"FREQ=HOURLY",
+			"FREQ=MONTHLY;BYSETPOS=-1;BYDAY=MO,TU,WE,TH,FR",
+			"FREQ=YEARLY;BYDAY=1MO",
+		} {
+			_, err := Parse(rule)
+			assert.ErrorIs(t, err, ErrUnsupported, rule)
+		}
+	})
+}
+
+func TestOccurrences(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 30, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name  string
+		rule  string
+		start time.Time
+		n     int
+		want  []time.Time
+	}{
+		{
+			name:  "Every Weekday",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			start: date(2026, 10, 15), // 목요일
+			n:     4,
+			want:  []time.Time{date(2026, 10, 15), date(2026, 10, 16), date(2026, 10, 19), date(2026, 10, 20)},
+		},
+		{
+			name:  "First Monday Of The Month",
+			rule:  "FREQ=MONTHLY;BYDAY=1MO",
+			start: date(2026, 10, 5),
+			n:     3,
+			want:  []time.Time{date(2026, 10, 5), date(2026, 11, 2), date(2026, 12, 7)},
+		},
+		{
+			name:  "Last Day Of The Month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: date(2027, 1, 31),
+			n:     3,
+			want:  []time.Time{date(2027, 1, 31), date(2027, 2, 28), date(2027, 3, 31)},
+		},
+		{
+			name:  "Skips Months Without The Day",
+			rule:  "FREQ=MONTHLY",
+			start: date(2027, 1, 31),
+			n:     3,
+			want:  []time.Time{date(2027, 1, 31), date(2027, 3, 31), date(2027, 5, 31)},
+		},
+		{
+			name:  "Every Other Week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2",
+			start: date(2026, 10, 14),
+			n:     3,
+			want:  []time.Time{date(2026, 10, 14), date(2026, 10, 28), date(2026, 11, 11)},
+		},
+		{
+			name:  "Yearly On The Fourth Thursday Of November",
+			rule:  "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			start: date(2026, 11, 26),
+			n:     2,
+			want:  []time.Time{date(2026, 11, 26), date(2027, 11, 25)},
+		},
+		{
+			name:  "Daily On Weekends Only",
+			rule:  "FREQ=DAILY;BYDAY=SA,SU",
+			start: date(2026, 10, 17),
+			n:     3,
+			want:  []time.Time{date(2026, 10, 17), date(2026, 10, 18), date(2026, 10, 24)},
+		},
+		{
+			name:  "Stops At Count",
+			rule:  "FREQ=DAILY;COUNT=2",
+			start: date(2026, 10, 17),
+			n:     5,
+			want:  []time.Time{date(2026, 10, 17), date(2026, 10, 18)},
+		},
+		{
+			name:  "Stops At Until",
+			rule:  "FREQ=DAILY;UNTIL=20261019",
+			start: date(2026, 10, 17),
+			n:     5,
+			want:  []time.Time{date(2026, 10, 17), date(2026, 10, 18), date(2026, 10, 19)},
+		},
+		{
+			name:  "Never Occurring Day",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			start: date(2026, 10, 17),
+			n:     3,
+			want:  []time.Time{date(2026, 10, 17)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			rule, err := Parse(tc.rule)
+			require.NoError(t, err)
+
+			// Act
+			got := rule.Occurrences(tc.start, tc.n)
+
+			// Assert
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	t.Run("Decrements Count", func(t *testing.T) {
+		// Arrange
+		start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+		rule, err := Parse("FREQ=WEEKLY;COUNT=2")
+		require.NoError(t, err)
+
+		// Act
+		next, rest, ok := rule.Next(start)
+
+		// Assert
+		require.True(t, ok)
+		assert.Equal(t, start.AddDate(0, 0, 7), next)
+		assert.Equal(t, "FREQ=WEEKLY;COUNT=1", rest.String())
+		assert.Equal(t, 2, rule.Count, "원래 규칙은 바뀌지 않아야 한다")
+
+		_, _, ok = rest.Next(next)
+		assert.False(t, ok, "마지막 발생 다음은 없어야 한다")
+	})
+
+	t.Run("Keeps Local Time Across Daylight Saving", func(t *testing.T) {
+		// Arrange
+		newYork, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skip("time zone database is not available")
+		}
+		start := time.Date(2026, 10, 31, 9, 0, 0, 0, newYork)
+		rule, err := Parse("FREQ=DAILY")
+		require.NoError(t, err)
+
+		// Act
+		next, _, ok := rule.Next(start)
+
+		// Assert
+		require.True(t, ok)
+		assert.Equal(t, time.Date(2026, 11, 1, 9, 0, 0, 0, newYork), next)
+		assert.Equal(t, 25*time.Hour, next.Sub(start))
+	})
+}
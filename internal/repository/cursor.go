@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"integration-test-example/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// cursor 는 keyset 페이지네이션의 마지막 행 위치를 담는다.
+// 클라이언트에게는 base64 로 인코딩된 불투명한 문자열로만 노출된다.
+type cursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+	// Query 는 검색어(q). 다른 검색의 cursor 를 재사용하지 못하게 한다.
+	Query string `json:"q,omitempty"`
+}
+
+func encodeCursor(query model.TodoListQuery, last *model.Todo) string {
+	c := cursor{
+		Sort:  query.Sort,
+		Order: query.Order,
+		Value: sortValue(query.Sort, last),
+		ID:    last.ID,
+		Query: query.Q,
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 는 cursor 를 해석하고, 현재 쿼리와 정렬 조건이 다르면 거부한다.
+func decodeCursor(query model.TodoListQuery) (*cursor, error) {
+	if query.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(query.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.Sort != query.Sort || c.Order != query.Order || c.Query != query.Q {
+		return nil, ErrInvalidCursor
+	}
+
+	switch query.Sort {
+	case model.SortTitle, model.SortPosition:
+	case model.SortPriority:
+		if _, ok := model.PriorityFromRank(atoi(c.Value)); !ok {
+			return nil, ErrInvalidCursor
+		}
+	case model.SortRelevance:
+		if _, err := strconv.ParseFloat(c.Value, 64); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	default:
+		if _, err := time.Parse(time.RFC3339Nano, c.Value); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return &c, nil
+}
+
+// sortValue 는 정렬 컬럼 값을 cursor 에 저장할 문자열로 변환한다.
+func sortValue(sort string, todo *model.Todo) string {
+	switch sort {
+	case model.SortUpdatedAt:
+		return todo.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case model.SortTitle:
+		return todo.Title
+	case model.SortPriority:
+		return strconv.Itoa(todo.Priority.Rank())
+	case model.SortPosition:
+		return todo.Position
+	case model.SortDeletedAt:
+		return deletedAt(todo).UTC().Format(time.RFC3339Nano)
+	case model.SortRelevance:
+		return strconv.FormatFloat(score(todo), 'g', -1, 64)
+	default:
+		return todo.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// cursorArg 는 cursor 값을 SQL 바인딩 인자로 변환한다.
+func (c *cursor) cursorArg() interface{} {
+	switch c.Sort {
+	case model.SortTitle, model.SortPosition:
+		return c.Value
+	case model.SortPriority:
+		return atoi(c.Value)
+	case model.SortRelevance:
+		v, _ := strconv.ParseFloat(c.Value, 64)
+		return v
+	}
+	t, _ := time.Parse(time.RFC3339Nano, c.Value)
+	return t
+}
+
+// compareTodos 는 sort 컬럼, id 순으로 두 todo 를 비교한다. (-1, 0, 1)
+func compareTodos(sort string, a, b *model.Todo) int {
+	var cmp int
+	switch sort {
+	case model.SortUpdatedAt:
+		cmp = a.UpdatedAt.Compare(b.UpdatedAt)
+	case model.SortTitle:
+		cmp = strings.Compare(a.Title, b.Title)
+	case model.SortPriority:
+		cmp = compareNumbers(a.Priority.Rank(), b.Priority.Rank())
+	case model.SortPosition:
+		cmp = strings.Compare(a.Position, b.Position)
+	case model.SortDeletedAt:
+		cmp = deletedAt(a).Compare(deletedAt(b))
+	case model.SortRelevance:
+		cmp = compareNumbers(score(a), score(b))
+	default:
+		cmp = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if cmp != 0 {
+		return cmp
+	}
+
+	switch {
+	case a.ID < b.ID:
+		return -1
+	case a.ID > b.ID:
+		return 1
+	}
+	return 0
+}
+
+// after 는 todo 가 cursor 위치보다 뒤에 오는지 판단한다.
+func (c *cursor) after(todo *model.Todo) bool {
+	pivot := &model.Todo{ID: c.ID}
+	switch c.Sort {
+	case model.SortUpdatedAt:
+		pivot.UpdatedAt, _ = time.Parse(time.RFC3339Nano, c.Value)
+	case model.SortTitle:
+		pivot.Title = c.Value
+	case model.SortPriority:
+		pivot.Priority, _ = model.PriorityFromRank(atoi(c.Value))
+	case model.SortPosition:
+		pivot.Position = c.Value
+	case model.SortDeletedAt:
+		deleted, _ := time.Parse(time.RFC3339Nano, c.Value)
+		pivot.DeletedAt = &deleted
+	case model.SortRelevance:
+		v, _ := strconv.ParseFloat(c.Value, 64)
+		pivot.Match = &model.TodoMatch{Score: v}
+	default:
+		pivot.CreatedAt, _ = time.Parse(time.RFC3339Nano, c.Value)
+	}
+
+	cmp := compareTodos(c.Sort, todo, pivot)
+	if c.Order == model.OrderAsc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// deletedAt 은 휴지통으로 옮겨진 시각을 반환한다. 활성 todo 는 zero time 이다.
+func deletedAt(todo *model.Todo) time.Time {
+	if todo.DeletedAt == nil {
+		return time.Time{}
+	}
+	return *todo.DeletedAt
+}
+
+// score 는 검색 관련도를 반환한다. 검색으로 조회되지 않은 todo 는 0 이다.
+func score(todo *model.Todo) float64 {
+	if todo.Match == nil {
+		return 0
+	}
+	return todo.Match.Score
+}
+
+func compareNumbers[T int | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// atoi 는 숫자가 아니면 -1 을 반환한다.
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/position"
+	"strings"
+	"time"
+)
+
+// ErrItemNotFound 는 todo 에 해당 item 이 없을 때 반환된다.
+var ErrItemNotFound = errors.New("todo item not found")
+
+// itemColumns 는 item 조회 시 scanItem 과 같은 순서로 읽는 컬럼 목록
+const itemColumns = "id, todo_id, title, completed, position, created_at, updated_at"
+
+func scanItem(row rowScanner) (*model.TodoItem, error) {
+	item := &model.TodoItem{}
+	err := row.Scan(&item.ID, &item.TodoID, &item.Title, &item.Completed, &item.Position, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// loadDetails 는 todos 의 태그와 progress 를 todo 수와 관계없이 각각 한 번의 쿼리로 채운다.
+func loadDetails(q dbConn, todos ...*model.Todo) error {
+	if err := loadTags(q, todos...); err != nil {
+		return err
+	}
+	return loadProgress(q, todos...)
+}
+
+// loadProgress 는 todos 의 item 완료 현황을 한 번의 쿼리로 집계해 채운다.
+func loadProgress(q dbConn, todos ...*model.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Todo, len(todos))
+	placeholders := make([]string, len(todos))
+	args := make([]interface{}, len(todos))
+	for i, todo := range todos {
+		todo.Progress = nil
+		byID[todo.ID] = todo
+		placeholders[i] = "?"
+		args[i] = todo.ID
+	}
+
+	query := `
+		SELECT todo_id, COUNT(*), COALESCE(SUM(completed), 0)
+		FROM todo_items
+		WHERE todo_id IN (` + strings.Join(placeholders, ", ") + `)
+		GROUP BY todo_id
+	`
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todoID int64
+		var total, completed int
+		if err := rows.Scan(&todoID, &total, &completed); err != nil {
+			return err
+		}
+		if todo, ok := byID[todoID]; ok {
+			todo.Progress = model.NewTodoProgress(completed, total)
+		}
+	}
+	return rows.Err()
+}
+
+// checkOwner 는 범위가 정해진 저장소면 todoID 의 todo 가 소유자의 것인지 확인한다. 아니면 ErrTodoNotFound 를 반환한다.
+// item 은 부모 todo 의 소유자를 따른다.
+func (r TodoRepository) checkOwner(q queryRower, todoID int) error {
+	if r.ownerID == 0 {
+		return nil
+	}
+
+	var exists int
+	err := q.QueryRow(`SELECT 1 FROM todos WHERE id = ? AND user_id = ?`, todoID, r.ownerID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return ErrTodoNotFound
+	}
+	return err
+}
+
+// GetItems 는 todo 의 item 을 순서대로 조회한다.
+func (r TodoRepository) GetItems(todoID int) ([]*model.TodoItem, error) {
+	if err := r.checkOwner(r.conn(), todoID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn().Query(`SELECT `+itemColumns+` FROM todo_items WHERE todo_id = ? ORDER BY position`, todoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*model.TodoItem, 0)
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r TodoRepository) GetItem(todoID, itemID int) (*model.TodoItem, error) {
+	if err := r.checkOwner(r.conn(), todoID); err != nil {
+		return nil, err
+	}
+	return getItem(r.conn(), todoID, itemID, false)
+}
+
+func getItem(q queryRower, todoID, itemID int, forUpdate bool) (*model.TodoItem, error) {
+	query := `SELECT ` + itemColumns + ` FROM todo_items WHERE id = ? AND todo_id = ?`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	item, err := scanItem(q.QueryRow(query, itemID, todoID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// CreateItem 은 item 을 todo 의 item 중 맨 뒤에 추가한다.
+func (r TodoRepository) CreateItem(item *model.TodoItem) (*model.TodoItem, error) {
+	query := `INSERT INTO todo_items (todo_id, title, completed, position, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+
+	now := time.Now()
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	err := r.inTx(func(tx *sql.Tx) error {
+		if err := r.checkOwner(tx, int(item.TodoID)); err != nil {
+			return err
+		}
+		last, err := lastItemPosition(tx, item.TodoID)
+		if err != nil {
+			return err
+		}
+		if item.Position, err = position.Between(last, ""); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(query, item.TodoID, item.Title, item.Completed, item.Position, item.CreatedAt, item.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		item.ID, err = result.LastInsertId()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// UpdateItem 은 item 의 title, completed 를 수정한다.
+func (r TodoRepository) UpdateItem(item *model.TodoItem) (*model.TodoItem, error) {
+	query := `UPDATE todo_items SET title = ?, completed = ?, updated_at = ? WHERE id = ? AND todo_id = ?`
+
+	var updated *model.TodoItem
+	err := r.inTx(func(tx *sql.Tx) error {
+		if err := r.checkOwner(tx, int(item.TodoID)); err != nil {
+			return err
+		}
+		stored, err := getItem(tx, int(item.TodoID), int(item.ID), true)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if _, err := tx.Exec(query, item.Title, item.Completed, now, item.ID, item.TodoID); err != nil {
+			return err
+		}
+
+		stored.Title = item.Title
+		stored.Completed = item.Completed
+		stored.UpdatedAt = now
+		updated = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (r TodoRepository) DeleteItem(todoID, itemID int) error {
+	if err := r.checkOwner(r.conn(), todoID); err != nil {
+		return err
+	}
+
+	result, err := r.conn().Exec(`DELETE FROM todo_items WHERE id = ? AND todo_id = ?`, itemID, todoID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+// MoveItem 은 item 을 같은 todo 의 anchorID item 바로 뒤(after) 또는 바로 앞으로 옮긴다.
+func (r TodoRepository) MoveItem(todoID, itemID, anchorID int, after bool) (*model.TodoItem, error) {
+	query := `UPDATE todo_items SET position = ?, updated_at = ? WHERE id = ?`
+
+	var moved *model.TodoItem
+	err := r.inTx(func(tx *sql.Tx) error {
+		if err := r.checkOwner(tx, todoID); err != nil {
+			return err
+		}
+		item, err := getItem(tx, todoID, itemID, true)
+		if err != nil {
+			return err
+		}
+		anchor, err := getItem(tx, todoID, anchorID, true)
+		if errors.Is(err, ErrItemNotFound) {
+			return ErrAnchorNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		adjacent, err := adjacentItemPosition(tx, item.TodoID, anchor.Position, after, item.ID)
+		if err != nil {
+			return err
+		}
+		lo, hi := anchor.Position, adjacent
+		if !after {
+			lo, hi = adjacent, anchor.Position
+		}
+		if item.Position, err = position.Between(lo, hi); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if _, err := tx.Exec(query, item.Position, now, item.ID); err != nil {
+			return err
+		}
+		item.UpdatedAt = now
+		moved = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+// lastItemPosition 은 todo 의 item 중 마지막 키를 반환한다. item 이 없으면 빈 문자열이다.
+func lastItemPosition(tx *sql.Tx, todoID int64) (string, error) {
+	var last string
+	err := tx.QueryRow(`SELECT position FROM todo_items WHERE todo_id = ? ORDER BY position DESC LIMIT 1 FOR UPDATE`, todoID).Scan(&last)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return last, err
+}
+
+// adjacentItemPosition 은 todo 의 item 중 key 바로 뒤(after) 또는 바로 앞의 키를 반환한다. 없으면 빈 문자열이다.
+// 옮기려는 item(excludeID) 은 제외한다.
+func adjacentItemPosition(tx *sql.Tx, todoID int64, key string, after bool, excludeID int64) (string, error) {
+	query := `SELECT position FROM todo_items WHERE todo_id = ? AND position < ? AND id <> ? ORDER BY position DESC LIMIT 1 FOR UPDATE`
+	if after {
+		query = `SELECT position FROM todo_items WHERE todo_id = ? AND position > ? AND id <> ? ORDER BY position LIMIT 1 FOR UPDATE`
+	}
+
+	var adjacent string
+	err := tx.QueryRow(query, todoID, key, excludeID).Scan(&adjacent)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return adjacent, err
+}
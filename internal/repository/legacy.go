@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"database/sql"
+	"integration-test-example/internal/position"
+)
+
+// ClaimedRows 는 ClaimUnowned 가 소유자에게 넘긴 행 수다. 합쳐진 태그도 Tags 에 포함된다.
+type ClaimedRows struct {
+	Todos int64
+	Lists int64
+	Tags  int64
+}
+
+// ClaimUnowned 는 사용자 도입 전에 만들어져 소유자가 없는 (user_id 가 NULL 인) todo, list, 태그를 ownerID 사용자에게 넘긴다.
+// 소유자가 없는 행은 어떤 사용자의 API 에도 보이지 않으므로, 기존 데이터를 계속 쓰려면 배포 후 한 번 실행한다. (server claim-unowned)
+//
+// todo 는 기존 순서를 유지한 채 소유자의 마지막 todo 뒤로 옮겨지고, 소유자에게 같은 이름의 태그가 있으면 그 태그로 합쳐진다.
+// position 이 바뀌므로 넘겨받은 todo 의 version 을 1 올린다. 사용자가 todo 를 수정한 것은 아니므로 updated_at 은 그대로 두고 이벤트도 기록하지 않는다.
+func (r *TodoRepository) ClaimUnowned(ownerID int64) (ClaimedRows, error) {
+	var claimed ClaimedRows
+	err := r.inTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`UPDATE lists SET user_id = ? WHERE user_id IS NULL`, ownerID)
+		if err != nil {
+			return err
+		}
+		if claimed.Lists, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		if claimed.Tags, err = claimUnownedTags(tx, ownerID); err != nil {
+			return err
+		}
+
+		claimed.Todos, err = r.withOwner(ownerID).claimUnownedTodos(tx)
+		return err
+	})
+	if err != nil {
+		return ClaimedRows{}, err
+	}
+
+	return claimed, nil
+}
+
+// claimUnownedTags 는 소유자가 없는 태그를 ownerID 사용자에게 넘긴다.
+// 같은 이름의 태그가 이미 있으면 붙어 있던 todo 를 그 태그로 옮기고 소유자가 없는 태그는 삭제한다.
+func claimUnownedTags(tx *sql.Tx, ownerID int64) (int64, error) {
+	rows, err := tx.Query(`SELECT id, name FROM tags WHERE user_id IS NULL ORDER BY id FOR UPDATE`)
+	if err != nil {
+		return 0, err
+	}
+	type unownedTag struct {
+		id   int64
+		name string
+	}
+	var tags []unownedTag
+	for rows.Next() {
+		var tag unownedTag
+		if err := rows.Scan(&tag.id, &tag.name); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		tags = append(tags, tag)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, tag := range tags {
+		var existingID int64
+		err := tx.QueryRow(`SELECT id FROM tags WHERE user_id = ? AND name = ? FOR UPDATE`, ownerID, tag.name).Scan(&existingID)
+		if err == sql.ErrNoRows {
+			if _, err := tx.Exec(`UPDATE tags SET user_id = ? WHERE id = ?`, ownerID, tag.id); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := tx.Exec(`INSERT IGNORE INTO todo_tags (todo_id, tag_id) SELECT todo_id, ? FROM todo_tags WHERE tag_id = ?`, existingID, tag.id); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, tag.id); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(tags)), nil
+}
+
+// claimUnownedTodos 는 소유자가 없는 todo 를 기존 position 순서대로 저장소 소유자의 마지막 todo 뒤에 붙인다. 휴지통의 todo 도 포함한다.
+func (r TodoRepository) claimUnownedTodos(tx *sql.Tx) (int64, error) {
+	rows, err := tx.Query(`SELECT id FROM todos WHERE user_id IS NULL ORDER BY position FOR UPDATE`)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	last, err := r.lastPosition(tx)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		if last, err = position.Between(last, ""); err != nil {
+			return 0, err
+		}
+		_, err := tx.Exec(`UPDATE todos SET user_id = ?, position = ?, version = version + 1, updated_at = updated_at WHERE id = ?`,
+			r.ownerID, last, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(ids)), nil
+}
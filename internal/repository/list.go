@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"integration-test-example/internal/model"
+	"strings"
+	"time"
+)
+
+// ErrListNotFound 는 list 가 없거나, todo 의 ListID 가 없는 list 를 가리킬 때 반환된다.
+var ErrListNotFound = errors.New("list not found")
+
+// ListStore 는 todo 를 묶는 list 를 관리하는 계약이다. todo 를 list 에 넣고 빼는 것은 todo 의 ListID 변경이므로 TodoStore 가 담당한다.
+//
+// ListsForOwner 로 범위가 정해진 저장소는 소유자의 list 만 다루며, 다른 사용자의 list 는 없는 것으로 취급한다. (ErrListNotFound)
+type ListStore interface {
+	// ListsForOwner 는 ownerID 사용자의 list 만 다루는 저장소를 반환한다. 이 저장소로 만든 list 는 ownerID 사용자의 것이 된다.
+	ListsForOwner(ownerID int64) ListStore
+
+	// GetLists 는 모든 list 를 만든 순서로 조회한다.
+	GetLists() ([]*model.List, error)
+	GetList(id int) (*model.List, error)
+	CreateList(list *model.List) (*model.List, error)
+	RenameList(id int, name string) (*model.List, error)
+	// DeleteList 는 list 를 삭제하고 영향을 받은 활성 todo 수를 반환한다.
+	// purge 가 false 면 속한 활성 todo 를 list 에서 빼 휴지통으로 옮기고, 휴지통에 있던 todo 는 list 없이 남긴다.
+	// purge 가 true 면 휴지통의 todo 를 포함해 속한 todo 를 영구 삭제한다.
+	// 어느 경우든 활성 todo 마다 같은 트랜잭션 안에서 events 를 outbox 에 기록한다.
+	// 범위가 정해진 저장소는 소유자의 todo 만 옮기거나 삭제한다.
+	DeleteList(id int, purge bool, events ...string) (int64, error)
+}
+
+var (
+	_ ListStore = (*TodoRepository)(nil)
+	_ ListStore = (*MemoryTodoRepository)(nil)
+)
+
+// mysqlNoReferencedRow 는 외래 키가 가리키는 행이 없을 때의 에러 번호 (ER_NO_REFERENCED_ROW_2)
+const mysqlNoReferencedRow = 1452
+
+// mapListReference 는 todo 의 list_id 외래 키 위반을 ErrListNotFound 로 바꾼다.
+func mapListReference(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlNoReferencedRow {
+		return ErrListNotFound
+	}
+	return err
+}
+
+func (r *TodoRepository) ListsForOwner(ownerID int64) ListStore {
+	return r.withOwner(ownerID)
+}
+
+func (r *TodoRepository) GetLists() ([]*model.List, error) {
+	query := `SELECT id, user_id, name, created_at, updated_at FROM lists`
+	conditions, args := r.ownerFilter(nil, nil)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := r.conn().Query(query+" ORDER BY created_at, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := make([]*model.List, 0)
+	for rows.Next() {
+		list := &model.List{}
+		if err := rows.Scan(&list.ID, &list.UserID, &list.Name, &list.CreatedAt, &list.UpdatedAt); err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+func (r *TodoRepository) GetList(id int) (*model.List, error) {
+	return r.getList(r.conn(), id, false)
+}
+
+// getList 는 저장소가 다룰 수 있는 list 를 조회한다. 다른 사용자의 list 는 ErrListNotFound 다.
+func (r TodoRepository) getList(q queryRower, id int, forUpdate bool) (*model.List, error) {
+	conditions, args := r.ownerFilter([]string{"id = ?"}, []interface{}{id})
+	query := `SELECT id, user_id, name, created_at, updated_at FROM lists WHERE ` + strings.Join(conditions, " AND ")
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	list := &model.List{}
+	err := q.QueryRow(query, args...).Scan(&list.ID, &list.UserID, &list.Name, &list.CreatedAt, &list.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// checkList 는 todo 의 listID 가 nil 이거나 저장소가 다룰 수 있는 list 를 가리키는지 확인한다.
+// 외래 키는 list 가 있는지만 확인하므로 다른 사용자의 list 는 여기서 거른다.
+func (r TodoRepository) checkList(q queryRower, listID *int64) error {
+	if listID == nil {
+		return nil
+	}
+	_, err := r.getList(q, int(*listID), false)
+	return err
+}
+
+func (r *TodoRepository) CreateList(list *model.List) (*model.List, error) {
+	now := time.Now()
+	list.UserID = r.owner()
+	list.CreatedAt = now
+	list.UpdatedAt = now
+
+	result, err := r.conn().Exec(`INSERT INTO lists (user_id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		list.UserID, list.Name, list.CreatedAt, list.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.ID, err = result.LastInsertId(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *TodoRepository) RenameList(id int, name string) (*model.List, error) {
+	var renamed *model.List
+	err := r.inTx(func(tx *sql.Tx) error {
+		list, err := r.getList(tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if _, err := tx.Exec(`UPDATE lists SET name = ?, updated_at = ? WHERE id = ?`, name, now, id); err != nil {
+			return err
+		}
+
+		list.Name = name
+		list.UpdatedAt = now
+		renamed = list
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return renamed, nil
+}
+
+func (r *TodoRepository) DeleteList(id int, purge bool, events ...string) (int64, error) {
+	var affected int64
+	err := r.inTx(func(tx *sql.Tx) error {
+		if _, err := r.getList(tx, id, true); err != nil {
+			return err
+		}
+
+		todos, err := r.listTodos(tx, id)
+		if err != nil {
+			return err
+		}
+		// 영구 삭제하면 태그와 item 도 지워지므로 이벤트 payload 를 먼저 채운다.
+		if err := loadDetails(tx, todos...); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if purge {
+			conditions, args := r.ownerFilter([]string{"list_id = ?"}, []interface{}{id})
+			_, err = tx.Exec(`DELETE FROM todos WHERE `+strings.Join(conditions, " AND "), args...)
+		} else {
+			conditions, args := r.ownerFilter([]string{"list_id = ?", "deleted_at IS NULL"}, []interface{}{now, now, id})
+			query := `
+				UPDATE todos
+				SET list_id = NULL, deleted_at = ?, version = version + 1, updated_at = ?
+				WHERE ` + strings.Join(conditions, " AND ")
+			_, err = tx.Exec(query, args...)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, todo := range todos {
+			if !purge {
+				todo.ListID = nil
+			}
+			todo.DeletedAt = &now
+			todo.Version++
+			todo.UpdatedAt = now
+			if err := insertEvents(tx, todo, events); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM lists WHERE id = ?`, id); err != nil {
+			return err
+		}
+		affected = int64(len(todos))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+// listTodos 는 list 에 속한 저장소의 활성 todo 를 잠그고 조회한다.
+func (r TodoRepository) listTodos(tx *sql.Tx, listID int) ([]*model.Todo, error) {
+	conditions, args := r.ownerFilter([]string{"list_id = ?", "deleted_at IS NULL"}, []interface{}{listID})
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY id FOR UPDATE`
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := make([]*model.Todo, 0)
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
@@ -0,0 +1,1350 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/position"
+	"integration-test-example/internal/search"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryTodoRepository 는 todo 를 프로세스 메모리에 저장한다. 단위 테스트나 MySQL 없이 서버를 띄울 때 사용한다.
+//
+// ForOwner 로 만든 저장소는 같은 memoryData 를 공유하며, ownerID 사용자의 todo 만 다룬다.
+type MemoryTodoRepository struct {
+	*memoryData
+	ownerID int64
+}
+
+// memoryData 는 MemoryTodoRepository 가 공유하는 저장소 상태
+type memoryData struct {
+	mu     sync.RWMutex
+	todos  map[int64]*model.Todo
+	nextID int64
+
+	outbox       []*model.OutboxEvent
+	nextOutboxID int64
+
+	tags      map[int64]*model.Tag
+	nextTagID int64
+	// todoTags 는 todo id 별로 붙은 태그 id 집합. 저장된 todo 의 Tags, Progress 는 비워 두고 조회할 때 채운다.
+	todoTags map[int64]map[int64]bool
+
+	items      map[int64]*model.TodoItem
+	nextItemID int64
+
+	lists      map[int64]*model.List
+	nextListID int64
+
+	users      map[int64]*model.User
+	nextUserID int64
+}
+
+func NewMemoryTodoRepository() *MemoryTodoRepository {
+	return &MemoryTodoRepository{memoryData: &memoryData{
+		todos:        make(map[int64]*model.Todo),
+		nextID:       1,
+		nextOutboxID: 1,
+		tags:         make(map[int64]*model.Tag),
+		nextTagID:    1,
+		todoTags:     make(map[int64]map[int64]bool),
+		items:        make(map[int64]*model.TodoItem),
+		nextItemID:   1,
+		lists:        make(map[int64]*model.List),
+		nextListID:   1,
+		users:        make(map[int64]*model.User),
+		nextUserID:   1,
+	}}
+}
+
+// ForOwner 는 같은 저장소에서 ownerID 사용자의 todo 만 다루는 저장소를 반환한다.
+func (r *MemoryTodoRepository) ForOwner(ownerID int64) TodoStore {
+	return &MemoryTodoRepository{memoryData: r.memoryData, ownerID: ownerID}
+}
+
+// ListsForOwner 는 같은 저장소에서 ownerID 사용자의 list 만 다루는 저장소를 반환한다.
+func (r *MemoryTodoRepository) ListsForOwner(ownerID int64) ListStore {
+	return &MemoryTodoRepository{memoryData: r.memoryData, ownerID: ownerID}
+}
+
+// TagsForOwner 는 같은 저장소에서 ownerID 사용자의 태그만 다루는 저장소를 반환한다.
+func (r *MemoryTodoRepository) TagsForOwner(ownerID int64) TagStore {
+	return &MemoryTodoRepository{memoryData: r.memoryData, ownerID: ownerID}
+}
+
+func (r *MemoryTodoRepository) Create(todo *model.Todo, events ...string) (*model.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(todo, events)
+}
+
+func (r *MemoryTodoRepository) GetAll(query model.TodoListQuery) (*model.TodoPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.getAll(query)
+}
+
+func (r *MemoryTodoRepository) GetTodo(id int) (*model.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.getTodo(id)
+}
+
+func (r *MemoryTodoRepository) GetTrashedTodo(id int) (*model.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.getTrashedTodo(id)
+}
+
+func (r *MemoryTodoRepository) Update(todo *model.Todo, events ...string) (*model.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.update(todo, events)
+}
+
+func (r *MemoryTodoRepository) Delete(id int, version int64, events ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.delete(id, version, events)
+}
+
+func (r *MemoryTodoRepository) Move(id int, version int64, anchorID int, after bool, events ...string) (*model.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.move(id, version, anchorID, after, events)
+}
+
+func (r *MemoryTodoRepository) Restore(id int, version int64, events ...string) (*model.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.restoreTodo(id, version, events)
+}
+
+func (r *MemoryTodoRepository) AttachTag(id int, version int64, tagID int, events ...string) (*model.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.changeTag(id, version, tagID, true, events)
+}
+
+func (r *MemoryTodoRepository) DetachTag(id int, version int64, tagID int, events ...string) (*model.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.changeTag(id, version, tagID, false, events)
+}
+
+func (r *MemoryTodoRepository) GetItems(todoID int) ([]*model.TodoItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.getItems(todoID)
+}
+
+func (r *MemoryTodoRepository) GetItem(todoID, itemID int) (*model.TodoItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.getItem(todoID, itemID)
+}
+
+func (r *MemoryTodoRepository) CreateItem(item *model.TodoItem) (*model.TodoItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.createItem(item)
+}
+
+func (r *MemoryTodoRepository) UpdateItem(item *model.TodoItem) (*model.TodoItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateItem(item)
+}
+
+func (r *MemoryTodoRepository) DeleteItem(todoID, itemID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deleteItem(todoID, itemID)
+}
+
+func (r *MemoryTodoRepository) MoveItem(todoID, itemID, anchorID int, after bool) (*model.TodoItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.moveItem(todoID, itemID, anchorID, after)
+}
+
+func (r *MemoryTodoRepository) Purge(before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trashed := make([]*model.Todo, 0)
+	for _, stored := range r.todos {
+		if stored.DeletedAt != nil && stored.DeletedAt.Before(before) {
+			trashed = append(trashed, stored)
+		}
+	}
+	// MySQL 구현과 동일하게 오래된 순으로 limit 건
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.Before(*trashed[j].DeletedAt)
+	})
+	if len(trashed) > limit {
+		trashed = trashed[:limit]
+	}
+
+	for _, todo := range trashed {
+		delete(r.todos, todo.ID)
+		delete(r.todoTags, todo.ID)
+		for _, item := range r.itemsOf(todo.ID) {
+			delete(r.items, item.ID)
+		}
+	}
+	return int64(len(trashed)), nil
+}
+
+func (r *MemoryTodoRepository) ClaimDueReminders(now time.Time, limit int, events ...string) ([]*model.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := make([]*model.Todo, 0)
+	for _, stored := range r.todos {
+		if stored.RemindedAt != nil || stored.RemindAt == nil || stored.RemindAt.After(now) {
+			continue
+		}
+		if stored.Completed || stored.DeletedAt != nil {
+			continue
+		}
+		due = append(due, stored)
+	}
+	// MySQL 구현과 동일하게 (remind_at, id) 순으로 limit 건
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].RemindAt.Equal(*due[j].RemindAt) {
+			return due[i].RemindAt.Before(*due[j].RemindAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	todos := make([]*model.Todo, 0, len(due))
+	for _, stored := range due {
+		reminded := *stored
+		reminded.RemindedAt = &now
+		reminded.Version++
+		r.todos[reminded.ID] = &reminded
+		r.appendEvents(&reminded, events)
+
+		todo := reminded
+		r.fillDetails(&todo)
+		todos = append(todos, &todo)
+	}
+	return todos, nil
+}
+
+// Transaction 은 fn 이 끝날 때까지 저장소 전체를 잠그고, fn 이 에러를 반환하면 호출 전 상태로 되돌린다.
+func (r *MemoryTodoRepository) Transaction(fn func(store TodoStore) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return memoryTodoTx{r}.Transaction(fn)
+}
+
+// 아래 메서드는 호출자가 mu 를 잡은 상태에서 호출한다.
+
+func (r *MemoryTodoRepository) create(todo *model.Todo, events []string) (*model.Todo, error) {
+	if !r.listExists(todo.ListID) {
+		return nil, ErrListNotFound
+	}
+	if _, err := priorityRank(todo); err != nil {
+		return nil, err
+	}
+
+	last, _ := r.adjacentPosition("", false, 0)
+	key, err := position.Between(last, "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	todo.UserID = r.owner()
+	todo.Position = key
+	todo.Version = 1
+	todo.CreatedAt = now
+	todo.UpdatedAt = now
+	todo.ID = r.nextID
+	r.nextID++
+
+	tagIDs := make(map[int64]bool, len(todo.Tags))
+	for _, tag := range todo.Tags {
+		if _, ok := r.storedTag(tag.ID); ok {
+			tagIDs[tag.ID] = true
+		}
+	}
+	if len(tagIDs) > 0 {
+		r.todoTags[todo.ID] = tagIDs
+	}
+
+	stored := *todo
+	stored.Tags = nil
+	stored.Progress = nil
+	r.todos[todo.ID] = &stored
+	r.appendEvents(&stored, events)
+
+	*todo = stored
+	r.fillDetails(todo)
+	return todo, nil
+}
+
+func (r *MemoryTodoRepository) getAll(query model.TodoListQuery) (*model.TodoPage, error) {
+	if _, ok := sortColumns[query.Sort]; !ok {
+		return nil, fmt.Errorf("unsupported sort: %q", query.Sort)
+	}
+
+	c, err := decodeCursor(query)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := search.Terms(query.Q)
+	if len(terms) == 0 && query.Sort == model.SortRelevance {
+		return nil, fmt.Errorf("sort %q requires a search query", query.Sort)
+	}
+
+	todos := make([]*model.Todo, 0, len(r.todos))
+	for _, stored := range r.todos {
+		if (stored.DeletedAt != nil) != query.Trashed || !r.owns(stored) {
+			continue
+		}
+		if query.Completed != nil && stored.Completed != *query.Completed {
+			continue
+		}
+		if query.Overdue != nil && isOverdue(stored, query.Now) != *query.Overdue {
+			continue
+		}
+		if query.DueBefore != nil && (stored.DueAt == nil || !stored.DueAt.Before(*query.DueBefore)) {
+			continue
+		}
+		if query.DueAfter != nil && (stored.DueAt == nil || !stored.DueAt.After(*query.DueAfter)) {
+			continue
+		}
+		if len(terms) > 0 && !search.Match(terms, stored.Title, stored.Description) {
+			continue
+		}
+		if query.ListID != nil && (stored.ListID == nil || *stored.ListID != *query.ListID) {
+			continue
+		}
+		if len(query.Tags) > 0 && !r.matchTags(stored.ID, query.Tags, query.TagMatch == model.TagMatchAll) {
+			continue
+		}
+		todo := *stored
+		r.fillDetails(&todo)
+		if len(terms) > 0 {
+			todo.Match = &model.TodoMatch{Score: search.Score(terms, stored.Title, stored.Description)}
+		}
+		if c != nil && !c.after(&todo) {
+			continue
+		}
+		todos = append(todos, &todo)
+	}
+
+	// MySQL 구현과 동일하게 (sort 컬럼, id) 순 정렬
+	sort.Slice(todos, func(i, j int) bool {
+		cmp := compareTodos(query.Sort, todos[i], todos[j])
+		if query.Order == model.OrderAsc {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+
+	if len(todos) > query.Limit+1 {
+		todos = todos[:query.Limit+1]
+	}
+
+	return newTodoPage(query, todos), nil
+}
+
+// isOverdue 는 MySQL 구현의 overdue 조건과 같다: 미완료이고 마감이 now 이전
+func isOverdue(todo *model.Todo, now time.Time) bool {
+	return !todo.Completed && todo.DueAt != nil && todo.DueAt.Before(now)
+}
+
+// owner 는 새 todo (또는 list, 태그) 의 소유자. 범위가 정해지지 않은 저장소면 nil 이다.
+func (r *MemoryTodoRepository) owner() *int64 {
+	if r.ownerID == 0 {
+		return nil
+	}
+	ownerID := r.ownerID
+	return &ownerID
+}
+
+// owns 는 MySQL 구현의 소유자 조건과 같이 범위가 정해진 저장소면 todo 가 소유자의 것인지 확인한다.
+func (r *MemoryTodoRepository) owns(todo *model.Todo) bool {
+	return r.ownedBy(todo.UserID)
+}
+
+// ownedBy 는 범위가 정해진 저장소면 userID 가 소유자인지 확인한다.
+func (r *MemoryTodoRepository) ownedBy(userID *int64) bool {
+	return r.ownerID == 0 || (userID != nil && *userID == r.ownerID)
+}
+
+// storedTodo 는 저장소가 다룰 수 있는 todo 중 휴지통 여부가 trashed 와 같은 저장된 todo 를 찾는다. 반환된 todo 를 수정하면 안 된다.
+func (r *MemoryTodoRepository) storedTodo(id int64, trashed bool) (*model.Todo, bool) {
+	stored, ok := r.todos[id]
+	if !ok || (stored.DeletedAt != nil) != trashed || !r.owns(stored) {
+		return nil, false
+	}
+	return stored, true
+}
+
+func (r *MemoryTodoRepository) getTodo(id int) (*model.Todo, error) {
+	stored, ok := r.storedTodo(int64(id), false)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	todo := *stored
+	r.fillDetails(&todo)
+	return &todo, nil
+}
+
+func (r *MemoryTodoRepository) getTrashedTodo(id int) (*model.Todo, error) {
+	stored, ok := r.storedTodo(int64(id), true)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	todo := *stored
+	r.fillDetails(&todo)
+	return &todo, nil
+}
+
+func (r *MemoryTodoRepository) update(todo *model.Todo, events []string) (*model.Todo, error) {
+	stored, ok := r.storedTodo(todo.ID, false)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	if stored.Version != todo.Version {
+		return nil, ErrVersionConflict
+	}
+	if !r.listExists(todo.ListID) {
+		return nil, ErrListNotFound
+	}
+	if _, err := priorityRank(todo); err != nil {
+		return nil, err
+	}
+
+	todo.Version++
+	todo.UserID = stored.UserID
+	todo.CreatedAt = stored.CreatedAt
+	todo.UpdatedAt = time.Now()
+	r.fillDetails(todo)
+
+	updated := *todo
+	updated.Tags, updated.Progress = nil, nil
+	r.todos[todo.ID] = &updated
+	r.appendEvents(&updated, events)
+	return todo, nil
+}
+
+func (r *MemoryTodoRepository) delete(id int, version int64, events []string) error {
+	stored, ok := r.storedTodo(int64(id), false)
+	if !ok {
+		return ErrTodoNotFound
+	}
+	if stored.Version != version {
+		return ErrVersionConflict
+	}
+
+	now := time.Now()
+	trashed := *stored
+	trashed.DeletedAt = &now
+	trashed.Version++
+	trashed.UpdatedAt = now
+	r.todos[trashed.ID] = &trashed
+	r.appendEvents(&trashed, events)
+	return nil
+}
+
+func (r *MemoryTodoRepository) restoreTodo(id int, version int64, events []string) (*model.Todo, error) {
+	stored, ok := r.storedTodo(int64(id), true)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	if stored.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	restored := *stored
+	restored.DeletedAt = nil
+	restored.Version++
+	restored.UpdatedAt = time.Now()
+	r.todos[restored.ID] = &restored
+	r.appendEvents(&restored, events)
+
+	todo := restored
+	r.fillDetails(&todo)
+	return &todo, nil
+}
+
+func (r *MemoryTodoRepository) move(id int, version int64, anchorID int, after bool, events []string) (*model.Todo, error) {
+	stored, ok := r.storedTodo(int64(id), false)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	if stored.Version != version {
+		return nil, ErrVersionConflict
+	}
+	anchor, ok := r.storedTodo(int64(anchorID), false)
+	if !ok {
+		return nil, ErrAnchorNotFound
+	}
+
+	adjacent, ok := r.adjacentPosition(anchor.Position, after, stored.ID)
+	lo, hi := anchor.Position, ""
+	if ok {
+		hi = adjacent
+	}
+	if !after {
+		lo, hi = hi, anchor.Position
+	}
+	key, err := position.Between(lo, hi)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := *stored
+	moved.Position = key
+	moved.Version++
+	moved.UpdatedAt = time.Now()
+	r.todos[moved.ID] = &moved
+	r.appendEvents(&moved, events)
+
+	todo := moved
+	r.fillDetails(&todo)
+	return &todo, nil
+}
+
+// adjacentPosition 은 MySQL 구현과 같이 휴지통을 포함한 저장소의 todo 중 key 바로 뒤(after) 또는 바로 앞의 키를 찾는다.
+// key 가 빈 문자열이고 after 가 false 면 마지막 키를 찾는다.
+func (r *MemoryTodoRepository) adjacentPosition(key string, after bool, excludeID int64) (string, bool) {
+	var (
+		adjacent string
+		found    bool
+	)
+	for _, stored := range r.todos {
+		if stored.ID == excludeID || !r.owns(stored) {
+			continue
+		}
+		if after {
+			if stored.Position > key && (!found || stored.Position < adjacent) {
+				adjacent, found = stored.Position, true
+			}
+		} else if key == "" || stored.Position < key {
+			if !found || stored.Position > adjacent {
+				adjacent, found = stored.Position, true
+			}
+		}
+	}
+	return adjacent, found
+}
+
+func (r *MemoryTodoRepository) changeTag(id int, version int64, tagID int, attach bool, events []string) (*model.Todo, error) {
+	stored, ok := r.storedTodo(int64(id), false)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	if stored.Version != version {
+		return nil, ErrVersionConflict
+	}
+	if _, ok := r.storedTag(int64(tagID)); !ok {
+		return nil, ErrTagNotFound
+	}
+
+	todo := *stored
+	if r.todoTags[todo.ID][int64(tagID)] == attach {
+		r.fillDetails(&todo)
+		return &todo, nil
+	}
+
+	// 스냅샷과 공유하지 않도록 태그 집합을 복사해서 바꾼다.
+	tagIDs := make(map[int64]bool, len(r.todoTags[todo.ID])+1)
+	for existing := range r.todoTags[todo.ID] {
+		tagIDs[existing] = true
+	}
+	if attach {
+		tagIDs[int64(tagID)] = true
+	} else {
+		delete(tagIDs, int64(tagID))
+	}
+	r.todoTags[todo.ID] = tagIDs
+
+	todo.Version++
+	todo.UpdatedAt = time.Now()
+	changed := todo
+	r.todos[changed.ID] = &changed
+	r.appendEvents(&changed, events)
+
+	r.fillDetails(&todo)
+	return &todo, nil
+}
+
+// fillDetails 는 저장된 todo 의 복사본에 태그와 progress 를 채운다.
+func (r *MemoryTodoRepository) fillDetails(todo *model.Todo) {
+	todo.Tags = r.tagsOf(todo.ID)
+	todo.Progress = model.ProgressOf(r.itemsOf(todo.ID))
+}
+
+// tagsOf 는 todo 에 붙은 태그를 MySQL 구현과 같이 이름 순(대소문자 구분 없음)으로 반환한다.
+func (r *MemoryTodoRepository) tagsOf(todoID int64) []model.Tag {
+	tags := make([]model.Tag, 0, len(r.todoTags[todoID]))
+	for tagID := range r.todoTags[todoID] {
+		tags = append(tags, *r.tags[tagID])
+	}
+	sortTags(tags)
+	return tags
+}
+
+func sortTags(tags []model.Tag) {
+	sort.Slice(tags, func(i, j int) bool {
+		a, b := strings.ToLower(tags[i].Name), strings.ToLower(tags[j].Name)
+		if a != b {
+			return a < b
+		}
+		return tags[i].ID < tags[j].ID
+	})
+}
+
+// matchTags 는 todo 에 names 중 하나라도(matchAll 이면 모두) 붙어 있는지 대소문자 구분 없이 확인한다.
+func (r *MemoryTodoRepository) matchTags(todoID int64, names []string, matchAll bool) bool {
+	matched := 0
+	for _, name := range names {
+		for tagID := range r.todoTags[todoID] {
+			if strings.EqualFold(r.tags[tagID].Name, name) {
+				matched++
+				break
+			}
+		}
+	}
+	if matchAll {
+		return matched == len(names)
+	}
+	return matched > 0
+}
+
+func (r *MemoryTodoRepository) GetTags() ([]*model.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make([]model.Tag, 0, len(r.tags))
+	for _, stored := range r.tags {
+		if r.ownedBy(stored.UserID) {
+			tags = append(tags, *stored)
+		}
+	}
+	sortTags(tags)
+
+	result := make([]*model.Tag, len(tags))
+	for i := range tags {
+		result[i] = &tags[i]
+	}
+	return result, nil
+}
+
+func (r *MemoryTodoRepository) GetTag(id int) (*model.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.storedTag(int64(id))
+	if !ok {
+		return nil, ErrTagNotFound
+	}
+	tag := *stored
+	return &tag, nil
+}
+
+func (r *MemoryTodoRepository) CreateTag(tag *model.Tag) (*model.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tagNameTaken(tag.Name, 0) {
+		return nil, ErrDuplicateTag
+	}
+
+	tag.ID = r.nextTagID
+	tag.UserID = r.owner()
+	tag.CreatedAt = time.Now()
+	r.nextTagID++
+
+	stored := *tag
+	r.tags[tag.ID] = &stored
+	return tag, nil
+}
+
+func (r *MemoryTodoRepository) RenameTag(id int, name string) (*model.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.storedTag(int64(id))
+	if !ok {
+		return nil, ErrTagNotFound
+	}
+	if stored.Name == name {
+		tag := *stored
+		return &tag, nil
+	}
+	if r.tagNameTaken(name, stored.ID) {
+		return nil, ErrDuplicateTag
+	}
+
+	renamed := *stored
+	renamed.Name = name
+	r.tags[renamed.ID] = &renamed
+	r.touchTaggedTodos(renamed.ID)
+
+	tag := renamed
+	return &tag, nil
+}
+
+func (r *MemoryTodoRepository) DeleteTag(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.storedTag(int64(id)); !ok {
+		return ErrTagNotFound
+	}
+
+	r.touchTaggedTodos(int64(id))
+	for todoID, tagIDs := range r.todoTags {
+		if tagIDs[int64(id)] {
+			remaining := make(map[int64]bool, len(tagIDs))
+			for tagID := range tagIDs {
+				if tagID != int64(id) {
+					remaining[tagID] = true
+				}
+			}
+			r.todoTags[todoID] = remaining
+		}
+	}
+	delete(r.tags, int64(id))
+	return nil
+}
+
+// tagNameTaken 은 MySQL 의 (user_id, name) UNIQUE 인덱스와 같이 excludeID 외의 같은 소유자의 태그가
+// 대소문자 구분 없이 같은 이름인지 확인한다.
+func (r *MemoryTodoRepository) tagNameTaken(name string, excludeID int64) bool {
+	owner := r.owner()
+	for _, stored := range r.tags {
+		if stored.ID != excludeID && sameUser(stored.UserID, owner) && strings.EqualFold(stored.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameUser 는 두 소유자가 같은지 확인한다. 둘 다 nil 이면 같은 것으로 본다.
+func sameUser(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// storedTag 는 저장소가 다룰 수 있는 저장된 태그를 찾는다. 반환된 태그를 수정하면 안 된다.
+func (r *MemoryTodoRepository) storedTag(id int64) (*model.Tag, bool) {
+	stored, ok := r.tags[id]
+	if !ok || !r.ownedBy(stored.UserID) {
+		return nil, false
+	}
+	return stored, true
+}
+
+// touchTaggedTodos 는 MySQL 구현과 같이 태그가 붙은 todo 의 version 만 올린다.
+func (r *MemoryTodoRepository) touchTaggedTodos(tagID int64) {
+	for todoID, tagIDs := range r.todoTags {
+		if !tagIDs[tagID] {
+			continue
+		}
+		touched := *r.todos[todoID]
+		touched.Version++
+		r.todos[todoID] = &touched
+	}
+}
+
+// itemsOf 는 todo 의 저장된 item 을 position 순으로 반환한다. 반환된 item 을 수정하면 안 된다.
+func (r *MemoryTodoRepository) itemsOf(todoID int64) []*model.TodoItem {
+	items := make([]*model.TodoItem, 0)
+	for _, stored := range r.items {
+		if stored.TodoID == todoID {
+			items = append(items, stored)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Position < items[j].Position
+	})
+	return items
+}
+
+// checkOwner 는 MySQL 구현과 같이 범위가 정해진 저장소면 todoID 의 todo 가 소유자의 것인지 확인한다.
+func (r *MemoryTodoRepository) checkOwner(todoID int) error {
+	if r.ownerID == 0 {
+		return nil
+	}
+	if stored, ok := r.todos[int64(todoID)]; !ok || !r.owns(stored) {
+		return ErrTodoNotFound
+	}
+	return nil
+}
+
+func (r *MemoryTodoRepository) getItems(todoID int) ([]*model.TodoItem, error) {
+	if err := r.checkOwner(todoID); err != nil {
+		return nil, err
+	}
+	return copyItems(r.itemsOf(int64(todoID))), nil
+}
+
+func copyItems(stored []*model.TodoItem) []*model.TodoItem {
+	items := make([]*model.TodoItem, len(stored))
+	for i, item := range stored {
+		copied := *item
+		items[i] = &copied
+	}
+	return items
+}
+
+func (r *MemoryTodoRepository) getItem(todoID, itemID int) (*model.TodoItem, error) {
+	if err := r.checkOwner(todoID); err != nil {
+		return nil, err
+	}
+	stored, ok := r.items[int64(itemID)]
+	if !ok || stored.TodoID != int64(todoID) {
+		return nil, ErrItemNotFound
+	}
+	item := *stored
+	return &item, nil
+}
+
+func (r *MemoryTodoRepository) createItem(item *model.TodoItem) (*model.TodoItem, error) {
+	if err := r.checkOwner(int(item.TodoID)); err != nil {
+		return nil, err
+	}
+	last := ""
+	if items := r.itemsOf(item.TodoID); len(items) > 0 {
+		last = items[len(items)-1].Position
+	}
+	key, err := position.Between(last, "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	item.ID = r.nextItemID
+	item.Position = key
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	r.nextItemID++
+
+	stored := *item
+	r.items[item.ID] = &stored
+	return item, nil
+}
+
+func (r *MemoryTodoRepository) updateItem(item *model.TodoItem) (*model.TodoItem, error) {
+	stored, err := r.getItem(int(item.TodoID), int(item.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	stored.Title = item.Title
+	stored.Completed = item.Completed
+	stored.UpdatedAt = time.Now()
+	r.items[stored.ID] = stored
+
+	updated := *stored
+	return &updated, nil
+}
+
+func (r *MemoryTodoRepository) deleteItem(todoID, itemID int) error {
+	if _, err := r.getItem(todoID, itemID); err != nil {
+		return err
+	}
+	delete(r.items, int64(itemID))
+	return nil
+}
+
+func (r *MemoryTodoRepository) moveItem(todoID, itemID, anchorID int, after bool) (*model.TodoItem, error) {
+	moved, err := r.getItem(todoID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := r.getItem(todoID, anchorID)
+	if err != nil {
+		return nil, ErrAnchorNotFound
+	}
+
+	// MySQL 구현과 같이 옮기려는 item 을 제외하고 기준 item 의 바로 뒤(앞) 키를 찾는다.
+	adjacent := ""
+	for _, stored := range r.itemsOf(anchor.TodoID) {
+		if stored.ID == moved.ID {
+			continue
+		}
+		if after {
+			if stored.Position > anchor.Position && (adjacent == "" || stored.Position < adjacent) {
+				adjacent = stored.Position
+			}
+		} else if stored.Position < anchor.Position && stored.Position > adjacent {
+			adjacent = stored.Position
+		}
+	}
+	lo, hi := anchor.Position, adjacent
+	if !after {
+		lo, hi = adjacent, anchor.Position
+	}
+	key, err := position.Between(lo, hi)
+	if err != nil {
+		return nil, err
+	}
+
+	moved.Position = key
+	moved.UpdatedAt = time.Now()
+	r.items[moved.ID] = moved
+
+	item := *moved
+	return &item, nil
+}
+
+func (r *MemoryTodoRepository) GetLists() ([]*model.List, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lists := make([]*model.List, 0, len(r.lists))
+	for _, stored := range r.lists {
+		if !r.ownedBy(stored.UserID) {
+			continue
+		}
+		list := *stored
+		lists = append(lists, &list)
+	}
+	// MySQL 구현과 같이 만든 순서로
+	sort.Slice(lists, func(i, j int) bool {
+		if !lists[i].CreatedAt.Equal(lists[j].CreatedAt) {
+			return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+		}
+		return lists[i].ID < lists[j].ID
+	})
+	return lists, nil
+}
+
+func (r *MemoryTodoRepository) GetList(id int) (*model.List, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.storedList(int64(id))
+	if !ok {
+		return nil, ErrListNotFound
+	}
+	list := *stored
+	return &list, nil
+}
+
+func (r *MemoryTodoRepository) CreateList(list *model.List) (*model.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	list.ID = r.nextListID
+	list.UserID = r.owner()
+	list.CreatedAt = now
+	list.UpdatedAt = now
+	r.nextListID++
+
+	stored := *list
+	r.lists[list.ID] = &stored
+	return list, nil
+}
+
+func (r *MemoryTodoRepository) RenameList(id int, name string) (*model.List, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.storedList(int64(id))
+	if !ok {
+		return nil, ErrListNotFound
+	}
+
+	renamed := *stored
+	renamed.Name = name
+	renamed.UpdatedAt = time.Now()
+	r.lists[renamed.ID] = &renamed
+
+	list := renamed
+	return &list, nil
+}
+
+func (r *MemoryTodoRepository) DeleteList(id int, purge bool, events ...string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.storedList(int64(id)); !ok {
+		return 0, ErrListNotFound
+	}
+
+	// MySQL 구현과 같이 id 순으로 처리한다.
+	ids := make([]int64, 0)
+	for todoID, stored := range r.todos {
+		if stored.ListID != nil && *stored.ListID == int64(id) {
+			ids = append(ids, todoID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	now := time.Now()
+	var affected int64
+	for _, todoID := range ids {
+		stored := *r.todos[todoID]
+		active := stored.DeletedAt == nil
+		switch {
+		case !r.owns(&stored):
+			// 다른 사용자의 todo 는 외래 키의 ON DELETE SET NULL 과 같이 list 에서만 빠진다.
+			stored.ListID = nil
+			r.todos[todoID] = &stored
+			continue
+		case active:
+			stored.DeletedAt = &now
+			stored.Version++
+			stored.UpdatedAt = now
+			if !purge {
+				stored.ListID = nil
+			}
+			r.appendEvents(&stored, events)
+			affected++
+		case !purge:
+			// 외래 키의 ON DELETE SET NULL 과 같이 휴지통의 todo 는 list 없이 남는다.
+			stored.ListID = nil
+		}
+
+		if purge {
+			delete(r.todos, todoID)
+			delete(r.todoTags, todoID)
+			for _, item := range r.itemsOf(todoID) {
+				delete(r.items, item.ID)
+			}
+			continue
+		}
+		r.todos[todoID] = &stored
+	}
+
+	delete(r.lists, int64(id))
+	return affected, nil
+}
+
+func (r *MemoryTodoRepository) CreateUser(user *model.User) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// MySQL 의 UNIQUE 인덱스와 같이 이메일은 유일하다.
+	for _, stored := range r.users {
+		if stored.Email == user.Email {
+			return nil, ErrDuplicateEmail
+		}
+	}
+
+	user.ID = r.nextUserID
+	user.CreatedAt = time.Now()
+	r.nextUserID++
+
+	stored := *user
+	r.users[user.ID] = &stored
+	return user, nil
+}
+
+func (r *MemoryTodoRepository) GetUserByEmail(email string) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, stored := range r.users {
+		if stored.Email == email {
+			user := *stored
+			return &user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// listExists 는 MySQL 구현과 같이 listID 가 nil 이거나 저장소가 다룰 수 있는 list 를 가리키는지 확인한다.
+func (r *MemoryTodoRepository) listExists(listID *int64) bool {
+	if listID == nil {
+		return true
+	}
+	_, ok := r.storedList(*listID)
+	return ok
+}
+
+// storedList 는 저장소가 다룰 수 있는 저장된 list 를 찾는다. 반환된 list 를 수정하면 안 된다.
+func (r *MemoryTodoRepository) storedList(id int64) (*model.List, bool) {
+	stored, ok := r.lists[id]
+	if !ok || !r.ownedBy(stored.UserID) {
+		return nil, false
+	}
+	return stored, true
+}
+
+// memoryState 는 Transaction 롤백을 위한 저장소 상태 스냅샷
+type memoryState struct {
+	todos        map[int64]*model.Todo
+	nextID       int64
+	outbox       []*model.OutboxEvent
+	nextOutboxID int64
+	tags         map[int64]*model.Tag
+	nextTagID    int64
+	todoTags     map[int64]map[int64]bool
+	items        map[int64]*model.TodoItem
+	nextItemID   int64
+	lists        map[int64]*model.List
+	nextListID   int64
+}
+
+func (r *MemoryTodoRepository) snapshot() memoryState {
+	todos := make(map[int64]*model.Todo, len(r.todos))
+	for id, todo := range r.todos {
+		todos[id] = todo
+	}
+	outbox := make([]*model.OutboxEvent, len(r.outbox))
+	for i, event := range r.outbox {
+		copied := *event
+		outbox[i] = &copied
+	}
+	tags := make(map[int64]*model.Tag, len(r.tags))
+	for id, tag := range r.tags {
+		tags[id] = tag
+	}
+	todoTags := make(map[int64]map[int64]bool, len(r.todoTags))
+	for todoID, tagIDs := range r.todoTags {
+		copied := make(map[int64]bool, len(tagIDs))
+		for tagID := range tagIDs {
+			copied[tagID] = true
+		}
+		todoTags[todoID] = copied
+	}
+	items := make(map[int64]*model.TodoItem, len(r.items))
+	for id, item := range r.items {
+		items[id] = item
+	}
+	lists := make(map[int64]*model.List, len(r.lists))
+	for id, list := range r.lists {
+		lists[id] = list
+	}
+	return memoryState{
+		todos:        todos,
+		nextID:       r.nextID,
+		outbox:       outbox,
+		nextOutboxID: r.nextOutboxID,
+		tags:         tags,
+		nextTagID:    r.nextTagID,
+		todoTags:     todoTags,
+		items:        items,
+		nextItemID:   r.nextItemID,
+		lists:        lists,
+		nextListID:   r.nextListID,
+	}
+}
+
+func (r *MemoryTodoRepository) restore(state memoryState) {
+	r.todos = state.todos
+	r.nextID = state.nextID
+	r.outbox = state.outbox
+	r.nextOutboxID = state.nextOutboxID
+	r.tags = state.tags
+	r.nextTagID = state.nextTagID
+	r.todoTags = state.todoTags
+	r.items = state.items
+	r.nextItemID = state.nextItemID
+	r.lists = state.lists
+	r.nextListID = state.nextListID
+}
+
+// memoryTodoTx 는 Transaction 안에서 fn 에 전달되는 저장소. mu 는 바깥 Transaction 이 이미 잡고 있다.
+type memoryTodoTx struct {
+	r *MemoryTodoRepository
+}
+
+func (tx memoryTodoTx) Create(todo *model.Todo, events ...string) (*model.Todo, error) {
+	return tx.r.create(todo, events)
+}
+
+func (tx memoryTodoTx) GetAll(query model.TodoListQuery) (*model.TodoPage, error) {
+	return tx.r.getAll(query)
+}
+
+func (tx memoryTodoTx) GetTodo(id int) (*model.Todo, error) {
+	return tx.r.getTodo(id)
+}
+
+func (tx memoryTodoTx) GetTrashedTodo(id int) (*model.Todo, error) {
+	return tx.r.getTrashedTodo(id)
+}
+
+func (tx memoryTodoTx) Update(todo *model.Todo, events ...string) (*model.Todo, error) {
+	return tx.r.update(todo, events)
+}
+
+func (tx memoryTodoTx) Delete(id int, version int64, events ...string) error {
+	return tx.r.delete(id, version, events)
+}
+
+func (tx memoryTodoTx) Move(id int, version int64, anchorID int, after bool, events ...string) (*model.Todo, error) {
+	return tx.r.move(id, version, anchorID, after, events)
+}
+
+func (tx memoryTodoTx) Restore(id int, version int64, events ...string) (*model.Todo, error) {
+	return tx.r.restoreTodo(id, version, events)
+}
+
+func (tx memoryTodoTx) AttachTag(id int, version int64, tagID int, events ...string) (*model.Todo, error) {
+	return tx.r.changeTag(id, version, tagID, true, events)
+}
+
+func (tx memoryTodoTx) DetachTag(id int, version int64, tagID int, events ...string) (*model.Todo, error) {
+	return tx.r.changeTag(id, version, tagID, false, events)
+}
+
+func (tx memoryTodoTx) GetItems(todoID int) ([]*model.TodoItem, error) {
+	return tx.r.getItems(todoID)
+}
+
+func (tx memoryTodoTx) GetItem(todoID, itemID int) (*model.TodoItem, error) {
+	return tx.r.getItem(todoID, itemID)
+}
+
+func (tx memoryTodoTx) CreateItem(item *model.TodoItem) (*model.TodoItem, error) {
+	return tx.r.createItem(item)
+}
+
+func (tx memoryTodoTx) UpdateItem(item *model.TodoItem) (*model.TodoItem, error) {
+	return tx.r.updateItem(item)
+}
+
+func (tx memoryTodoTx) DeleteItem(todoID, itemID int) error {
+	return tx.r.deleteItem(todoID, itemID)
+}
+
+func (tx memoryTodoTx) MoveItem(todoID, itemID, anchorID int, after bool) (*model.TodoItem, error) {
+	return tx.r.moveItem(todoID, itemID, anchorID, after)
+}
+
+func (tx memoryTodoTx) ForOwner(ownerID int64) TodoStore {
+	return memoryTodoTx{&MemoryTodoRepository{memoryData: tx.r.memoryData, ownerID: ownerID}}
+}
+
+// Transaction 은 중첩 호출이므로 에러 시 이 호출 안의 변경만 되돌린다. (MySQL 의 SAVEPOINT 와 같다)
+func (tx memoryTodoTx) Transaction(fn func(store TodoStore) error) error {
+	state := tx.r.snapshot()
+	if err := fn(tx); err != nil {
+		tx.r.restore(state)
+		return err
+	}
+	return nil
+}
+
+// appendEvents 는 호출자가 mu 를 잡은 상태에서 outbox 이벤트를 추가한다.
+func (r *MemoryTodoRepository) appendEvents(todo *model.Todo, events []string) {
+	if len(events) == 0 {
+		return
+	}
+
+	// 저장된 todo 는 Tags, Progress 를 갖지 않으므로 MySQL 구현과 같이 채운 표현을 기록한다.
+	detailed := *todo
+	r.fillDetails(&detailed)
+	payload, _ := json.Marshal(&detailed)
+	now := time.Now()
+	for _, eventType := range events {
+		r.outbox = append(r.outbox, &model.OutboxEvent{
+			ID:          r.nextOutboxID,
+			EventType:   eventType,
+			TodoID:      todo.ID,
+			Payload:     payload,
+			CreatedAt:   now,
+			AvailableAt: now,
+		})
+		r.nextOutboxID++
+	}
+}
+
+func (r *MemoryTodoRepository) ClaimPending(limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var events []*model.OutboxEvent
+	for _, stored := range r.outbox {
+		if len(events) == limit {
+			break
+		}
+		if stored.DispatchedAt != nil || stored.AvailableAt.After(now) {
+			continue
+		}
+		stored.AvailableAt = now.Add(lease)
+		event := *stored
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+func (r *MemoryTodoRepository) MarkDispatched(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.outbox {
+		if stored.ID == id {
+			now := time.Now()
+			stored.DispatchedAt = &now
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *MemoryTodoRepository) MarkFailed(id int64, reason string, retryAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.outbox {
+		if stored.ID == id {
+			stored.Attempts++
+			stored.LastError = reason
+			stored.AvailableAt = retryAt
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *MemoryTodoRepository) PurgeDispatched(before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// MySQL 구현과 같이 발행된 순으로 limit 건
+	dispatched := make([]*model.OutboxEvent, 0)
+	for _, stored := range r.outbox {
+		if stored.DispatchedAt != nil && stored.DispatchedAt.Before(before) {
+			dispatched = append(dispatched, stored)
+		}
+	}
+	sort.Slice(dispatched, func(i, j int) bool {
+		return dispatched[i].DispatchedAt.Before(*dispatched[j].DispatchedAt)
+	})
+	if len(dispatched) > limit {
+		dispatched = dispatched[:limit]
+	}
+
+	purged := make(map[int64]bool, len(dispatched))
+	for _, event := range dispatched {
+		purged[event.ID] = true
+	}
+	remaining := make([]*model.OutboxEvent, 0, len(r.outbox)-len(purged))
+	for _, stored := range r.outbox {
+		if !purged[stored.ID] {
+			remaining = append(remaining, stored)
+		}
+	}
+	r.outbox = remaining
+	return int64(len(purged)), nil
+}
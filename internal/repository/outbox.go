@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"integration-test-example/internal/model"
+	"strings"
+	"time"
+)
+
+// OutboxStore 는 outbox 에 쌓인 이벤트를 relay 가 가져가고 결과를 기록하는 계약이다.
+type OutboxStore interface {
+	// ClaimPending 은 발행 대기 중인 이벤트를 최대 limit 건 가져오고,
+	// lease 동안 다른 relay 가 같은 이벤트를 가져가지 못하게 한다.
+	ClaimPending(limit int, lease time.Duration) ([]*model.OutboxEvent, error)
+	MarkDispatched(id int64) error
+	MarkFailed(id int64, reason string, retryAt time.Time) error
+	// PurgeDispatched 는 before 이전에 발행된 이벤트를 최대 limit 건 삭제하고 삭제된 건수를 반환한다.
+	// 발행되지 않은 이벤트는 삭제하지 않는다.
+	PurgeDispatched(before time.Time, limit int) (int64, error)
+}
+
+var (
+	_ OutboxStore = (*TodoRepository)(nil)
+	_ OutboxStore = (*MemoryTodoRepository)(nil)
+)
+
+// insertEvents 는 todo 변경과 같은 트랜잭션 안에서 outbox 행을 기록한다.
+func insertEvents(tx *sql.Tx, todo *model.Todo, events []string) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(todo)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO outbox (event_type, todo_id, payload, created_at, available_at)
+		VALUES (?, ?, ?, ?, ?)`
+
+	now := time.Now()
+	for _, eventType := range events {
+		if _, err := tx.Exec(query, eventType, todo.ID, payload, now, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r TodoRepository) ClaimPending(limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	// SKIP LOCKED: 여러 인스턴스의 relay 가 동시에 돌아도 같은 행을 두 번 가져가지 않는다.
+	query := `
+		SELECT id, event_type, todo_id, payload, attempts, created_at, available_at
+		FROM outbox
+		WHERE dispatched_at IS NULL AND available_at <= ?
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.Query(query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*model.OutboxEvent
+	for rows.Next() {
+		event := &model.OutboxEvent{}
+		err := rows.Scan(
+			&event.ID,
+			&event.EventType,
+			&event.TodoID,
+			&event.Payload,
+			&event.Attempts,
+			&event.CreatedAt,
+			&event.AvailableAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(events))
+	args := []interface{}{now.Add(lease)}
+	for i, event := range events {
+		placeholders[i] = "?"
+		args = append(args, event.ID)
+	}
+
+	leaseQuery := `UPDATE outbox SET available_at = ? WHERE id IN (` + strings.Join(placeholders, ", ") + `)`
+	if _, err := tx.Exec(leaseQuery, args...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r TodoRepository) MarkDispatched(id int64) error {
+	query := `UPDATE outbox SET dispatched_at = ? WHERE id = ?`
+
+	_, err := r.db.Exec(query, time.Now(), id)
+	return err
+}
+
+func (r TodoRepository) MarkFailed(id int64, reason string, retryAt time.Time) error {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = ?, available_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.Exec(query, reason, retryAt, id)
+	return err
+}
+
+func (r TodoRepository) PurgeDispatched(before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM outbox
+		WHERE dispatched_at IS NOT NULL AND dispatched_at < ?
+		ORDER BY dispatched_at
+		LIMIT ?
+	`
+
+	result, err := r.db.Exec(query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
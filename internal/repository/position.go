@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/position"
+	"strings"
+	"time"
+)
+
+// lastPosition 은 저장소가 다루는 todo 의 수동 정렬 순서에서 마지막 키를 반환한다. todo 가 없으면 빈 문자열이다.
+// 동시에 생성된 todo 가 같은 키를 받지 않도록 마지막 행을 잠근다. 범위가 정해진 저장소면 소유자의 행만 잠근다.
+func (r TodoRepository) lastPosition(tx *sql.Tx) (string, error) {
+	conditions, args := r.ownerFilter(nil, nil)
+	query := `SELECT position FROM todos`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var last string
+	err := tx.QueryRow(query+" ORDER BY position DESC LIMIT 1 FOR UPDATE", args...).Scan(&last)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return last, err
+}
+
+// adjacentPosition 은 저장소가 다루는 todo 중 key 바로 뒤(after) 또는 바로 앞의 키를 반환한다. 없으면 빈 문자열이다.
+// 휴지통의 todo 도 키를 차지하므로 함께 고려하고, 옮기려는 todo(excludeID) 는 제외한다.
+func (r TodoRepository) adjacentPosition(tx *sql.Tx, key string, after bool, excludeID int64) (string, error) {
+	comparison, order := "position < ?", "position DESC"
+	if after {
+		comparison, order = "position > ?", "position"
+	}
+	conditions, args := r.ownerFilter([]string{comparison, "id <> ?"}, []interface{}{key, excludeID})
+	query := `SELECT position FROM todos WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY ` + order + ` LIMIT 1 FOR UPDATE`
+
+	var adjacent string
+	err := tx.QueryRow(query, args...).Scan(&adjacent)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return adjacent, err
+}
+
+// Move 는 todo 를 anchorID todo 의 바로 뒤(after) 또는 바로 앞으로 옮기고, 같은 트랜잭션 안에서 events 를 outbox 에 기록한다.
+// 옮겨지는 todo 의 position 만 바뀌며, 저장된 version 이 version 과 다르면 ErrVersionConflict 를 반환한다.
+func (r TodoRepository) Move(id int, version int64, anchorID int, after bool, events ...string) (*model.Todo, error) {
+	query := `UPDATE todos SET position = ?, version = version + 1, updated_at = ? WHERE id = ?`
+
+	var moved *model.Todo
+	err := r.inTx(func(tx *sql.Tx) error {
+		todo, err := r.getTodo(tx, id, true)
+		if err != nil {
+			return err
+		}
+		if todo.Version != version {
+			return ErrVersionConflict
+		}
+
+		anchor, err := r.getTodo(tx, anchorID, true)
+		if errors.Is(err, ErrTodoNotFound) {
+			return ErrAnchorNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		adjacent, err := r.adjacentPosition(tx, anchor.Position, after, todo.ID)
+		if err != nil {
+			return err
+		}
+		lo, hi := anchor.Position, adjacent
+		if !after {
+			lo, hi = adjacent, anchor.Position
+		}
+		if todo.Position, err = position.Between(lo, hi); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if _, err := tx.Exec(query, todo.Position, now, id); err != nil {
+			return err
+		}
+
+		todo.Version++
+		todo.UpdatedAt = now
+		if err := insertEvents(tx, todo, events); err != nil {
+			return err
+		}
+		moved = todo
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
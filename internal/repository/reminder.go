@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"integration-test-example/internal/model"
+	"time"
+)
+
+// ReminderStore 는 알림 시각이 지난 todo 를 scheduler 가 가져가 알림 이벤트를 기록하는 계약이다.
+type ReminderStore interface {
+	// ClaimDueReminders 는 remind_at 이 now 이하이고 아직 알림이 발송되지 않은 미완료 todo 를 최대 limit 건 가져와
+	// 발송 시각(reminded_at)을 기록하고, 같은 트랜잭션 안에서 events 를 outbox 에 기록한다.
+	// 알림 발송도 todo 의 변경이므로 version 이 1 증가한다.
+	ClaimDueReminders(now time.Time, limit int, events ...string) ([]*model.Todo, error)
+}
+
+var (
+	_ ReminderStore = (*TodoRepository)(nil)
+	_ ReminderStore = (*MemoryTodoRepository)(nil)
+)
+
+func (r *TodoRepository) ClaimDueReminders(now time.Time, limit int, events ...string) ([]*model.Todo, error) {
+	// 여러 scheduler 가 동시에 실행되어도 같은 todo 를 두 번 가져가지 않도록 SKIP LOCKED
+	selectQuery := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE reminded_at IS NULL AND remind_at <= ? AND completed = FALSE AND deleted_at IS NULL
+		ORDER BY remind_at, id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`
+	// 사용자의 수정이 아니므로 updated_at 은 그대로 둔다. (ON UPDATE CURRENT_TIMESTAMP 방지)
+	updateQuery := `UPDATE todos SET reminded_at = ?, version = version + 1, updated_at = updated_at WHERE id = ?`
+
+	var todos []*model.Todo
+	err := r.inTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query(selectQuery, now, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			todo, err := scanTodo(rows)
+			if err != nil {
+				return err
+			}
+			todos = append(todos, todo)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		// 같은 연결에서 UPDATE 를 실행하기 전에 결과를 모두 읽고 닫는다.
+		rows.Close()
+		if err := loadDetails(tx, todos...); err != nil {
+			return err
+		}
+
+		for _, todo := range todos {
+			if _, err := tx.Exec(updateQuery, now, todo.ID); err != nil {
+				return err
+			}
+			todo.RemindedAt = &now
+			todo.Version++
+			if err := insertEvents(tx, todo, events); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
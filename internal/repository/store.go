@@ -0,0 +1,55 @@
+package repository
+
+import "integration-test-example/internal/model"
+
+// TodoStore 는 TodoService 가 의존하는 저장소 계약이다. TodoRepository (MySQL) 와 MemoryTodoRepository 가 구현한다.
+//
+// 변경 메서드의 events 는 todo 변경과 원자적으로 outbox 에 기록된다.
+// Update / Delete / Restore / Move / AttachTag / DetachTag 는 저장된 version 이 주어진 version 과 다르면 ErrVersionConflict 를 반환한다.
+//
+// Delete 는 todo 를 휴지통으로 옮기며(soft delete), 휴지통의 todo 는 GetAll / GetTodo / Update 에서 없는 것으로 취급된다.
+// 휴지통은 GetAll 의 query.Trashed 와 GetTrashedTodo 로 조회하고 Restore 로 되돌린다.
+//
+// ForOwner 로 범위가 정해진 저장소는 모든 메서드에서 소유자의 todo 와 그 item, list, 태그만 다루며, 다른 사용자의 것은 없는 것으로 취급한다.
+// 범위가 정해지지 않은 저장소는 소유자와 관계없이 모든 todo 를 다룬다.
+type TodoStore interface {
+	// ForOwner 는 ownerID 사용자의 todo 만 다루는 저장소를 반환한다. 이 저장소로 만든 todo 는 ownerID 사용자의 것이 된다.
+	ForOwner(ownerID int64) TodoStore
+
+	// Create 는 todo.Tags 의 태그(ID 기준)를 함께 붙인다. 그 사이 삭제된 태그는 건너뛴다.
+	// Create / Update 는 todo.ListID 의 list 가 없거나 저장소가 다룰 수 없는 list 면 ErrListNotFound 를 반환한다.
+	Create(todo *model.Todo, events ...string) (*model.Todo, error)
+	GetAll(query model.TodoListQuery) (*model.TodoPage, error)
+	GetTodo(id int) (*model.Todo, error)
+	Update(todo *model.Todo, events ...string) (*model.Todo, error)
+	Delete(id int, version int64, events ...string) error
+	GetTrashedTodo(id int) (*model.Todo, error)
+	Restore(id int, version int64, events ...string) (*model.Todo, error)
+	// Move 는 todo 를 anchorID todo 의 바로 뒤(after) 또는 바로 앞으로 옮긴다. 기준 todo 가 없으면 ErrAnchorNotFound.
+	Move(id int, version int64, anchorID int, after bool, events ...string) (*model.Todo, error)
+	// AttachTag / DetachTag 는 todo 에 태그를 붙이거나 뗀다. 태그가 없으면 ErrTagNotFound.
+	// 이미 붙어 있거나 붙어 있지 않아 바뀌는 것이 없으면 version 을 올리지 않고 events 도 기록하지 않는다.
+	AttachTag(id int, version int64, tagID int, events ...string) (*model.Todo, error)
+	DetachTag(id int, version int64, tagID int, events ...string) (*model.Todo, error)
+
+	// item 은 todo 의 일부이므로 item 변경 메서드는 version 을 올리거나 events 를 기록하지 않는다.
+	// 호출자는 같은 Transaction 안에서 부모 todo 를 Update 해 version 과 progress 를 함께 바꾼다.
+	// todoID 의 item 이 아니면 ErrItemNotFound 를 반환한다.
+	GetItems(todoID int) ([]*model.TodoItem, error)
+	GetItem(todoID, itemID int) (*model.TodoItem, error)
+	CreateItem(item *model.TodoItem) (*model.TodoItem, error)
+	UpdateItem(item *model.TodoItem) (*model.TodoItem, error)
+	DeleteItem(todoID, itemID int) error
+	// MoveItem 은 item 을 같은 todo 의 anchorID item 바로 뒤(after) 또는 바로 앞으로 옮긴다. 기준 item 이 없으면 ErrAnchorNotFound.
+	MoveItem(todoID, itemID, anchorID int, after bool) (*model.TodoItem, error)
+
+	// Transaction 은 fn 에서 store 로 실행한 변경을 하나의 트랜잭션으로 커밋하고, fn 이 에러를 반환하면 모두 롤백한다.
+	// fn 안에서 store.Transaction 을 다시 호출하면 그 안의 변경만 따로 롤백할 수 있다.
+	Transaction(fn func(store TodoStore) error) error
+}
+
+var (
+	_ TodoStore = (*TodoRepository)(nil)
+	_ TodoStore = (*MemoryTodoRepository)(nil)
+	_ TodoStore = memoryTodoTx{}
+)
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"integration-test-example/internal/model"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrDuplicateTag 는 같은 소유자에게 같은 이름(대소문자 구분 없음)의 태그가 이미 있을 때 반환된다.
+	ErrDuplicateTag = errors.New("tag name already exists")
+)
+
+// TagStore 는 태그 자체를 관리하는 계약이다. 태그를 todo 에 붙이고 떼는 것은 todo 의 변경이므로 TodoStore 가 담당한다.
+//
+// 태그 이름은 todo 의 표현에 포함되므로 RenameTag / DeleteTag 는 태그가 붙은 todo 의 version 을 1 올린다.
+// 사용자가 todo 를 수정한 것은 아니므로 updated_at 은 그대로 두고 이벤트도 기록하지 않는다.
+//
+// TagsForOwner 로 범위가 정해진 저장소는 소유자의 태그만 다루며, 다른 사용자의 태그는 없는 것으로 취급한다. (ErrTagNotFound)
+type TagStore interface {
+	// TagsForOwner 는 ownerID 사용자의 태그만 다루는 저장소를 반환한다. 이 저장소로 만든 태그는 ownerID 사용자의 것이 된다.
+	TagsForOwner(ownerID int64) TagStore
+
+	// GetTags 는 모든 태그를 이름 순으로 조회한다.
+	GetTags() ([]*model.Tag, error)
+	GetTag(id int) (*model.Tag, error)
+	CreateTag(tag *model.Tag) (*model.Tag, error)
+	RenameTag(id int, name string) (*model.Tag, error)
+	DeleteTag(id int) error
+}
+
+var (
+	_ TagStore = (*TodoRepository)(nil)
+	_ TagStore = (*MemoryTodoRepository)(nil)
+)
+
+// mysqlDuplicateEntry 는 UNIQUE 인덱스 위반 에러 번호 (ER_DUP_ENTRY)
+const mysqlDuplicateEntry = 1062
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
+
+func (r *TodoRepository) TagsForOwner(ownerID int64) TagStore {
+	return r.withOwner(ownerID)
+}
+
+func (r *TodoRepository) GetTags() ([]*model.Tag, error) {
+	query := `SELECT id, user_id, name, created_at FROM tags`
+	conditions, args := r.ownerFilter(nil, nil)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := r.conn().Query(query+" ORDER BY name, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]*model.Tag, 0)
+	for rows.Next() {
+		tag := &model.Tag{}
+		if err := rows.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *TodoRepository) GetTag(id int) (*model.Tag, error) {
+	return r.getTag(r.conn(), id, false)
+}
+
+// getTag 는 저장소가 다룰 수 있는 태그를 조회한다. 다른 사용자의 태그는 ErrTagNotFound 다.
+func (r TodoRepository) getTag(q queryRower, id int, forUpdate bool) (*model.Tag, error) {
+	conditions, args := r.ownerFilter([]string{"id = ?"}, []interface{}{id})
+	query := `SELECT id, user_id, name, created_at FROM tags WHERE ` + strings.Join(conditions, " AND ")
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	tag := &model.Tag{}
+	err := q.QueryRow(query, args...).Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (r *TodoRepository) CreateTag(tag *model.Tag) (*model.Tag, error) {
+	tag.UserID = r.owner()
+	tag.CreatedAt = time.Now()
+
+	result, err := r.conn().Exec(`INSERT INTO tags (user_id, name, created_at) VALUES (?, ?, ?)`, tag.UserID, tag.Name, tag.CreatedAt)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return nil, ErrDuplicateTag
+		}
+		return nil, err
+	}
+
+	if tag.ID, err = result.LastInsertId(); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (r *TodoRepository) RenameTag(id int, name string) (*model.Tag, error) {
+	var renamed *model.Tag
+	err := r.inTx(func(tx *sql.Tx) error {
+		tag, err := r.getTag(tx, id, true)
+		if err != nil {
+			return err
+		}
+		if tag.Name == name {
+			renamed = tag
+			return nil
+		}
+
+		if _, err := tx.Exec(`UPDATE tags SET name = ? WHERE id = ?`, name, id); err != nil {
+			if isDuplicateEntry(err) {
+				return ErrDuplicateTag
+			}
+			return err
+		}
+		if err := touchTaggedTodos(tx, id); err != nil {
+			return err
+		}
+
+		tag.Name = name
+		renamed = tag
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return renamed, nil
+}
+
+// DeleteTag 는 태그를 삭제한다. todo_tags 의 행은 외래 키로 함께 삭제된다.
+func (r *TodoRepository) DeleteTag(id int) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		if _, err := r.getTag(tx, id, true); err != nil {
+			return err
+		}
+		if err := touchTaggedTodos(tx, id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, id)
+		return err
+	})
+}
+
+// touchTaggedTodos 는 tagID 태그가 붙은 todo 의 version 을 올려 ETag 가 바뀌도록 한다.
+func touchTaggedTodos(tx *sql.Tx, tagID int) error {
+	query := `
+		UPDATE todos
+		JOIN todo_tags ON todo_tags.todo_id = todos.id
+		SET todos.version = todos.version + 1, todos.updated_at = todos.updated_at
+		WHERE todo_tags.tag_id = ?
+	`
+	_, err := tx.Exec(query, tagID)
+	return err
+}
+
+// AttachTag 는 todo 에 태그를 붙이고, 같은 트랜잭션 안에서 events 를 outbox 에 기록한다.
+// 이미 붙어 있으면 todo 를 바꾸지 않고 그대로 반환한다.
+func (r TodoRepository) AttachTag(id int, version int64, tagID int, events ...string) (*model.Todo, error) {
+	return r.changeTag(id, version, tagID, `INSERT IGNORE INTO todo_tags (todo_id, tag_id) VALUES (?, ?)`, events)
+}
+
+// DetachTag 는 todo 에서 태그를 떼고, 같은 트랜잭션 안에서 events 를 outbox 에 기록한다.
+// 붙어 있지 않으면 todo 를 바꾸지 않고 그대로 반환한다.
+func (r TodoRepository) DetachTag(id int, version int64, tagID int, events ...string) (*model.Todo, error) {
+	return r.changeTag(id, version, tagID, `DELETE FROM todo_tags WHERE todo_id = ? AND tag_id = ?`, events)
+}
+
+// changeTag 는 (todo_id, tag_id) 를 인자로 받는 query 로 todo_tags 를 바꾸고, 바뀐 경우에만 version 을 올린다.
+func (r TodoRepository) changeTag(id int, version int64, tagID int, query string, events []string) (*model.Todo, error) {
+	var changed *model.Todo
+	err := r.inTx(func(tx *sql.Tx) error {
+		todo, err := r.getTodo(tx, id, true)
+		if err != nil {
+			return err
+		}
+		if todo.Version != version {
+			return ErrVersionConflict
+		}
+		if _, err := r.getTag(tx, tagID, true); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(query, id, tagID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		changed = todo
+		if rowsAffected == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		if _, err := tx.Exec(`UPDATE todos SET version = version + 1, updated_at = ? WHERE id = ?`, now, id); err != nil {
+			return err
+		}
+		todo.Version++
+		todo.UpdatedAt = now
+		if err := loadDetails(tx, todo); err != nil {
+			return err
+		}
+		return insertEvents(tx, todo, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// attachTags 는 새 todo 에 tags 를 붙인다. 존재하지 않거나 저장소가 다룰 수 없는 태그는 건너뛴다.
+func (r TodoRepository) attachTags(tx *sql.Tx, todoID int64, tags []model.Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(tags))
+	args := []interface{}{todoID}
+	for i, tag := range tags {
+		placeholders[i] = "?"
+		args = append(args, tag.ID)
+	}
+	conditions, args := r.ownerFilter([]string{"id IN (" + strings.Join(placeholders, ", ") + ")"}, args)
+
+	query := `
+		INSERT IGNORE INTO todo_tags (todo_id, tag_id)
+		SELECT ?, id FROM tags WHERE ` + strings.Join(conditions, " AND ")
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// tagCondition 은 이름이 names 중 하나인 태그가 붙은(matchAll 이면 모두 붙은) todo 를 찾는 조건을 만든다.
+// names 는 service 에서 중복이 제거된 상태여야 한다.
+func tagCondition(names []string, matchAll bool) (string, []interface{}) {
+	placeholders := make([]string, len(names))
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		placeholders[i] = "?"
+		args[i] = name
+	}
+
+	condition := `id IN (
+		SELECT todo_tags.todo_id
+		FROM todo_tags
+		JOIN tags ON tags.id = todo_tags.tag_id
+		WHERE tags.name IN (` + strings.Join(placeholders, ", ") + `)`
+	if matchAll {
+		condition += `
+		GROUP BY todo_tags.todo_id
+		HAVING COUNT(DISTINCT todo_tags.tag_id) = ?`
+		args = append(args, len(names))
+	}
+	return condition + ")", args
+}
+
+// loadTags 는 todos 의 태그를 한 번의 쿼리로 읽어 이름 순으로 채운다.
+func loadTags(q dbConn, todos ...*model.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*model.Todo, len(todos))
+	placeholders := make([]string, len(todos))
+	args := make([]interface{}, len(todos))
+	for i, todo := range todos {
+		todo.Tags = []model.Tag{}
+		byID[todo.ID] = todo
+		placeholders[i] = "?"
+		args[i] = todo.ID
+	}
+
+	query := `
+		SELECT todo_tags.todo_id, tags.id, tags.name, tags.created_at
+		FROM todo_tags
+		JOIN tags ON tags.id = todo_tags.tag_id
+		WHERE todo_tags.todo_id IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY tags.name, tags.id
+	`
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID int64
+			tag    model.Tag
+		)
+		if err := rows.Scan(&todoID, &tag.ID, &tag.Name, &tag.CreatedAt); err != nil {
+			return err
+		}
+		if todo, ok := byID[todoID]; ok {
+			todo.Tags = append(todo.Tags, tag)
+		}
+	}
+	return rows.Err()
+}
@@ -3,108 +3,455 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"integration-test-example/internal/model"
+	"integration-test-example/internal/position"
+	"integration-test-example/internal/search"
+	"strings"
 	"time"
 )
 
 type TodoRepository struct {
 	db *sql.DB
+	// tx 는 Transaction 안에서 fn 에 전달된 저장소일 때만 설정된다.
+	tx         *sql.Tx
+	savepoints int
+	// ownerID 는 ForOwner 로 범위가 정해진 저장소일 때만 설정된다.
+	ownerID int64
 }
 
 var (
 	ErrTodoNotFound = errors.New("todo not found")
+	// ErrAnchorNotFound 는 Move 의 기준 todo 가 없을 때 반환된다.
+	ErrAnchorNotFound = errors.New("anchor todo not found")
+	// ErrVersionConflict 는 읽은 뒤 다른 요청이 먼저 todo 를 변경해 version 이 달라졌을 때 반환된다.
+	ErrVersionConflict = errors.New("todo version conflict")
+	// ErrInvalidPriority 는 알 수 없는 우선순위를 저장하려 할 때 반환된다.
+	ErrInvalidPriority = errors.New("invalid priority")
 )
 
+// priorityRank 는 저장할 우선순위의 Rank 를 반환한다. 빈 우선순위는 기본값(normal)으로 저장한다.
+func priorityRank(todo *model.Todo) (int, error) {
+	if todo.Priority == "" {
+		todo.Priority = model.PriorityNormal
+	}
+	rank := todo.Priority.Rank()
+	if rank < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidPriority, todo.Priority)
+	}
+	return rank, nil
+}
+
+// todoColumns 는 todo 조회 시 scanTodo 와 같은 순서로 읽는 컬럼 목록
+const todoColumns = "id, user_id, title, description, completed, list_id, auto_complete, priority, position, version, created_at, updated_at, due_at, remind_at, reminded_at, rrule, deleted_at"
+
+// rowScanner 는 *sql.Row 와 *sql.Rows 를 함께 다루기 위한 인터페이스
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTodo 는 todoColumns 를 읽고, 그 뒤에 조회한 컬럼이 있으면 extra 로 읽는다.
+func scanTodo(row rowScanner, extra ...interface{}) (*model.Todo, error) {
+	todo := &model.Todo{}
+	var priority int
+	dest := []interface{}{
+		&todo.ID,
+		&todo.UserID,
+		&todo.Title,
+		&todo.Description,
+		&todo.Completed,
+		&todo.ListID,
+		&todo.AutoComplete,
+		&priority,
+		&todo.Position,
+		&todo.Version,
+		&todo.CreatedAt,
+		&todo.UpdatedAt,
+		&todo.DueAt,
+		&todo.RemindAt,
+		&todo.RemindedAt,
+		&todo.RRule,
+		&todo.DeletedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var ok bool
+	if todo.Priority, ok = model.PriorityFromRank(priority); !ok {
+		return nil, fmt.Errorf("todo %d has unknown priority rank %d", todo.ID, priority)
+	}
+	return todo, nil
+}
+
 func NewTodoRepository(db *sql.DB) *TodoRepository {
 	return &TodoRepository{
 		db: db,
 	}
 }
 
-func (r *TodoRepository) Create(todo *model.Todo) (*model.Todo, error) {
-	query := `INSERT INTO todos (title, description, completed, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)`
+// ForOwner 는 ownerID 사용자의 todo 만 다루는 저장소를 반환한다. 만드는 todo 는 ownerID 사용자의 것이 된다.
+func (r *TodoRepository) ForOwner(ownerID int64) TodoStore {
+	return r.withOwner(ownerID)
+}
+
+// withOwner 는 같은 연결과 트랜잭션에서 ownerID 사용자로 범위가 정해진 저장소를 반환한다.
+func (r *TodoRepository) withOwner(ownerID int64) *TodoRepository {
+	return &TodoRepository{db: r.db, tx: r.tx, savepoints: r.savepoints, ownerID: ownerID}
+}
+
+// ownerFilter 는 범위가 정해진 저장소면 소유자의 todo (또는 list, 태그) 만 남기는 조건을 conditions, args 에 더한다.
+func (r TodoRepository) ownerFilter(conditions []string, args []interface{}) ([]string, []interface{}) {
+	if r.ownerID == 0 {
+		return conditions, args
+	}
+	return append(conditions, "user_id = ?"), append(args, r.ownerID)
+}
+
+// owner 는 새 todo (또는 list, 태그) 의 user_id 로 저장할 값. 범위가 정해지지 않은 저장소면 NULL 이다.
+func (r TodoRepository) owner() *int64 {
+	if r.ownerID == 0 {
+		return nil
+	}
+	return &r.ownerID
+}
+
+// dbConn 은 *sql.DB 와 *sql.Tx 를 함께 다루기 위한 인터페이스
+type dbConn interface {
+	queryRower
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// conn 은 Transaction 안이면 진행 중인 트랜잭션을, 아니면 DB 를 반환한다.
+func (r TodoRepository) conn() dbConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// inTx 는 fn 을 트랜잭션 안에서 실행한다.
+// Transaction 안이면 진행 중인 트랜잭션을 그대로 사용하고, 커밋/롤백은 바깥 Transaction 이 결정한다.
+func (r TodoRepository) inTx(fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Transaction 은 fn 에서 store 로 실행한 변경을 하나의 트랜잭션으로 커밋하고, fn 이 에러를 반환하면 모두 롤백한다.
+// Transaction 안에서 다시 호출하면 SAVEPOINT 로 중첩되어, 에러 시 그 안의 변경만 롤백된다.
+func (r *TodoRepository) Transaction(fn func(store TodoStore) error) error {
+	if r.tx != nil {
+		return r.savepoint(fn)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&TodoRepository{db: r.db, tx: tx, ownerID: r.ownerID}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TodoRepository) savepoint(fn func(store TodoStore) error) error {
+	r.savepoints++
+	name := fmt.Sprintf("sp_%d", r.savepoints)
+
+	if _, err := r.tx.Exec("SAVEPOINT " + name); err != nil {
+		return err
+	}
+	if err := fn(r); err != nil {
+		if _, rollbackErr := r.tx.Exec("ROLLBACK TO SAVEPOINT " + name); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+	_, err := r.tx.Exec("RELEASE SAVEPOINT " + name)
+	return err
+}
+
+// Create 는 todo 를 저장하고, 같은 트랜잭션 안에서 events 를 outbox 에 기록한다. todo 의 소유자는 저장소의 소유자다.
+// todo.Tags 의 태그(ID 기준)를 함께 붙이며, 그 사이 삭제된 태그는 건너뛴다.
+// todo.ListID 의 list 가 없거나 다른 사용자의 list 면 ErrListNotFound 를 반환하고, 다른 사용자의 태그는 건너뛴다.
+func (r *TodoRepository) Create(todo *model.Todo, events ...string) (*model.Todo, error) {
+	query := `INSERT INTO todos (user_id, title, description, completed, list_id, auto_complete, priority, position, version, created_at, updated_at, due_at, remind_at, rrule)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	priority, err := priorityRank(todo)
+	if err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
+	todo.UserID = r.owner()
+	todo.Version = 1
 	todo.CreatedAt = now
 	todo.UpdatedAt = now
 
-	result, err := r.db.Exec(
-		query,
-		todo.Title,
-		todo.Description,
-		todo.Completed,
-		todo.CreatedAt,
-		todo.UpdatedAt,
-	)
+	err = r.inTx(func(tx *sql.Tx) error {
+		if err := r.checkList(tx, todo.ListID); err != nil {
+			return err
+		}
+
+		// 새 todo 는 수동 정렬 순서의 맨 뒤에 둔다.
+		last, err := r.lastPosition(tx)
+		if err != nil {
+			return err
+		}
+		if todo.Position, err = position.Between(last, ""); err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(
+			query,
+			todo.UserID,
+			todo.Title,
+			todo.Description,
+			todo.Completed,
+			todo.ListID,
+			todo.AutoComplete,
+			priority,
+			todo.Position,
+			todo.Version,
+			todo.CreatedAt,
+			todo.UpdatedAt,
+			todo.DueAt,
+			todo.RemindAt,
+			todo.RRule,
+		)
+		if err != nil {
+			return mapListReference(err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		todo.ID = id
+		if err := r.attachTags(tx, todo.ID, todo.Tags); err != nil {
+			return err
+		}
+		if err := loadDetails(tx, todo); err != nil {
+			return err
+		}
 
+		return insertEvents(tx, todo, events)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	id, err := result.LastInsertId()
+	return todo, nil
+}
+
+// sortColumns 는 허용된 정렬 키를 실제 컬럼으로 매핑한다. (SQL 인젝션 방지용 화이트리스트)
+var sortColumns = map[string]string{
+	model.SortCreatedAt: "created_at",
+	model.SortUpdatedAt: "updated_at",
+	model.SortTitle:     "title",
+	model.SortDeletedAt: "deleted_at",
+	model.SortPriority:  "priority",
+	model.SortPosition:  "position",
+	model.SortRelevance: "score",
+}
+
+// matchExpression 은 title, description 의 FULLTEXT 인덱스(ngram parser)로 검색하는 BOOLEAN MODE 검색식
+const matchExpression = "MATCH(title, description) AGAINST (? IN BOOLEAN MODE)"
+
+// GetAll 은 keyset 페이지네이션으로 한 페이지의 todo 를 조회한다.
+// query 는 service 에서 기본값이 채워지고 검증된 상태여야 한다.
+func (r TodoRepository) GetAll(query model.TodoListQuery) (*model.TodoPage, error) {
+	column, ok := sortColumns[query.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort: %q", query.Sort)
+	}
+
+	c, err := decodeCursor(query)
 	if err != nil {
 		return nil, err
 	}
 
-	todo.ID = id
-	return todo, nil
-}
+	direction, comparator := "DESC", "<"
+	if query.Order == model.OrderAsc {
+		direction, comparator = "ASC", ">"
+	}
 
-func (r TodoRepository) GetAll() ([]*model.Todo, error) {
-	query := `
-		SELECT id, title, description, completed, created_at, updated_at
+	var (
+		conditions = []string{"deleted_at IS NULL"}
+		args       []interface{}
+		columns    = todoColumns
+		// columnArgs 는 keyset 조건의 정렬 컬럼이 검색식일 때 바인딩할 인자
+		columnArgs []interface{}
+	)
+	if query.Trashed {
+		conditions[0] = "deleted_at IS NOT NULL"
+	}
+
+	terms := search.Terms(query.Q)
+	if len(terms) > 0 {
+		against := search.BooleanQuery(terms)
+		// SELECT 의 score 는 WHERE 보다 앞에 바인딩된다.
+		columns += ", " + matchExpression + " AS score"
+		args = append(args, against)
+		conditions = append(conditions, matchExpression)
+		args = append(args, against)
+		if query.Sort == model.SortRelevance {
+			column, columnArgs = matchExpression, []interface{}{against}
+		}
+	} else if query.Sort == model.SortRelevance {
+		return nil, fmt.Errorf("sort %q requires a search query", query.Sort)
+	}
+
+	// 검색식의 인자가 SELECT 와 WHERE 에 먼저 바인딩되므로 소유자 조건은 검색 조건 뒤에 둔다.
+	conditions, args = r.ownerFilter(conditions, args)
+	if query.Completed != nil {
+		conditions = append(conditions, "completed = ?")
+		args = append(args, *query.Completed)
+	}
+	if query.Overdue != nil {
+		if *query.Overdue {
+			conditions = append(conditions, "(completed = FALSE AND due_at < ?)")
+		} else {
+			conditions = append(conditions, "NOT (completed = FALSE AND due_at IS NOT NULL AND due_at < ?)")
+		}
+		args = append(args, query.Now)
+	}
+	if query.DueBefore != nil {
+		conditions = append(conditions, "due_at < ?")
+		args = append(args, *query.DueBefore)
+	}
+	if query.DueAfter != nil {
+		conditions = append(conditions, "due_at > ?")
+		args = append(args, *query.DueAfter)
+	}
+	if query.ListID != nil {
+		conditions = append(conditions, "list_id = ?")
+		args = append(args, *query.ListID)
+	}
+	if len(query.Tags) > 0 {
+		condition, tagArgs := tagCondition(query.Tags, query.TagMatch == model.TagMatchAll)
+		conditions = append(conditions, condition)
+		args = append(args, tagArgs...)
+	}
+	if c != nil {
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, comparator))
+		args = append(args, columnArgs...)
+		args = append(args, c.cursorArg())
+		args = append(args, columnArgs...)
+		args = append(args, c.cursorArg(), c.ID)
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	// 다음 페이지 존재 여부 확인을 위해 limit+1 건 조회
+	sqlQuery := fmt.Sprintf(`
+		SELECT %s
 		FROM todos
-		ORDER BY created_at DESC
-	`
+		%s
+		ORDER BY %s %s, id %s
+		LIMIT ?
+	`, columns, where, sortColumns[query.Sort], direction, direction)
+	args = append(args, query.Limit+1)
 
-	rows, err := r.db.Query(query)
+	rows, err := r.conn().Query(sqlQuery, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var todos []*model.Todo
+	todos := make([]*model.Todo, 0, query.Limit+1)
 	for rows.Next() {
-		todo := &model.Todo{}
-		err := rows.Scan(
-			&todo.ID,
-			&todo.Title,
-			&todo.Description,
-			&todo.Completed,
-			&todo.CreatedAt,
-			&todo.UpdatedAt,
+		var (
+			score float64
+			extra []interface{}
 		)
+		if len(terms) > 0 {
+			extra = append(extra, &score)
+		}
+
+		todo, err := scanTodo(rows, extra...)
 		if err != nil {
 			return nil, err
 		}
+		if len(terms) > 0 {
+			todo.Match = &model.TodoMatch{Score: score}
+		}
 		todos = append(todos, todo)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
+	rows.Close()
+
+	// 페이지의 태그와 progress 는 todo 마다가 아니라 한 번의 쿼리로 읽는다.
+	page := newTodoPage(query, todos)
+	if err := loadDetails(r.conn(), page.Todos...); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
 
-	return todos, nil
+// newTodoPage 는 limit+1 건으로 조회된 결과를 잘라 다음 cursor 를 계산한다.
+func newTodoPage(query model.TodoListQuery, todos []*model.Todo) *model.TodoPage {
+	page := &model.TodoPage{Todos: todos}
+	if len(todos) > query.Limit {
+		page.Todos = todos[:query.Limit]
+		page.NextCursor = encodeCursor(query, page.Todos[len(page.Todos)-1])
+	}
+	return page
 }
 
+// GetTodo 는 활성 todo 를 조회한다. 휴지통에 있는 todo 는 ErrTodoNotFound 를 반환한다.
 func (r TodoRepository) GetTodo(id int) (*model.Todo, error) {
-	query := `
-		SELECT id, title, description, completed, created_at, updated_at
-		FROM todos
-		WHERE id = ?
-	`
+	return r.getTodo(r.conn(), id, false)
+}
 
-	todo := &model.Todo{}
-	err := r.db.QueryRow(query, id).Scan(
-		&todo.ID,
-		&todo.Title,
-		&todo.Description,
-		&todo.Completed,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-	)
+// GetTrashedTodo 는 휴지통에 있는 todo 를 조회한다.
+func (r TodoRepository) GetTrashedTodo(id int) (*model.Todo, error) {
+	return r.getTrashedTodo(r.conn(), id, false)
+}
+
+// queryRower 는 *sql.DB 와 *sql.Tx 를 함께 다루기 위한 인터페이스
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func (r TodoRepository) getTodo(q dbConn, id int, forUpdate bool) (*model.Todo, error) {
+	return r.findTodo(q, id, "deleted_at IS NULL", forUpdate)
+}
+
+func (r TodoRepository) getTrashedTodo(q dbConn, id int, forUpdate bool) (*model.Todo, error) {
+	return r.findTodo(q, id, "deleted_at IS NOT NULL", forUpdate)
+}
+
+// findTodo 는 id 와 condition 을 모두 만족하는 todo 를 태그, progress 와 함께 조회한다.
+// 범위가 정해진 저장소면 다른 사용자의 todo 는 없는 것으로 취급한다.
+func (r TodoRepository) findTodo(q dbConn, id int, condition string, forUpdate bool) (*model.Todo, error) {
+	conditions, args := r.ownerFilter([]string{"id = ?", condition}, []interface{}{id})
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE ` + strings.Join(conditions, " AND ")
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
 
+	todo, err := scanTodo(q.QueryRow(query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrTodoNotFound
@@ -112,59 +459,140 @@ func (r TodoRepository) GetTodo(id int) (*model.Todo, error) {
 		return nil, err
 	}
 
+	if err := loadDetails(q, todo); err != nil {
+		return nil, err
+	}
 	return todo, nil
 }
 
-func (r TodoRepository) Update(todo *model.Todo) (*model.Todo, error) {
-	query := `
-		UPDATE todos
-		SET title = ?, description = ?, completed = ?, updated_at = ?
-		WHERE id = ?
-	`
-
-	todo.UpdatedAt = time.Now()
+// Update 는 todo 를 수정하고, 같은 트랜잭션 안에서 events 를 outbox 에 기록한다.
+// todo.Version 이 저장된 version 과 같을 때만 수정하며, 수정되면 version 이 1 증가한다.
+// 휴지통에 있는 todo 는 수정할 수 없다. (ErrTodoNotFound) todo.ListID 의 list 가 없거나 다른 사용자의 list 면 ErrListNotFound 를 반환한다.
+func (r TodoRepository) Update(todo *model.Todo, events ...string) (*model.Todo, error) {
+	priority, err := priorityRank(todo)
+	if err != nil {
+		return nil, err
+	}
 
-	result, err := r.db.Exec(
-		query,
+	updatedAt := time.Now()
+	args := []interface{}{
 		todo.Title,
 		todo.Description,
 		todo.Completed,
-		todo.UpdatedAt,
+		todo.ListID,
+		todo.AutoComplete,
+		priority,
+		todo.DueAt,
+		todo.RemindAt,
+		todo.RemindedAt,
+		todo.RRule,
+		updatedAt,
 		todo.ID,
-	)
-
-	if err != nil {
-		return nil, err
+		todo.Version,
 	}
+	conditions, args := r.ownerFilter([]string{"id = ?", "version = ?", "deleted_at IS NULL"}, args)
+	query := `
+		UPDATE todos
+		SET title = ?, description = ?, completed = ?, list_id = ?, auto_complete = ?, priority = ?, due_at = ?, remind_at = ?, reminded_at = ?, rrule = ?,
+			version = version + 1, updated_at = ?
+		WHERE ` + strings.Join(conditions, " AND ")
+
+	err = r.inTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return mapListReference(err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		// version 이 항상 바뀌므로 0 건이면 행이 없거나 version 이 다른 경우다.
+		if rowsAffected == 0 {
+			if _, err := r.getTodo(tx, int(todo.ID), false); err != nil {
+				return err
+			}
+			return ErrVersionConflict
+		}
+		if err := r.checkList(tx, todo.ListID); err != nil {
+			return err
+		}
 
-	rowsAffected, err := result.RowsAffected()
+		updated := *todo
+		updated.Version++
+		updated.UpdatedAt = updatedAt
+		if err := insertEvents(tx, &updated, events); err != nil {
+			return err
+		}
+		*todo = updated
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return nil, ErrTodoNotFound
-	}
-
 	return todo, nil
 }
 
-func (r TodoRepository) Delete(id int) error {
-	query := `DELETE FROM todos WHERE id = ?`
+// Delete 는 todo 를 휴지통으로 옮기고(soft delete), 같은 트랜잭션 안에서 옮겨진 todo 로 events 를 outbox 에 기록한다.
+// 저장된 version 이 version 과 다르면 ErrVersionConflict 를 반환한다.
+func (r TodoRepository) Delete(id int, version int64, events ...string) error {
+	return r.inTx(func(tx *sql.Tx) error {
+		todo, err := r.getTodo(tx, id, true)
+		if err != nil {
+			return err
+		}
+		if todo.Version != version {
+			return ErrVersionConflict
+		}
 
-	result, err := r.db.Exec(query, id)
-	if err != nil {
-		return err
-	}
+		query := `UPDATE todos SET deleted_at = ?, version = version + 1, updated_at = ? WHERE id = ?`
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+		now := time.Now()
+		if _, err := tx.Exec(query, now, now, id); err != nil {
+			return err
+		}
 
-	if rowsAffected == 0 {
-		return ErrTodoNotFound
+		todo.DeletedAt = &now
+		todo.Version++
+		todo.UpdatedAt = now
+		return insertEvents(tx, todo, events)
+	})
+}
+
+// Restore 는 휴지통의 todo 를 다시 활성 상태로 되돌리고, 같은 트랜잭션 안에서 events 를 outbox 에 기록한다.
+// 저장된 version 이 version 과 다르면 ErrVersionConflict 를 반환한다.
+func (r TodoRepository) Restore(id int, version int64, events ...string) (*model.Todo, error) {
+	var restored *model.Todo
+	err := r.inTx(func(tx *sql.Tx) error {
+		todo, err := r.getTrashedTodo(tx, id, true)
+		if err != nil {
+			return err
+		}
+		if todo.Version != version {
+			return ErrVersionConflict
+		}
+
+		query := `UPDATE todos SET deleted_at = NULL, version = version + 1, updated_at = ? WHERE id = ?`
+
+		now := time.Now()
+		if _, err := tx.Exec(query, now, id); err != nil {
+			return err
+		}
+
+		todo.DeletedAt = nil
+		todo.Version++
+		todo.UpdatedAt = now
+		if err := insertEvents(tx, todo, events); err != nil {
+			return err
+		}
+		restored = todo
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return restored, nil
 }
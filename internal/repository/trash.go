@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"time"
+)
+
+// TrashStore 는 휴지통에 오래 머문 todo 를 purger 가 영구 삭제하는 계약이다.
+type TrashStore interface {
+	// Purge 는 before 이전에 휴지통으로 옮겨진 todo 를 최대 limit 건 영구 삭제하고 삭제된 건수를 반환한다.
+	Purge(before time.Time, limit int) (int64, error)
+}
+
+var (
+	_ TrashStore = (*TodoRepository)(nil)
+	_ TrashStore = (*MemoryTodoRepository)(nil)
+)
+
+func (r *TodoRepository) Purge(before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM todos
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		ORDER BY deleted_at
+		LIMIT ?
+	`
+
+	result, err := r.conn().Exec(query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
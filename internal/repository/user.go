@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"integration-test-example/internal/model"
+	"time"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	// ErrDuplicateEmail 는 같은 이메일의 사용자가 이미 있을 때 반환된다.
+	ErrDuplicateEmail = errors.New("email already registered")
+)
+
+// UserStore 는 todo 의 소유자인 사용자를 관리하는 계약이다.
+// 이메일은 호출자가 소문자로 정규화해서 넘긴다.
+type UserStore interface {
+	CreateUser(user *model.User) (*model.User, error)
+	GetUserByEmail(email string) (*model.User, error)
+}
+
+var (
+	_ UserStore = (*TodoRepository)(nil)
+	_ UserStore = (*MemoryTodoRepository)(nil)
+)
+
+func (r *TodoRepository) CreateUser(user *model.User) (*model.User, error) {
+	user.CreatedAt = time.Now()
+
+	result, err := r.conn().Exec(`INSERT INTO users (email, password_hash, created_at) VALUES (?, ?, ?)`,
+		user.Email, user.PasswordHash, user.CreatedAt)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return nil, ErrDuplicateEmail
+		}
+		return nil, err
+	}
+
+	if user.ID, err = result.LastInsertId(); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *TodoRepository) GetUserByEmail(email string) (*model.User, error) {
+	user := &model.User{}
+	err := r.conn().QueryRow(`SELECT id, email, password_hash, created_at FROM users WHERE email = ?`, email).
+		Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,166 @@
+// Package search 는 todo 전문 검색(q)의 검색어 해석, 점수 계산, 하이라이트를 담당한다.
+//
+// MySQL 저장소는 FULLTEXT 인덱스(ngram parser)로 검색하고, 메모리 저장소는 같은 의미를 Match / Score 로 흉내 낸다.
+// 두 구현 모두 모든 검색어가 title 또는 description 에 부분 문자열로 포함된 todo 만 찾는다.
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// MaxQueryLength 는 검색어 전체의 최대 문자(rune) 수
+const MaxQueryLength = 200
+
+// operators 는 MySQL BOOLEAN MODE 에서 특별한 의미를 갖는 문자. 검색어에서는 구분자로 취급한다.
+const operators = `+-<>()~*"@`
+
+// Terms 는 q 를 공백과 연산자 문자로 나눈 검색어 목록을 반환한다. 중복은 제거된다.
+func Terms(q string) []string {
+	fields := strings.FieldsFunc(q, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(operators, r)
+	})
+
+	terms := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		key := strings.ToLower(field)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+// BooleanQuery 는 terms 를 모두 포함해야 하는 MySQL BOOLEAN MODE 검색식으로 만든다.
+// ngram parser 에서 "..." 구문 검색은 검색어를 연속된 ngram 으로 찾으므로 부분 문자열 검색과 같다.
+func BooleanQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `+"` + term + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Match 는 모든 terms 가 title 또는 description 에 포함되어 있는지 대소문자 구분 없이 확인한다.
+func Match(terms []string, title, description string) bool {
+	title, description = strings.ToLower(title), strings.ToLower(description)
+	for _, term := range terms {
+		term = strings.ToLower(term)
+		if !strings.Contains(title, term) && !strings.Contains(description, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// titleWeight 는 title 에서 찾은 검색어가 description 보다 점수에 더 기여하도록 하는 가중치
+const titleWeight = 2
+
+// Score 는 검색어가 나타난 횟수로 관련도를 계산한다. title 의 일치는 titleWeight 배로 계산한다.
+func Score(terms []string, title, description string) float64 {
+	title, description = strings.ToLower(title), strings.ToLower(description)
+
+	var score float64
+	for _, term := range terms {
+		term = strings.ToLower(term)
+		score += float64(titleWeight*strings.Count(title, term) + strings.Count(description, term))
+	}
+	return score
+}
+
+// Highlight 는 text 에서 terms 와 일치하는 부분을 <mark> 로 감싼 HTML 조각을 반환한다.
+// text 가 width 문자보다 길면 첫 번째 일치 위치 주변 width 문자만 잘라 "…" 를 붙인다.
+// 일치하는 부분이 없으면 false 를 반환한다.
+func Highlight(text string, terms []string, width int) (string, bool) {
+	runes := []rune(text)
+	ranges := matchRanges(runes, terms)
+	if len(ranges) == 0 {
+		return "", false
+	}
+
+	start, end := 0, len(runes)
+	if width > 0 && len(runes) > width {
+		// 첫 번째 일치 앞쪽에 문맥을 조금 남긴다.
+		start = ranges[0][0] - width/4
+		if start < 0 {
+			start = 0
+		}
+		end = start + width
+		if end > len(runes) {
+			end = len(runes)
+			start = end - width
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, r := range ranges {
+		if r[1] <= start || r[0] >= end {
+			continue
+		}
+		from, to := max(r[0], start), min(r[1], end)
+		b.WriteString(html.EscapeString(string(runes[pos:from])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[from:to])))
+		b.WriteString("</mark>")
+		pos = to
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
+
+// matchRanges 는 terms 가 나타나는 [시작, 끝) rune 구간을 겹치지 않게 합쳐 위치 순으로 반환한다.
+func matchRanges(runes []rune, terms []string) [][2]int {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	matched := make([]bool, len(runes))
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if equalRunes(lower[i:i+len(needle)], needle) {
+				for j := i; j < i+len(needle); j++ {
+					matched[j] = true
+				}
+			}
+		}
+	}
+
+	var ranges [][2]int
+	for i := 0; i < len(matched); i++ {
+		if !matched[i] {
+			continue
+		}
+		j := i
+		for j < len(matched) && matched[j] {
+			j++
+		}
+		ranges = append(ranges, [2]int{i, j})
+		i = j
+	}
+	return ranges
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	t.Run("Splits On Spaces And Boolean Operators", func(t *testing.T) {
+		// Act
+		terms := Terms(`  장보기 +"milk"  -eggs Milk (bread)* `)
+
+		// Assert
+		assert.Equal(t, []string{"장보기", "milk", "eggs", "bread"}, terms)
+		assert.Equal(t, `+"장보기" +"milk" +"eggs" +"bread"`, BooleanQuery(terms))
+	})
+
+	t.Run("Operators Only Yield No Terms", func(t *testing.T) {
+		// Act
+		terms := Terms(`+- "" *`)
+
+		// Assert
+		assert.Empty(t, terms)
+	})
+}
+
+func TestMatchAndScore(t *testing.T) {
+	// Act & Assert
+	assert.True(t, Match([]string{"장보"}, "주말 장보기", ""), "korean substrings match without spaces")
+	assert.True(t, Match([]string{"MILK", "store"}, "buy milk", "at the store"), "terms may match different fields")
+	assert.False(t, Match([]string{"milk", "bread"}, "buy milk", ""), "every term must match")
+
+	assert.Greater(t, Score([]string{"milk"}, "milk", ""), Score([]string{"milk"}, "", "milk"),
+		"title matches weigh more than description matches")
+}
+
+func TestHighlight(t *testing.T) {
+	t.Run("Marks Every Match And Escapes Html", func(t *testing.T) {
+		// Act
+		snippet, ok := Highlight("Buy <b>milk</b> & MILK tea", []string{"milk"}, 0)
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, "Buy &lt;b&gt;<mark>milk</mark>&lt;/b&gt; &amp; <mark>MILK</mark> tea", snippet)
+	})
+
+	t.Run("Merges Overlapping Terms", func(t *testing.T) {
+		// Act
+		snippet, _ := Highlight("주말 장보기 목록", []string{"장보", "보기"}, 0)
+
+		// Assert
+		assert.Equal(t, "주말 <mark>장보기</mark> 목록", snippet)
+	})
+
+	t.Run("Long Text Is Cut Around First Match", func(t *testing.T) {
+		// Arrange
+		text := strings.Repeat("가", 100) + "우유" + strings.Repeat("나", 100)
+
+		// Act
+		snippet, ok := Highlight(text, []string{"우유"}, 20)
+
+		// Assert
+		assert.True(t, ok)
+		assert.Equal(t, "…"+strings.Repeat("가", 5)+"<mark>우유</mark>"+strings.Repeat("나", 13)+"…", snippet)
+	})
+
+	t.Run("No Match", func(t *testing.T) {
+		// Act
+		_, ok := Highlight("buy bread", []string{"milk"}, 0)
+
+		// Assert
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+)
+
+// DefaultMaxBatchSize 는 BatchConfig.MaxSize 가 설정되지 않았을 때의 최대 operation 수
+const DefaultMaxBatchSize = 100
+
+var (
+	ErrInvalidBatch  = errors.New("invalid batch")
+	ErrBatchTooLarge = errors.New("batch too large")
+)
+
+// BatchConfig 는 config.json 의 batch 설정
+type BatchConfig struct {
+	// MaxSize 는 한 요청에 담을 수 있는 최대 operation 수 (기본 100)
+	MaxSize int `json:"max_size"`
+}
+
+// BatchResult 는 operation 하나의 결과. 실패했으면 Err 가 설정된다.
+type BatchResult struct {
+	Op   string
+	Todo *model.Todo
+	Err  error
+}
+
+// BatchError 는 atomic batch 에서 처음 실패한 operation 과 그 에러
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigureBatch 는 batch 설정을 적용한다.
+func (s *TodoService) ConfigureBatch(config BatchConfig) {
+	s.maxBatchSize = config.MaxSize
+	if s.maxBatchSize <= 0 {
+		s.maxBatchSize = DefaultMaxBatchSize
+	}
+}
+
+// BatchTodos 는 operations 를 순서대로 하나의 트랜잭션 안에서 실행한다.
+//
+// atomic 모드에서는 하나라도 실패하면 전체를 롤백하고 *BatchError 를 반환한다.
+// independent 모드에서는 operation 마다 SAVEPOINT 를 두어 실패한 것만 롤백하고, 결과는 항목별로 반환한다.
+func (s *TodoService) BatchTodos(mode string, operations []model.BatchTodoOperation) ([]BatchResult, error) {
+	switch mode {
+	case "":
+		mode = model.BatchAtomic
+	case model.BatchAtomic, model.BatchIndependent:
+	default:
+		return nil, fmt.Errorf("%w: unsupported mode %q", ErrInvalidBatch, mode)
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidBatch)
+	}
+	if len(operations) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d operations are allowed", ErrBatchTooLarge, s.maxBatchSize)
+	}
+
+	results := make([]BatchResult, len(operations))
+	err := s.todoRepository.Transaction(func(store repository.TodoStore) error {
+		for i, operation := range operations {
+			results[i].Op = operation.Op
+
+			var todo *model.Todo
+			apply := func(store repository.TodoStore) error {
+				var err error
+				todo, err = applyBatchOperation(store, operation)
+				return err
+			}
+
+			var err error
+			if mode == model.BatchIndependent {
+				err = store.Transaction(apply)
+			} else {
+				err = apply(store)
+			}
+			if err != nil {
+				if mode == model.BatchAtomic {
+					return &BatchError{Index: i, Err: err}
+				}
+				results[i].Err = err
+				continue
+			}
+			results[i].Todo = todo
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func applyBatchOperation(store repository.TodoStore, operation model.BatchTodoOperation) (*model.Todo, error) {
+	var ifMatch Precondition
+	if operation.Version != 0 {
+		ifMatch = func(current *model.Todo) bool {
+			return current.Version == operation.Version
+		}
+	}
+
+	opts := []TodoOption{
+		WithPriority(operation.Priority),
+		WithList(operation.ListID),
+		WithAutoComplete(operation.AutoComplete),
+		WithSchedule(operation.DueAt, operation.RemindAt),
+		WithRecurrence(operation.RRule),
+	}
+
+	switch operation.Op {
+	case model.BatchCreate:
+		return createTodo(store, operation.Title, operation.Description, opts...)
+	case model.BatchUpdate:
+		if operation.ID <= 0 {
+			return nil, fmt.Errorf("%w: update requires an id", ErrInvalidBatch)
+		}
+		return updateTodo(store, int(operation.ID), ifMatch,
+			replaceFields(operation.Title, operation.Description, operation.Completed, opts...))
+	case model.BatchDelete:
+		if operation.ID <= 0 {
+			return nil, fmt.Errorf("%w: delete requires an id", ErrInvalidBatch)
+		}
+		return nil, deleteTodo(store, int(operation.ID), ifMatch)
+	default:
+		return nil, fmt.Errorf("%w: unsupported op %q", ErrInvalidBatch, operation.Op)
+	}
+}
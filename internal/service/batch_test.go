@@ -0,0 +1,117 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"testing"
+	"time"
+)
+
+func TestBatchTodos(t *testing.T) {
+	t.Run("Atomic Batch Applies Mixed Operations", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		existing, err := svc.CreateTodo("existing", "")
+		require.NoError(t, err)
+		removed, err := svc.CreateTodo("removed", "")
+		require.NoError(t, err)
+
+		// Act
+		results, err := svc.BatchTodos(model.BatchAtomic, []model.BatchTodoOperation{
+			{Op: model.BatchCreate, Title: "created", Priority: model.PriorityHigh},
+			{Op: model.BatchUpdate, ID: existing.ID, Version: existing.Version, Title: "updated", Completed: true},
+			{Op: model.BatchDelete, ID: removed.ID},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, "created", results[0].Todo.Title)
+		assert.Equal(t, model.PriorityHigh, results[0].Todo.Priority)
+		assert.Equal(t, "updated", results[1].Todo.Title)
+		assert.True(t, results[1].Todo.Completed)
+		assert.Nil(t, results[2].Todo)
+
+		_, err = svc.GetTodoById(int(removed.ID))
+		assert.ErrorIs(t, err, ErrTodoNotFound)
+	})
+
+	t.Run("Atomic Batch Rolls Back Everything On Failure", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+		existing, err := svc.CreateTodo("existing", "")
+		require.NoError(t, err)
+		_, err = repo.ClaimPending(100, time.Minute)
+		require.NoError(t, err)
+
+		// Act
+		_, err = svc.BatchTodos(model.BatchAtomic, []model.BatchTodoOperation{
+			{Op: model.BatchCreate, Title: "created"},
+			{Op: model.BatchUpdate, ID: existing.ID, Title: "updated"},
+			{Op: model.BatchDelete, ID: 999},
+		})
+
+		// Assert
+		var batchErr *BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 2, batchErr.Index)
+		assert.ErrorIs(t, err, ErrTodoNotFound)
+
+		page, err := svc.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Todos, 1)
+		assert.Equal(t, "existing", page.Todos[0].Title)
+
+		events, err := repo.ClaimPending(100, 0)
+		require.NoError(t, err)
+		assert.Empty(t, events, "outbox events of rolled back operations must not be published")
+	})
+
+	t.Run("Independent Batch Reports Per Item Results", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		existing, err := svc.CreateTodo("existing", "")
+		require.NoError(t, err)
+
+		// Act
+		results, err := svc.BatchTodos(model.BatchIndependent, []model.BatchTodoOperation{
+			{Op: model.BatchCreate, Title: " "},
+			{Op: model.BatchUpdate, ID: existing.ID, Version: existing.Version + 1, Title: "stale"},
+			{Op: model.BatchCreate, Title: "created"},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		var validationErrors validation.Errors
+		assert.ErrorAs(t, results[0].Err, &validationErrors)
+		assert.ErrorIs(t, results[1].Err, ErrPreconditionFailed)
+		require.NoError(t, results[2].Err)
+
+		page, err := svc.GetAllTodos(model.TodoListQuery{Sort: model.SortTitle, Order: model.OrderAsc})
+		require.NoError(t, err)
+		require.Len(t, page.Todos, 2)
+		assert.Equal(t, "created", page.Todos[0].Title)
+		assert.Equal(t, "existing", page.Todos[1].Title)
+	})
+
+	t.Run("Batch Size Is Capped", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		svc.ConfigureBatch(BatchConfig{MaxSize: 2})
+		operations := []model.BatchTodoOperation{
+			{Op: model.BatchCreate, Title: "a"},
+			{Op: model.BatchCreate, Title: "b"},
+			{Op: model.BatchCreate, Title: "c"},
+		}
+
+		// Act
+		_, err := svc.BatchTodos(model.BatchAtomic, operations)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrBatchTooLarge)
+	})
+}
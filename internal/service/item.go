@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"strings"
+)
+
+// ErrItemNotFound 는 todo 에 해당 item 이 없을 때 반환된다.
+var ErrItemNotFound = errors.New("todo item not found")
+
+// GetItems 는 todo 의 item 을 순서대로 조회한다.
+func (s TodoService) GetItems(todoID int) ([]*model.TodoItem, error) {
+	if _, err := s.todoRepository.GetTodo(todoID); err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	return s.todoRepository.GetItems(todoID)
+}
+
+// CreateItem 은 todo 의 item 중 맨 뒤에 item 을 추가하고, 바뀐 progress 가 반영된 todo 와 함께 반환한다.
+func (s *TodoService) CreateItem(todoID int, ifMatch Precondition, title string, completed bool) (*model.TodoItem, *model.Todo, error) {
+	item := &model.TodoItem{TodoID: int64(todoID), Title: strings.TrimSpace(title), Completed: completed}
+	if err := validation.Struct(item); err != nil {
+		return nil, nil, err
+	}
+
+	var created *model.TodoItem
+	todo, err := s.changeItems(todoID, ifMatch, func(store repository.TodoStore) error {
+		copied := *item
+		var err error
+		created, err = store.CreateItem(&copied)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, todo, nil
+}
+
+// ReplaceItem 은 item 의 title, completed 를 교체한다. (PUT)
+func (s *TodoService) ReplaceItem(todoID, itemID int, ifMatch Precondition, title string, completed bool) (*model.TodoItem, *model.Todo, error) {
+	item := &model.TodoItem{ID: int64(itemID), TodoID: int64(todoID), Title: strings.TrimSpace(title), Completed: completed}
+	if err := validation.Struct(item); err != nil {
+		return nil, nil, err
+	}
+
+	var replaced *model.TodoItem
+	todo, err := s.changeItems(todoID, ifMatch, func(store repository.TodoStore) error {
+		var err error
+		replaced, err = store.UpdateItem(item)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return replaced, todo, nil
+}
+
+// DeleteItem 은 item 을 삭제하고 바뀐 progress 가 반영된 todo 를 반환한다.
+func (s *TodoService) DeleteItem(todoID, itemID int, ifMatch Precondition) (*model.Todo, error) {
+	return s.changeItems(todoID, ifMatch, func(store repository.TodoStore) error {
+		return store.DeleteItem(todoID, itemID)
+	})
+}
+
+// MoveItem 은 item 을 같은 todo 의 afterID item 바로 뒤, 또는 beforeID item 바로 앞으로 옮긴다. 둘 중 하나만 지정해야 한다.
+func (s *TodoService) MoveItem(todoID, itemID int, ifMatch Precondition, afterID, beforeID int) (*model.TodoItem, *model.Todo, error) {
+	if (afterID == 0) == (beforeID == 0) {
+		return nil, nil, fmt.Errorf("%w: exactly one of after_id and before_id is required", ErrInvalidMove)
+	}
+	anchorID, after := afterID, true
+	if beforeID != 0 {
+		anchorID, after = beforeID, false
+	}
+	if anchorID == itemID {
+		return nil, nil, fmt.Errorf("%w: an item cannot be moved relative to itself", ErrInvalidMove)
+	}
+
+	var moved *model.TodoItem
+	todo, err := s.changeItems(todoID, ifMatch, func(store repository.TodoStore) error {
+		var err error
+		moved, err = store.MoveItem(todoID, itemID, anchorID, after)
+		if errors.Is(err, repository.ErrAnchorNotFound) {
+			return fmt.Errorf("%w: item %d to move relative to does not exist", ErrInvalidMove, anchorID)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return moved, todo, nil
+}
+
+// changeItems 는 같은 트랜잭션 안에서 change 로 item 을 바꾸고 부모 todo 를 수정한다.
+// item 은 todo 의 일부이므로 todo 의 version 이 올라가고 todo_updated 이벤트가 기록된다.
+// todo 가 AutoComplete 이고 이번 변경으로 모든 item 이 완료되면 todo 도 완료되어 todo_completed 이벤트가 함께 기록된다.
+// 반복 todo 면 updateTodo 와 같이 다음 발생 todo 도 만든다.
+// 읽은 뒤 다른 요청이 먼저 todo 를 변경했다면 updateTodo 와 같이 처리한다.
+func (s *TodoService) changeItems(todoID int, ifMatch Precondition, change func(store repository.TodoStore) error) (*model.Todo, error) {
+	for attempt := 1; ; attempt++ {
+		var updatedTodo *model.Todo
+		err := s.todoRepository.Transaction(func(store repository.TodoStore) error {
+			existingTodo, err := store.GetTodo(todoID)
+			if err != nil {
+				return err
+			}
+			if ifMatch != nil && !ifMatch(existingTodo) {
+				return ErrPreconditionFailed
+			}
+
+			if err := change(store); err != nil {
+				return err
+			}
+
+			items, err := store.GetItems(todoID)
+			if err != nil {
+				return err
+			}
+			existingTodo.Progress = model.ProgressOf(items)
+
+			events := []string{model.EventTodoUpdated}
+			if existingTodo.AutoComplete && !existingTodo.Completed && existingTodo.Progress.Done() {
+				existingTodo.Completed = true
+				events = append(events, model.EventTodoCompleted)
+				if existingTodo.RRule != "" {
+					updatedTodo, err = completeOccurrence(store, existingTodo, events)
+					return err
+				}
+			}
+
+			updatedTodo, err = store.Update(existingTodo, events...)
+			return err
+		})
+		if errors.Is(err, repository.ErrVersionConflict) {
+			if ifMatch == nil && attempt < maxUpdateAttempts {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, mapConflictError(mapItemError(err), ifMatch)
+		}
+
+		return updatedTodo, nil
+	}
+}
+
+// mapItemError 는 item 관련 repository 에러를 service 에러로 변환한다.
+func mapItemError(err error) error {
+	if errors.Is(err, repository.ErrItemNotFound) {
+		return ErrItemNotFound
+	}
+	return mapRepositoryError(err)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestTodoItems(t *testing.T) {
+	t.Run("Item Changes Update Progress And Version", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		todo, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		require.Nil(t, todo.Progress, "a todo without items has no progress")
+
+		// Act
+		first, _, err := svc.CreateItem(int(todo.ID), nil, "first", false)
+		require.NoError(t, err)
+		_, _, err = svc.CreateItem(int(todo.ID), nil, "second", true)
+		require.NoError(t, err)
+		_, afterCreate, err := svc.CreateItem(int(todo.ID), nil, "third", false)
+		require.NoError(t, err)
+		_, afterReplace, err := svc.ReplaceItem(int(todo.ID), int(first.ID), nil, "first", true)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, &model.TodoProgress{Completed: 1, Total: 3, Percent: 33}, afterCreate.Progress)
+		assert.Equal(t, todo.Version+3, afterCreate.Version)
+		assert.Equal(t, &model.TodoProgress{Completed: 2, Total: 3, Percent: 66}, afterReplace.Progress)
+		assert.False(t, afterReplace.Completed, "todos do not auto-complete unless asked to")
+
+		stored, err := svc.GetTodoById(int(todo.ID))
+		require.NoError(t, err)
+		assert.Equal(t, afterReplace.Progress, stored.Progress)
+		page, err := svc.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Todos, 1)
+		assert.Equal(t, afterReplace.Progress, page.Todos[0].Progress)
+	})
+
+	t.Run("Auto Complete When Every Item Is Done", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+		todo, err := svc.CreateTodo("dummy title", "", WithAutoComplete(true))
+		require.NoError(t, err)
+		first, _, err := svc.CreateItem(int(todo.ID), nil, "first", false)
+		require.NoError(t, err)
+		second, _, err := svc.CreateItem(int(todo.ID), nil, "second", false)
+		require.NoError(t, err)
+		_, _, err = svc.ReplaceItem(int(todo.ID), int(first.ID), nil, "first", true)
+		require.NoError(t, err)
+		_, err = repo.ClaimPending(100, time.Minute)
+		require.NoError(t, err)
+
+		// Act
+		_, completed, err := svc.ReplaceItem(int(todo.ID), int(second.ID), nil, "second", true)
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, completed.Completed)
+		assert.True(t, completed.Progress.Done())
+		events, err := repo.ClaimPending(100, time.Minute)
+		require.NoError(t, err)
+		var eventTypes []string
+		for _, event := range events {
+			eventTypes = append(eventTypes, event.EventType)
+		}
+		assert.Equal(t, []string{model.EventTodoUpdated, model.EventTodoCompleted}, eventTypes)
+	})
+
+	t.Run("Move And Delete Items", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		todo, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		var items []*model.TodoItem
+		for _, title := range []string{"a", "b", "c"} {
+			item, _, err := svc.CreateItem(int(todo.ID), nil, title, false)
+			require.NoError(t, err)
+			items = append(items, item)
+		}
+		titles := func() []string {
+			items, err := svc.GetItems(int(todo.ID))
+			require.NoError(t, err)
+			var titles []string
+			for _, item := range items {
+				titles = append(titles, item.Title)
+			}
+			return titles
+		}
+
+		// Act
+		_, _, err = svc.MoveItem(int(todo.ID), int(items[2].ID), nil, 0, int(items[0].ID))
+		require.NoError(t, err)
+		_, _, err = svc.MoveItem(int(todo.ID), int(items[0].ID), nil, int(items[1].ID), 0)
+		require.NoError(t, err)
+		afterMove := titles()
+		afterDelete, err := svc.DeleteItem(int(todo.ID), int(items[1].ID), nil)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, []string{"c", "b", "a"}, afterMove)
+		assert.Equal(t, []string{"c", "a"}, titles())
+		assert.Equal(t, 2, afterDelete.Progress.Total)
+	})
+
+	t.Run("Items Belong To Their Todo", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		todo, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		other, err := svc.CreateTodo("other", "")
+		require.NoError(t, err)
+		item, _, err := svc.CreateItem(int(todo.ID), nil, "item", false)
+		require.NoError(t, err)
+		otherItem, _, err := svc.CreateItem(int(other.ID), nil, "other item", false)
+		require.NoError(t, err)
+
+		// Act
+		_, _, replaceErr := svc.ReplaceItem(int(other.ID), int(item.ID), nil, "stolen", true)
+		_, deleteErr := svc.DeleteItem(int(other.ID), int(item.ID), nil)
+		_, _, anchorErr := svc.MoveItem(int(todo.ID), int(item.ID), nil, int(otherItem.ID), 0)
+		_, _, missingErr := svc.CreateItem(999, nil, "item", false)
+
+		// Assert
+		assert.ErrorIs(t, replaceErr, ErrItemNotFound)
+		assert.ErrorIs(t, deleteErr, ErrItemNotFound)
+		assert.ErrorIs(t, anchorErr, ErrInvalidMove)
+		assert.ErrorIs(t, missingErr, ErrTodoNotFound)
+		unchanged, err := svc.GetTodoById(int(other.ID))
+		require.NoError(t, err)
+		assert.Equal(t, other.Version+1, unchanged.Version, "failed item changes roll back")
+	})
+}
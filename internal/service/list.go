@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"strings"
+)
+
+var (
+	ErrListNotFound = errors.New("list not found")
+	// ErrInvalidCascade 는 list 삭제 시 todo 처리 방식(cascade)이 지원되지 않을 때 반환된다.
+	ErrInvalidCascade = errors.New("invalid cascade")
+)
+
+// ListService 는 todo 를 묶는 list 를 관리한다. todo 를 list 에 넣고 빼는 것은 TodoService 가 todo 의 list_id 로 처리한다.
+type ListService struct {
+	listRepository repository.ListStore
+}
+
+func NewListService(repo repository.ListStore) *ListService {
+	return &ListService{listRepository: repo}
+}
+
+// ForUser 는 userID 사용자의 list 만 다루는 ListService 를 반환한다. 만드는 list 는 userID 사용자의 것이 된다.
+func (s ListService) ForUser(userID int64) *ListService {
+	s.listRepository = s.listRepository.ListsForOwner(userID)
+	return &s
+}
+
+func (s ListService) GetLists() ([]*model.List, error) {
+	return s.listRepository.GetLists()
+}
+
+func (s ListService) GetList(id int) (*model.List, error) {
+	list, err := s.listRepository.GetList(id)
+	if err != nil {
+		return nil, mapListError(err)
+	}
+	return list, nil
+}
+
+func (s ListService) CreateList(name string) (*model.List, error) {
+	list := &model.List{Name: strings.TrimSpace(name)}
+	if err := validation.Struct(list); err != nil {
+		return nil, err
+	}
+	return s.listRepository.CreateList(list)
+}
+
+// RenameList 는 list 이름을 바꾼다.
+func (s ListService) RenameList(id int, name string) (*model.List, error) {
+	list := &model.List{ID: int64(id), Name: strings.TrimSpace(name)}
+	if err := validation.Struct(list); err != nil {
+		return nil, err
+	}
+
+	renamed, err := s.listRepository.RenameList(id, list.Name)
+	if err != nil {
+		return nil, mapListError(err)
+	}
+	return renamed, nil
+}
+
+// DeleteList 는 list 를 삭제하고, cascade 에 따라 속한 todo 를 휴지통으로 옮기거나(archive, 기본값) 영구 삭제한다(delete).
+// 어느 경우든 활성 todo 마다 todo_deleted 이벤트가 기록되며, 영향을 받은 활성 todo 수를 반환한다.
+func (s ListService) DeleteList(id int, cascade string) (int64, error) {
+	var purge bool
+	switch cascade {
+	case "", model.ListCascadeArchive:
+	case model.ListCascadeDelete:
+		purge = true
+	default:
+		return 0, fmt.Errorf("%w: unsupported cascade %q", ErrInvalidCascade, cascade)
+	}
+
+	affected, err := s.listRepository.DeleteList(id, purge, model.EventTodoDeleted)
+	if err != nil {
+		return 0, mapListError(err)
+	}
+	return affected, nil
+}
+
+// mapListError 는 list 관련 repository 에러를 service 에러로 변환한다.
+func mapListError(err error) error {
+	if errors.Is(err, repository.ErrListNotFound) {
+		return ErrListNotFound
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"testing"
+	"time"
+)
+
+func TestListService(t *testing.T) {
+	t.Run("Create Rename And Get Lists", func(t *testing.T) {
+		// Arrange
+		svc := NewListService(repository.NewMemoryTodoRepository())
+
+		// Act
+		work, err := svc.CreateList("  work ")
+		require.NoError(t, err)
+		_, err = svc.CreateList("home")
+		require.NoError(t, err)
+		renamed, err := svc.RenameList(int(work.ID), "office")
+		require.NoError(t, err)
+		_, blankErr := svc.CreateList(" ")
+		_, missingErr := svc.RenameList(999, "nothing")
+
+		// Assert
+		assert.Equal(t, "office", renamed.Name)
+		lists, err := svc.GetLists()
+		require.NoError(t, err)
+		require.Len(t, lists, 2)
+		assert.Equal(t, "office", lists[0].Name, "lists are ordered by creation")
+		assert.Equal(t, "home", lists[1].Name)
+		var errs validation.Errors
+		assert.ErrorAs(t, blankErr, &errs)
+		assert.ErrorIs(t, missingErr, ErrListNotFound)
+	})
+
+	t.Run("Todos Belong To Existing Lists", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		lists := NewListService(repo)
+		todos := NewTodoService(repo)
+		list, err := lists.CreateList("work")
+		require.NoError(t, err)
+		missing := int64(999)
+
+		// Act
+		inList, err := todos.CreateTodo("in list", "", WithList(&list.ID))
+		require.NoError(t, err)
+		_, err = todos.CreateTodo("no list", "")
+		require.NoError(t, err)
+		_, missingErr := todos.CreateTodo("missing list", "", WithList(&missing))
+		moved, err := todos.PatchTodo(int(inList.ID), nil, mustMergePatch(t, `{"list_id": null}`))
+		require.NoError(t, err)
+		_, err = todos.PatchTodo(int(inList.ID), nil, mustMergePatch(t, `{"list_id": 1}`))
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, list.ID, *inList.ListID)
+		assert.Nil(t, moved.ListID)
+		var errs validation.Errors
+		require.ErrorAs(t, missingErr, &errs)
+		assert.Equal(t, "list_id", errs[0].Field)
+
+		page, err := todos.GetAllTodos(model.TodoListQuery{ListID: &list.ID})
+		require.NoError(t, err)
+		require.Len(t, page.Todos, 1)
+		assert.Equal(t, "in list", page.Todos[0].Title)
+	})
+
+	for _, tc := range []struct {
+		name    string
+		cascade string
+		purged  bool
+	}{
+		{name: "Delete Archives Todos By Default", cascade: "", purged: false},
+		{name: "Delete Purges Todos", cascade: model.ListCascadeDelete, purged: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			repo := repository.NewMemoryTodoRepository()
+			lists := NewListService(repo)
+			todos := NewTodoService(repo)
+			list, err := lists.CreateList("work")
+			require.NoError(t, err)
+			active, err := todos.CreateTodo("active", "", WithList(&list.ID))
+			require.NoError(t, err)
+			trashed, err := todos.CreateTodo("trashed", "", WithList(&list.ID))
+			require.NoError(t, err)
+			require.NoError(t, todos.DeleteTodo(int(trashed.ID), nil))
+			other, err := todos.CreateTodo("other", "")
+			require.NoError(t, err)
+			_, err = repo.ClaimPending(100, time.Minute)
+			require.NoError(t, err)
+
+			// Act
+			affected, err := lists.DeleteList(int(list.ID), tc.cascade)
+			require.NoError(t, err)
+
+			// Assert
+			assert.Equal(t, int64(1), affected)
+			_, err = lists.GetList(int(list.ID))
+			assert.ErrorIs(t, err, ErrListNotFound)
+			_, err = todos.GetTodoById(int(other.ID))
+			assert.NoError(t, err, "todos in other lists are untouched")
+			_, err = todos.GetTodoById(int(active.ID))
+			assert.ErrorIs(t, err, ErrTodoNotFound)
+
+			events, err := repo.ClaimPending(100, time.Minute)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			assert.Equal(t, model.EventTodoDeleted, events[0].EventType)
+			assert.Equal(t, active.ID, events[0].TodoID)
+
+			trash, err := todos.GetTrash(model.TodoListQuery{})
+			require.NoError(t, err)
+			if tc.purged {
+				assert.Empty(t, trash.Todos)
+			} else {
+				require.Len(t, trash.Todos, 2)
+				for _, todo := range trash.Todos {
+					assert.Nil(t, todo.ListID, "trashed todos leave the deleted list")
+				}
+				restored, err := todos.RestoreTodo(int(active.ID), nil)
+				require.NoError(t, err)
+				assert.Nil(t, restored.ListID)
+			}
+		})
+	}
+
+	t.Run("Lists Are Scoped To Their Owner", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		aliceLists, bobLists := NewListService(repo).ForUser(1), NewListService(repo).ForUser(2)
+		aliceTodos, bobTodos := NewTodoService(repo).ForUser(1), NewTodoService(repo).ForUser(2)
+		list, err := aliceLists.CreateList("work")
+		require.NoError(t, err)
+		_, err = aliceTodos.CreateTodo("alice's todo", "", WithList(&list.ID))
+		require.NoError(t, err)
+		bobTodo, err := bobTodos.CreateTodo("bob's todo", "")
+		require.NoError(t, err)
+
+		// Act
+		_, getErr := bobLists.GetList(int(list.ID))
+		_, renameErr := bobLists.RenameList(int(list.ID), "stolen")
+		_, deleteErr := bobLists.DeleteList(int(list.ID), model.ListCascadeDelete)
+		_, createErr := bobTodos.CreateTodo("into alice's list", "", WithList(&list.ID))
+		_, moveErr := bobTodos.PatchTodo(int(bobTodo.ID), nil, mustMergePatch(t, fmt.Sprintf(`{"list_id": %d}`, list.ID)))
+		bobAll, err := bobLists.GetLists()
+		require.NoError(t, err)
+
+		// Assert
+		assert.ErrorIs(t, getErr, ErrListNotFound)
+		assert.ErrorIs(t, renameErr, ErrListNotFound)
+		assert.ErrorIs(t, deleteErr, ErrListNotFound)
+		var errs validation.Errors
+		require.ErrorAs(t, createErr, &errs)
+		assert.Equal(t, "list_id", errs[0].Field)
+		require.ErrorAs(t, moveErr, &errs)
+		assert.Equal(t, "list_id", errs[0].Field)
+		assert.Empty(t, bobAll)
+
+		stored, err := aliceLists.GetList(int(list.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "work", stored.Name)
+		page, err := aliceTodos.GetAllTodos(model.TodoListQuery{ListID: &list.ID})
+		require.NoError(t, err)
+		assert.Len(t, page.Todos, 1)
+	})
+
+	t.Run("Rejects Unknown Cascade", func(t *testing.T) {
+		// Arrange
+		svc := NewListService(repository.NewMemoryTodoRepository())
+		list, err := svc.CreateList("work")
+		require.NoError(t, err)
+
+		// Act
+		_, err = svc.DeleteList(int(list.ID), "orphan")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidCascade)
+		_, err = svc.GetList(int(list.ID))
+		assert.NoError(t, err)
+	})
+}
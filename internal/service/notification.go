@@ -1,9 +1,11 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
 	"integration-test-example/internal/model"
 	"integration-test-example/pkg/sqs"
+	"log"
 	"time"
 )
 
@@ -17,18 +19,61 @@ func NewNotificationService(sqsClient *sqs.SQSClient) *NotificationService {
 	}
 }
 
-func (n *NotificationService) SendTodoCompletedNotification(todo *model.Todo) error {
-	message := sqs.NotificationMessage{
-		EventType: "todo_completed",
-		TodoID:    todo.ID,
-		Title:     todo.Title,
-		Message:   fmt.Sprintf("🎉 축하합니다! '%s' 할 일을 완료했습니다!", todo.Title),
-		Timestamp: time.Now().Format(time.RFC3339),
+// Publish 는 outbox 이벤트를 NotificationMessage 로 변환해 SQS 로 전송한다.
+func (n *NotificationService) Publish(event *model.OutboxEvent) error {
+	message, err := newNotificationMessage(event)
+	if err != nil {
+		return err
 	}
 
 	if err := n.sqsClient.SendMessage(message); err != nil {
-		return fmt.Errorf("failed to send todo completed notification: %w", err)
+		return fmt.Errorf("failed to send %s notification: %w", event.EventType, err)
+	}
+
+	return nil
+}
+
+func newNotificationMessage(event *model.OutboxEvent) (sqs.NotificationMessage, error) {
+	var todo model.Todo
+	if err := json.Unmarshal(event.Payload, &todo); err != nil {
+		return sqs.NotificationMessage{}, fmt.Errorf("failed to unmarshal outbox payload: %w", err)
 	}
 
+	var text string
+	switch event.EventType {
+	case model.EventTodoCreated:
+		text = fmt.Sprintf("'%s' 할 일이 추가되었습니다.", todo.Title)
+	case model.EventTodoUpdated:
+		text = fmt.Sprintf("'%s' 할 일이 수정되었습니다.", todo.Title)
+	case model.EventTodoCompleted:
+		text = fmt.Sprintf("🎉 축하합니다! '%s' 할 일을 완료했습니다!", todo.Title)
+	case model.EventTodoDeleted:
+		text = fmt.Sprintf("'%s' 할 일이 휴지통으로 이동되었습니다.", todo.Title)
+	case model.EventTodoRestored:
+		text = fmt.Sprintf("'%s' 할 일이 휴지통에서 복원되었습니다.", todo.Title)
+	case model.EventTodoReminder:
+		text = fmt.Sprintf("⏰ '%s' 할 일을 잊지 마세요!", todo.Title)
+		if todo.DueAt != nil {
+			text += fmt.Sprintf(" (마감: %s)", todo.DueAt.Format(time.RFC3339))
+		}
+	default:
+		text = fmt.Sprintf("'%s' 할 일에 %s 이벤트가 발생했습니다.", todo.Title, event.EventType)
+	}
+
+	return sqs.NotificationMessage{
+		EventID:   event.ID,
+		EventType: event.EventType,
+		TodoID:    event.TodoID,
+		Title:     todo.Title,
+		Message:   text,
+		Timestamp: event.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// LogPublisher 는 SQS 가 설정되지 않았을 때 outbox 이벤트를 로그로만 남긴다.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(event *model.OutboxEvent) error {
+	log.Printf("outbox event %d (%s) for todo %d dropped: SQS is not configured", event.ID, event.EventType, event.TodoID)
 	return nil
 }
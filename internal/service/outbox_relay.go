@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"log"
+	"time"
+)
+
+// EventPublisher 는 outbox 이벤트를 외부 브로커로 발행한다.
+type EventPublisher interface {
+	Publish(event *model.OutboxEvent) error
+}
+
+const (
+	defaultRelayInterval  = time.Second
+	defaultRelayBatchSize = 50
+	// 발행된 이벤트는 재처리 확인용으로 retention 동안 보관한 뒤 outboxPurgeInterval 마다 삭제한다.
+	defaultOutboxRetention = 7 * 24 * time.Hour
+	outboxPurgeInterval    = time.Hour
+
+	// relay 가 이벤트를 가져간 뒤 발행 결과를 기록하기까지 허용하는 시간
+	relayLease = 30 * time.Second
+
+	relayMinBackoff = time.Second
+	relayMaxBackoff = 5 * time.Minute
+)
+
+// OutboxRelay 는 outbox 를 주기적으로 비우며 이벤트를 발행한다. (at-least-once)
+// 발행된 이벤트는 retention 이 지나면 outbox 에서 삭제한다.
+type OutboxRelay struct {
+	store     repository.OutboxStore
+	publisher EventPublisher
+	interval  time.Duration
+	batchSize int
+	retention time.Duration
+}
+
+func NewOutboxRelay(store repository.OutboxStore, publisher EventPublisher, interval time.Duration, batchSize int, retention time.Duration) *OutboxRelay {
+	if interval <= 0 {
+		interval = defaultRelayInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultRelayBatchSize
+	}
+	if retention <= 0 {
+		retention = defaultOutboxRetention
+	}
+
+	return &OutboxRelay{
+		store:     store,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: batchSize,
+		retention: retention,
+	}
+}
+
+// Run 은 ctx 가 취소될 때까지 outbox 를 폴링한다.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	var purgedAt time.Time
+	for {
+		if err := r.Drain(); err != nil {
+			log.Printf("outbox relay: %v", err)
+		}
+		if now := time.Now(); now.Sub(purgedAt) >= outboxPurgeInterval {
+			purgedAt = now
+			if purged, err := r.Purge(now); err != nil {
+				log.Printf("outbox relay: %v", err)
+			} else if purged > 0 {
+				log.Printf("outbox relay: purged %d dispatched events", purged)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain 은 현재 발행 가능한 이벤트가 없을 때까지 배치 단위로 발행한다.
+func (r *OutboxRelay) Drain() error {
+	for {
+		events, err := r.store.ClaimPending(r.batchSize, relayLease)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			r.dispatch(event)
+		}
+
+		if len(events) < r.batchSize {
+			return nil
+		}
+	}
+}
+
+// Purge 는 now 기준으로 retention 이 지난 발행된 이벤트가 없을 때까지 배치 단위로 삭제하고 삭제된 건수를 반환한다.
+func (r *OutboxRelay) Purge(now time.Time) (int64, error) {
+	before := now.Add(-r.retention)
+
+	var total int64
+	for {
+		purged, err := r.store.PurgeDispatched(before, r.batchSize)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+		if purged < int64(r.batchSize) {
+			return total, nil
+		}
+	}
+}
+
+func (r *OutboxRelay) dispatch(event *model.OutboxEvent) {
+	if err := r.publisher.Publish(event); err != nil {
+		retryAt := time.Now().Add(relayBackoff(event.Attempts))
+		if markErr := r.store.MarkFailed(event.ID, err.Error(), retryAt); markErr != nil {
+			log.Printf("outbox relay: failed to record failure of event %d: %v", event.ID, markErr)
+		}
+		return
+	}
+
+	// 여기서 실패하면 lease 만료 후 다시 발행된다. (중복 가능)
+	if err := r.store.MarkDispatched(event.ID); err != nil {
+		log.Printf("outbox relay: failed to mark event %d dispatched: %v", event.ID, err)
+	}
+}
+
+// relayBackoff 는 시도 횟수에 따라 지수적으로 늘어나는 재시도 간격을 계산한다.
+func relayBackoff(attempts int) time.Duration {
+	backoff := relayMinBackoff
+	for i := 0; i < attempts && backoff < relayMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > relayMaxBackoff {
+		backoff = relayMaxBackoff
+	}
+	return backoff
+}
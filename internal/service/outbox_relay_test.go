@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"testing"
+	"time"
+)
+
+// fakePublisher: 발행된 이벤트를 기록하고, failures 만큼 먼저 실패하는 테스트 더블
+type fakePublisher struct {
+	failures  int
+	published []string
+}
+
+func (f *fakePublisher) Publish(event *model.OutboxEvent) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("sqs unavailable")
+	}
+	f.published = append(f.published, event.EventType)
+	return nil
+}
+
+func TestOutboxRelay(t *testing.T) {
+	t.Run("Drain Publishes Pending Events Once", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+		created, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteTodo(int(created.ID), nil))
+		publisher := &fakePublisher{}
+		relay := NewOutboxRelay(repo, publisher, time.Second, 1, time.Hour)
+
+		// Act
+		require.NoError(t, relay.Drain())
+		require.NoError(t, relay.Drain())
+
+		// Assert
+		assert.Equal(t, []string{model.EventTodoCreated, model.EventTodoDeleted}, publisher.published)
+	})
+
+	t.Run("Failed Event Is Retried After Backoff", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		_, err := NewTodoService(repo).CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		publisher := &fakePublisher{failures: 1}
+		relay := NewOutboxRelay(repo, publisher, time.Second, 10, time.Hour)
+
+		// Act
+		require.NoError(t, relay.Drain())
+
+		// Assert: 실패한 이벤트는 backoff 이후에만 다시 가져갈 수 있다.
+		assert.Empty(t, publisher.published)
+		events, err := repo.ClaimPending(10, time.Minute)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("Purge Removes Only Dispatched Events Past Retention", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+		for _, title := range []string{"first", "second", "third"} {
+			_, err := svc.CreateTodo(title, "")
+			require.NoError(t, err)
+		}
+		relay := NewOutboxRelay(repo, &fakePublisher{failures: 1}, time.Second, 1, time.Hour)
+		require.NoError(t, relay.Drain())
+
+		// Act
+		early, earlyErr := relay.Purge(time.Now())
+		purged, err := relay.Purge(time.Now().Add(time.Hour + time.Second))
+
+		// Assert
+		require.NoError(t, earlyErr)
+		assert.Zero(t, early, "dispatched events within retention are kept")
+		require.NoError(t, err)
+		// 발행에 실패한 첫 이벤트는 backoff 이후 다시 발행해야 하므로 남겨 둔다.
+		assert.Equal(t, int64(2), purged, "purges dispatched events in batches until nothing is left")
+	})
+
+	t.Run("Backoff Grows Exponentially Up To Max", func(t *testing.T) {
+		assert.Equal(t, relayMinBackoff, relayBackoff(0))
+		assert.Equal(t, 4*relayMinBackoff, relayBackoff(2))
+		assert.Equal(t, relayMaxBackoff, relayBackoff(100))
+	})
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/recurrence"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"strings"
+	"time"
+)
+
+// WithRecurrence 는 반복 일정(RRULE)을 설정한다. 빈 값이면 반복하지 않는다.
+// 반복 todo 는 마감 시각이 첫 발생 시각이므로 WithSchedule 로 마감 시각도 함께 설정해야 한다.
+func WithRecurrence(rrule string) TodoOption {
+	return func(todo *model.Todo) {
+		todo.RRule = rrule
+	}
+}
+
+// normalizeRRule 은 해석할 수 있는 RRule 을 정해진 순서의 표현으로 바꾼다. 해석할 수 없는 값은 검증에서 거부된다.
+func normalizeRRule(todo *model.Todo) {
+	todo.RRule = strings.TrimSpace(todo.RRule)
+	if rule, err := recurrence.Parse(todo.RRule); err == nil {
+		todo.RRule = rule.String()
+	}
+}
+
+// completeOccurrence 는 반복 todo 의 완료를 저장하고, 같은 트랜잭션 안에서 다음 발생 todo 를 만든다.
+// 반복은 다음 todo 로 넘어가므로 완료된 todo 의 RRule 은 비운다. 다시 미완료로 바꿨다가 완료해도 중복으로 만들지 않는다.
+// COUNT / UNTIL 로 반복이 끝났으면 다음 todo 를 만들지 않는다.
+func completeOccurrence(store repository.TodoStore, todo *model.Todo, events []string) (*model.Todo, error) {
+	next, err := nextOccurrence(todo)
+	if err != nil {
+		return nil, err
+	}
+
+	var completed *model.Todo
+	err = store.Transaction(func(tx repository.TodoStore) error {
+		todo.RRule = ""
+		var err error
+		if completed, err = tx.Update(todo, events...); err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+
+		items, err := tx.GetItems(int(todo.ID))
+		if err != nil {
+			return err
+		}
+		created, err := tx.Create(next, model.EventTodoCreated)
+		if err != nil {
+			return err
+		}
+		// 하위 항목은 미완료 상태로 복사한다.
+		for _, item := range items {
+			if _, err := tx.CreateItem(&model.TodoItem{TodoID: created.ID, Title: item.Title}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return completed, nil
+}
+
+// nextOccurrence 는 반복 todo 의 다음 발생 todo 를 만든다. 반복이 끝났으면 nil 이다.
+// 알림 시각은 마감 시각과의 간격을 유지한다.
+func nextOccurrence(todo *model.Todo) (*model.Todo, error) {
+	rule, err := recurrence.Parse(todo.RRule)
+	if err != nil {
+		return nil, err
+	}
+	dueAt, rest, ok := rule.Next(*todo.DueAt)
+	if !ok {
+		return nil, nil
+	}
+
+	next := &model.Todo{
+		Title:        todo.Title,
+		Description:  todo.Description,
+		ListID:       todo.ListID,
+		AutoComplete: todo.AutoComplete,
+		Priority:     todo.Priority,
+		DueAt:        &dueAt,
+		RRule:        rest.String(),
+		Tags:         todo.Tags,
+	}
+	if todo.RemindAt != nil {
+		remindAt := dueAt.Add(todo.RemindAt.Sub(*todo.DueAt))
+		next.RemindAt = &remindAt
+	}
+	if err := validation.Struct(next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// GetOccurrences 는 반복 todo 의 현재 마감 이후 발생 시각을 최대 limit 개 미리 보여준다.
+// 반복하지 않는 todo 는 빈 목록이다.
+func (s TodoService) GetOccurrences(id, limit int) ([]time.Time, error) {
+	if limit == 0 {
+		limit = model.DefaultOccurrenceLimit
+	}
+	if limit < 0 || limit > model.MaxOccurrenceLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidListQuery, model.MaxOccurrenceLimit)
+	}
+
+	todo, err := s.todoRepository.GetTodo(id)
+	if err != nil {
+		return nil, mapRepositoryError(err)
+	}
+	if todo.RRule == "" || todo.DueAt == nil {
+		return []time.Time{}, nil
+	}
+
+	rule, err := recurrence.Parse(todo.RRule)
+	if err != nil {
+		return nil, err
+	}
+	occurrences := rule.Occurrences(*todo.DueAt, limit+1)
+	if len(occurrences) == 0 {
+		return []time.Time{}, nil
+	}
+	// 첫 번째는 현재 마감 시각이다.
+	return occurrences[1:], nil
+}
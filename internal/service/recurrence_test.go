@@ -0,0 +1,173 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"testing"
+	"time"
+)
+
+func TestRecurringTodos(t *testing.T) {
+	// 2026-10-16 은 금요일
+	dueAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	remindAt := dueAt.Add(-30 * time.Minute)
+
+	t.Run("Rule Is Normalized And Requires Due At", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+
+		// Act
+		todo, err := svc.CreateTodo("standup", "", WithSchedule(&dueAt, nil),
+			WithRecurrence(" rrule:freq=weekly;byday=mo,tu,we,th,fr "))
+		_, noDueErr := svc.CreateTodo("standup", "", WithRecurrence("FREQ=DAILY"))
+		_, unsupportedErr := svc.CreateTodo("standup", "", WithSchedule(&dueAt, nil), WithRecurrence("FREQ=HOURLY"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", todo.RRule)
+		var errs validation.Errors
+		require.ErrorAs(t, noDueErr, &errs)
+		assert.Equal(t, "due_at", errs[0].Field)
+		require.ErrorAs(t, unsupportedErr, &errs)
+		assert.Equal(t, "rrule", errs[0].Field)
+	})
+
+	incomplete := false
+
+	t.Run("Completing Spawns The Next Occurrence", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+		tag, err := NewTagService(repo).CreateTag("work")
+		require.NoError(t, err)
+		todo, err := svc.CreateTodo("standup", "daily sync", WithPriority(model.PriorityHigh),
+			WithSchedule(&dueAt, &remindAt), WithRecurrence("FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=3"))
+		require.NoError(t, err)
+		_, err = svc.AttachTag(int(todo.ID), nil, int(tag.ID))
+		require.NoError(t, err)
+		_, _, err = svc.CreateItem(int(todo.ID), nil, "notes", true)
+		require.NoError(t, err)
+		_, err = repo.ClaimPending(100, time.Minute)
+		require.NoError(t, err)
+
+		// Act
+		completed, err := svc.ReplaceTodo(int(todo.ID), nil, "standup", "daily sync", true,
+			WithPriority(model.PriorityHigh), WithSchedule(&dueAt, &remindAt),
+			WithRecurrence("FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=3"))
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, completed.Completed)
+		assert.Empty(t, completed.RRule, "the series moves to the next occurrence")
+
+		page, err := svc.GetAllTodos(model.TodoListQuery{Completed: &incomplete})
+		require.NoError(t, err)
+		require.Len(t, page.Todos, 1)
+		next := page.Todos[0]
+		assert.Equal(t, "standup", next.Title)
+		assert.Equal(t, "daily sync", next.Description)
+		assert.Equal(t, model.PriorityHigh, next.Priority)
+		assert.Equal(t, dueAt.AddDate(0, 0, 3), *next.DueAt, "the next weekday is monday")
+		assert.Equal(t, remindAt.AddDate(0, 0, 3), *next.RemindAt)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=2", next.RRule)
+		require.Len(t, next.Tags, 1)
+		assert.Equal(t, "work", next.Tags[0].Name)
+		assert.Equal(t, &model.TodoProgress{Completed: 0, Total: 1, Percent: 0}, next.Progress)
+
+		events, err := repo.ClaimPending(100, time.Minute)
+		require.NoError(t, err)
+		var eventTypes []string
+		for _, event := range events {
+			eventTypes = append(eventTypes, event.EventType)
+		}
+		assert.Equal(t, []string{model.EventTodoUpdated, model.EventTodoCompleted, model.EventTodoCreated}, eventTypes)
+	})
+
+	t.Run("Completing Again Does Not Spawn Twice", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		todo, err := svc.CreateTodo("standup", "", WithSchedule(&dueAt, nil), WithRecurrence("FREQ=DAILY"))
+		require.NoError(t, err)
+
+		// Act
+		_, err = svc.PatchTodo(int(todo.ID), nil, mustMergePatch(t, `{"completed": true}`))
+		require.NoError(t, err)
+		_, err = svc.PatchTodo(int(todo.ID), nil, mustMergePatch(t, `{"completed": false}`))
+		require.NoError(t, err)
+		_, err = svc.PatchTodo(int(todo.ID), nil, mustMergePatch(t, `{"completed": true}`))
+		require.NoError(t, err)
+
+		// Assert
+		page, err := svc.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		assert.Len(t, page.Todos, 2)
+	})
+
+	t.Run("Last Occurrence Does Not Spawn", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		todo, err := svc.CreateTodo("standup", "", WithSchedule(&dueAt, nil), WithRecurrence("FREQ=DAILY;COUNT=1"))
+		require.NoError(t, err)
+
+		// Act
+		_, err = svc.ReplaceTodo(int(todo.ID), nil, "standup", "", true,
+			WithSchedule(&dueAt, nil), WithRecurrence("FREQ=DAILY;COUNT=1"))
+		require.NoError(t, err)
+
+		// Assert
+		page, err := svc.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		assert.Len(t, page.Todos, 1)
+	})
+
+	t.Run("Auto Complete Spawns The Next Occurrence", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		todo, err := svc.CreateTodo("standup", "", WithAutoComplete(true),
+			WithSchedule(&dueAt, nil), WithRecurrence("FREQ=MONTHLY;BYDAY=1MO"))
+		require.NoError(t, err)
+		item, _, err := svc.CreateItem(int(todo.ID), nil, "notes", false)
+		require.NoError(t, err)
+
+		// Act
+		_, completed, err := svc.ReplaceItem(int(todo.ID), int(item.ID), nil, "notes", true)
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, completed.Completed)
+		page, err := svc.GetAllTodos(model.TodoListQuery{Completed: &incomplete})
+		require.NoError(t, err)
+		require.Len(t, page.Todos, 1)
+		assert.Equal(t, time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC), *page.Todos[0].DueAt)
+		assert.False(t, page.Todos[0].Progress.Done())
+	})
+
+	t.Run("Preview Occurrences", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		recurring, err := svc.CreateTodo("standup", "", WithSchedule(&dueAt, nil), WithRecurrence("FREQ=MONTHLY;BYDAY=1MO"))
+		require.NoError(t, err)
+		plain, err := svc.CreateTodo("once", "")
+		require.NoError(t, err)
+
+		// Act
+		occurrences, err := svc.GetOccurrences(int(recurring.ID), 2)
+		require.NoError(t, err)
+		none, err := svc.GetOccurrences(int(plain.ID), 0)
+		require.NoError(t, err)
+		_, limitErr := svc.GetOccurrences(int(recurring.ID), model.MaxOccurrenceLimit+1)
+		_, missingErr := svc.GetOccurrences(999, 0)
+
+		// Assert
+		assert.Equal(t, []time.Time{
+			time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC),
+			time.Date(2026, 12, 7, 9, 0, 0, 0, time.UTC),
+		}, occurrences)
+		assert.Empty(t, none)
+		assert.ErrorIs(t, limitErr, ErrInvalidListQuery)
+		assert.ErrorIs(t, missingErr, ErrTodoNotFound)
+	})
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"log"
+	"time"
+)
+
+const (
+	defaultReminderInterval  = 30 * time.Second
+	defaultReminderBatchSize = 100
+)
+
+// ReminderScheduler 는 알림 시각(remind_at)이 지난 todo 에 대해 todo_reminder 이벤트를 outbox 에 기록한다.
+// 이벤트는 OutboxRelay 가 다른 이벤트와 같은 경로로 SQS 에 발행한다.
+type ReminderScheduler struct {
+	store     repository.ReminderStore
+	interval  time.Duration
+	batchSize int
+}
+
+func NewReminderScheduler(store repository.ReminderStore, interval time.Duration, batchSize int) *ReminderScheduler {
+	if interval <= 0 {
+		interval = defaultReminderInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultReminderBatchSize
+	}
+
+	return &ReminderScheduler{
+		store:     store,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run 은 ctx 가 취소될 때까지 interval 마다 알림 시각이 지난 todo 를 확인한다.
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Dispatch(time.Now()); err != nil {
+			log.Printf("reminder scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch 는 now 기준으로 알림 시각이 지난 todo 가 없을 때까지 배치 단위로 알림 이벤트를 기록하고 건수를 반환한다.
+func (s *ReminderScheduler) Dispatch(now time.Time) (int, error) {
+	var total int
+	for {
+		todos, err := s.store.ClaimDueReminders(now, s.batchSize, model.EventTodoReminder)
+		total += len(todos)
+		if err != nil {
+			return total, err
+		}
+		if len(todos) < s.batchSize {
+			return total, nil
+		}
+	}
+}
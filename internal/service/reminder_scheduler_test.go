@@ -0,0 +1,81 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestReminderScheduler(t *testing.T) {
+	t.Run("Dispatch Records Reminder Once For Due Todos", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+		now := time.Now()
+		past, future := now.Add(-time.Minute), now.Add(time.Hour)
+		due, err := svc.CreateTodo("due", "", WithSchedule(nil, &past))
+		require.NoError(t, err)
+		_, err = svc.CreateTodo("later", "", WithSchedule(nil, &future))
+		require.NoError(t, err)
+		completed, err := svc.CreateTodo("completed", "", WithSchedule(nil, &past))
+		require.NoError(t, err)
+		_, err = svc.PatchTodo(int(completed.ID), nil, mustMergePatch(t, `{"completed": true}`))
+		require.NoError(t, err)
+		scheduler := NewReminderScheduler(repo, time.Second, 1)
+
+		// Act
+		first, firstErr := scheduler.Dispatch(now)
+		second, secondErr := scheduler.Dispatch(now)
+
+		// Assert
+		require.NoError(t, firstErr)
+		require.NoError(t, secondErr)
+		assert.Equal(t, 1, first)
+		assert.Zero(t, second, "reminders are sent only once")
+
+		reminded, err := svc.GetTodoById(int(due.ID))
+		require.NoError(t, err)
+		require.NotNil(t, reminded.RemindedAt)
+		assert.Equal(t, due.Version+1, reminded.Version)
+
+		events, err := repo.ClaimPending(10, time.Minute)
+		require.NoError(t, err)
+		var reminders []int64
+		for _, event := range events {
+			if event.EventType == model.EventTodoReminder {
+				reminders = append(reminders, event.TodoID)
+			}
+		}
+		assert.Equal(t, []int64{due.ID}, reminders)
+	})
+
+	t.Run("Changing Remind At Rearms Reminder", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+		now := time.Now()
+		past := now.Add(-time.Minute)
+		created, err := svc.CreateTodo("dummy title", "", WithSchedule(nil, &past))
+		require.NoError(t, err)
+		scheduler := NewReminderScheduler(repo, time.Second, 10)
+		_, err = scheduler.Dispatch(now)
+		require.NoError(t, err)
+
+		// Act
+		untouched, err := svc.PatchTodo(int(created.ID), nil, mustMergePatch(t, `{"description": "edited"}`))
+		require.NoError(t, err)
+		rescheduled, err := svc.PatchTodo(int(created.ID), nil,
+			mustMergePatch(t, `{"remind_at": "`+now.Add(time.Minute).Format(time.RFC3339Nano)+`"}`))
+		require.NoError(t, err)
+		dispatched, err := scheduler.Dispatch(now.Add(2 * time.Minute))
+
+		// Assert
+		assert.NotNil(t, untouched.RemindedAt, "unrelated changes keep the sent reminder")
+		assert.Nil(t, rescheduled.RemindedAt)
+		require.NoError(t, err)
+		assert.Equal(t, 1, dispatched)
+	})
+}
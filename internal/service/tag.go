@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"strings"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagConflict 는 같은 이름(대소문자 구분 없음)의 태그가 이미 있을 때 반환된다.
+	ErrTagConflict = errors.New("tag name already exists")
+)
+
+// TagService 는 태그 자체를 관리한다. 태그를 todo 에 붙이고 떼는 것은 TodoService 가 담당한다.
+type TagService struct {
+	tagRepository repository.TagStore
+}
+
+func NewTagService(repo repository.TagStore) *TagService {
+	return &TagService{tagRepository: repo}
+}
+
+// ForUser 는 userID 사용자의 태그만 다루는 TagService 를 반환한다. 만드는 태그는 userID 사용자의 것이 된다.
+func (s TagService) ForUser(userID int64) *TagService {
+	s.tagRepository = s.tagRepository.TagsForOwner(userID)
+	return &s
+}
+
+func (s TagService) GetTags() ([]*model.Tag, error) {
+	return s.tagRepository.GetTags()
+}
+
+func (s TagService) CreateTag(name string) (*model.Tag, error) {
+	tag := &model.Tag{Name: strings.TrimSpace(name)}
+	if err := validation.Struct(tag); err != nil {
+		return nil, err
+	}
+
+	created, err := s.tagRepository.CreateTag(tag)
+	if err != nil {
+		return nil, mapTagError(err)
+	}
+	return created, nil
+}
+
+// RenameTag 는 태그 이름을 바꾼다. 태그가 붙은 todo 는 모두 새 이름으로 조회된다.
+func (s TagService) RenameTag(id int, name string) (*model.Tag, error) {
+	tag := &model.Tag{ID: int64(id), Name: strings.TrimSpace(name)}
+	if err := validation.Struct(tag); err != nil {
+		return nil, err
+	}
+
+	renamed, err := s.tagRepository.RenameTag(id, tag.Name)
+	if err != nil {
+		return nil, mapTagError(err)
+	}
+	return renamed, nil
+}
+
+// DeleteTag 는 태그를 삭제하고 모든 todo 에서 뗀다.
+func (s TagService) DeleteTag(id int) error {
+	return mapTagError(s.tagRepository.DeleteTag(id))
+}
+
+// AttachTag 는 todo 에 태그를 붙인다. 이미 붙어 있으면 todo 를 그대로 반환한다.
+// 읽은 뒤 다른 요청이 먼저 변경했다면 updateTodo 와 같이 처리한다.
+func (s *TodoService) AttachTag(id int, ifMatch Precondition, tagID int) (*model.Todo, error) {
+	return s.changeTag(id, ifMatch, tagID, s.todoRepository.AttachTag)
+}
+
+// DetachTag 는 todo 에서 태그를 뗀다. 붙어 있지 않으면 todo 를 그대로 반환한다.
+// 읽은 뒤 다른 요청이 먼저 변경했다면 updateTodo 와 같이 처리한다.
+func (s *TodoService) DetachTag(id int, ifMatch Precondition, tagID int) (*model.Todo, error) {
+	return s.changeTag(id, ifMatch, tagID, s.todoRepository.DetachTag)
+}
+
+func (s *TodoService) changeTag(
+	id int,
+	ifMatch Precondition,
+	tagID int,
+	change func(id int, version int64, tagID int, events ...string) (*model.Todo, error),
+) (*model.Todo, error) {
+	for attempt := 1; ; attempt++ {
+		existingTodo, err := s.todoRepository.GetTodo(id)
+		if err != nil {
+			return nil, mapRepositoryError(err)
+		}
+		if ifMatch != nil && !ifMatch(existingTodo) {
+			return nil, ErrPreconditionFailed
+		}
+
+		changedTodo, err := change(id, existingTodo.Version, tagID, model.EventTodoUpdated)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			if ifMatch == nil && attempt < maxUpdateAttempts {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, mapConflictError(mapTagError(err), ifMatch)
+		}
+
+		return changedTodo, nil
+	}
+}
+
+// normalizeTagFilter 는 태그 필터 이름의 앞뒤 공백과 (대소문자 구분 없는) 중복을 제거한다.
+func normalizeTagFilter(names []string) ([]string, bool) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, false
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, name)
+	}
+	return normalized, true
+}
+
+// mapTagError 는 태그 관련 repository 에러를 service 에러로 변환한다.
+func mapTagError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrTagNotFound):
+		return ErrTagNotFound
+	case errors.Is(err, repository.ErrDuplicateTag):
+		return ErrTagConflict
+	}
+	return mapRepositoryError(err)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"testing"
+)
+
+func TestTagService(t *testing.T) {
+	t.Run("Create Tag Rejects Duplicate Names Ignoring Case", func(t *testing.T) {
+		// Arrange
+		tags := NewTagService(repository.NewMemoryTodoRepository())
+		_, err := tags.CreateTag("  work ")
+		require.NoError(t, err)
+
+		// Act
+		_, duplicateErr := tags.CreateTag("WORK")
+		_, blankErr := tags.CreateTag("   ")
+
+		// Assert
+		assert.ErrorIs(t, duplicateErr, ErrTagConflict)
+		var validationErrors validation.Errors
+		assert.ErrorAs(t, blankErr, &validationErrors)
+		all, err := tags.GetTags()
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, "work", all[0].Name, "name is trimmed")
+	})
+
+	t.Run("Tags Are Scoped To Their Owner", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		aliceTags, bobTags := NewTagService(repo).ForUser(1), NewTagService(repo).ForUser(2)
+		bobTodos := NewTodoService(repo).ForUser(2)
+		tag, err := aliceTags.CreateTag("work")
+		require.NoError(t, err)
+		todo, err := bobTodos.CreateTodo("bob's todo", "")
+		require.NoError(t, err)
+
+		// Act
+		bobTag, createErr := bobTags.CreateTag("Work")
+		_, renameErr := bobTags.RenameTag(int(tag.ID), "stolen")
+		deleteErr := bobTags.DeleteTag(int(tag.ID))
+		_, attachErr := bobTodos.AttachTag(int(todo.ID), nil, int(tag.ID))
+		bobAll, err := bobTags.GetTags()
+		require.NoError(t, err)
+
+		// Assert
+		require.NoError(t, createErr, "tag names are unique per owner")
+		assert.ErrorIs(t, renameErr, ErrTagNotFound)
+		assert.ErrorIs(t, deleteErr, ErrTagNotFound)
+		assert.ErrorIs(t, attachErr, ErrTagNotFound)
+		require.Len(t, bobAll, 1)
+		assert.Equal(t, bobTag.ID, bobAll[0].ID)
+
+		aliceAll, err := aliceTags.GetTags()
+		require.NoError(t, err)
+		require.Len(t, aliceAll, 1)
+		assert.Equal(t, "work", aliceAll[0].Name)
+	})
+
+	t.Run("Attach And Detach Tag", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc, tags := NewTodoService(repo), NewTagService(repo)
+		todo, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		home, err := tags.CreateTag("home")
+		require.NoError(t, err)
+		work, err := tags.CreateTag("work")
+		require.NoError(t, err)
+
+		// Act
+		_, err = svc.AttachTag(int(todo.ID), nil, int(work.ID))
+		require.NoError(t, err)
+		attached, err := svc.AttachTag(int(todo.ID), nil, int(home.ID))
+		require.NoError(t, err)
+		again, err := svc.AttachTag(int(todo.ID), nil, int(home.ID))
+		require.NoError(t, err)
+		detached, err := svc.DetachTag(int(todo.ID), nil, int(work.ID))
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, []model.Tag{*home, *work}, attached.Tags, "tags are sorted by name")
+		assert.Equal(t, todo.Version+2, attached.Version)
+		assert.Equal(t, attached.Version, again.Version, "attaching an attached tag changes nothing")
+		assert.Equal(t, []model.Tag{*home}, detached.Tags)
+		stored, err := svc.GetTodoById(int(todo.ID))
+		require.NoError(t, err)
+		assert.Equal(t, detached, stored)
+
+		_, err = svc.AttachTag(int(todo.ID), nil, 999)
+		assert.ErrorIs(t, err, ErrTagNotFound)
+	})
+
+	t.Run("Rename And Delete Tag Change Tagged Todos", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc, tags := NewTodoService(repo), NewTagService(repo)
+		tagged, err := svc.CreateTodo("tagged", "")
+		require.NoError(t, err)
+		untagged, err := svc.CreateTodo("untagged", "")
+		require.NoError(t, err)
+		tag, err := tags.CreateTag("work")
+		require.NoError(t, err)
+		tagged, err = svc.AttachTag(int(tagged.ID), nil, int(tag.ID))
+		require.NoError(t, err)
+
+		// Act
+		_, err = tags.RenameTag(int(tag.ID), "office")
+		require.NoError(t, err)
+		renamed, err := svc.GetTodoById(int(tagged.ID))
+		require.NoError(t, err)
+		require.NoError(t, tags.DeleteTag(int(tag.ID)))
+		deleted, err := svc.GetTodoById(int(tagged.ID))
+		require.NoError(t, err)
+
+		// Assert
+		require.Len(t, renamed.Tags, 1)
+		assert.Equal(t, "office", renamed.Tags[0].Name)
+		assert.Equal(t, tagged.Version+1, renamed.Version, "ETag changes with the tag name")
+		assert.Empty(t, deleted.Tags)
+		assert.Equal(t, renamed.Version+1, deleted.Version)
+		unchanged, err := svc.GetTodoById(int(untagged.ID))
+		require.NoError(t, err)
+		assert.Equal(t, untagged.Version, unchanged.Version)
+	})
+
+	t.Run("Get All Todos Filters By Tags", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc, tags := NewTodoService(repo), NewTagService(repo)
+		work, err := tags.CreateTag("work")
+		require.NoError(t, err)
+		urgent, err := tags.CreateTag("urgent")
+		require.NoError(t, err)
+
+		tagged := map[string][]*model.Tag{
+			"work only":   {work},
+			"urgent only": {urgent},
+			"both":        {work, urgent},
+			"none":        nil,
+		}
+		ids := make(map[string]int64)
+		for title, todoTags := range tagged {
+			todo, err := svc.CreateTodo(title, "")
+			require.NoError(t, err)
+			for _, tag := range todoTags {
+				_, err := svc.AttachTag(int(todo.ID), nil, int(tag.ID))
+				require.NoError(t, err)
+			}
+			ids[title] = todo.ID
+		}
+		filter := func(match string, names ...string) []int64 {
+			page, err := svc.GetAllTodos(model.TodoListQuery{Tags: names, TagMatch: match})
+			require.NoError(t, err)
+			var result []int64
+			for _, todo := range page.Todos {
+				result = append(result, todo.ID)
+			}
+			return result
+		}
+
+		// Act & Assert
+		assert.ElementsMatch(t, []int64{ids["work only"], ids["both"]}, filter("", "Work"))
+		assert.ElementsMatch(t, []int64{ids["work only"], ids["urgent only"], ids["both"]},
+			filter(model.TagMatchAny, "work", "urgent"))
+		assert.Equal(t, []int64{ids["both"]}, filter(model.TagMatchAll, "work", "urgent", "WORK"),
+			"duplicate names are ignored")
+		assert.Empty(t, filter(model.TagMatchAll, "work", "missing"))
+	})
+
+	t.Run("Get All Todos Rejects Invalid Tag Filter", func(t *testing.T) {
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		tooMany := make([]string, model.MaxTagFilters+1)
+		for i := range tooMany {
+			tooMany[i] = string(rune('a' + i))
+		}
+
+		invalidQueries := map[string]model.TodoListQuery{
+			"blank tag":         {Tags: []string{" "}},
+			"too many tags":     {Tags: tooMany},
+			"unknown tag match": {Tags: []string{"work"}, TagMatch: "some"},
+		}
+		for name, query := range invalidQueries {
+			t.Run(name, func(t *testing.T) {
+				// Act
+				_, err := svc.GetAllTodos(query)
+
+				// Assert
+				assert.ErrorIs(t, err, ErrInvalidListQuery)
+			})
+		}
+	})
+}
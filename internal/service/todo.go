@@ -1,80 +1,522 @@
 package service
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"integration-test-example/internal/model"
+	"integration-test-example/internal/patch"
 	"integration-test-example/internal/repository"
+	"integration-test-example/internal/search"
+	"integration-test-example/internal/validation"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 var (
-	ErrTodoNotFound = errors.New("todo not found")
+	ErrTodoNotFound     = errors.New("todo not found")
+	ErrInvalidListQuery = errors.New("invalid list query")
+	ErrInvalidPatch     = errors.New("invalid patch")
+	// ErrPatchConflict 는 JSON Patch 의 test 연산이 현재 상태와 일치하지 않을 때 반환된다.
+	ErrPatchConflict = errors.New("patch conflicts with current state")
+	// ErrPreconditionFailed 는 저장된 todo 가 요청의 Precondition 을 만족하지 않을 때 반환된다.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrInvalidMove 는 move 의 기준 todo 가 잘못 지정되었거나 존재하지 않을 때 반환된다.
+	ErrInvalidMove = errors.New("invalid move")
+	// ErrTodoConflict 는 조건 없는 변경이 재시도 후에도 동시 변경과 계속 충돌할 때 반환된다.
+	ErrTodoConflict = errors.New("todo was modified concurrently")
 )
 
+// Precondition 은 변경 직전의 저장된 todo 가 만족해야 하는 조건 (If-Match). nil 이면 조건 없이 변경한다.
+type Precondition func(current *model.Todo) bool
+
+// maxUpdateAttempts 는 조건 없는 변경이 version 충돌 시 최신 상태로 다시 시도하는 최대 횟수
+const maxUpdateAttempts = 3
+
+// TodoService 는 모든 변경에 대해 도메인 이벤트를 outbox 에 함께 기록한다.
+// 실제 발행은 OutboxRelay 가 담당한다.
 type TodoService struct {
-	todoRepository *repository.TodoRepository
+	todoRepository repository.TodoStore
+	maxBatchSize   int
+}
+
+func NewTodoService(repo repository.TodoStore) *TodoService {
+	return &TodoService{
+		todoRepository: repo,
+		maxBatchSize:   DefaultMaxBatchSize,
+	}
+}
+
+// ForUser 는 userID 사용자의 todo 만 다루는 TodoService 를 반환한다. 만드는 todo 는 userID 사용자의 것이 되며, 나머지 설정은 그대로다.
+func (s TodoService) ForUser(userID int64) *TodoService {
+	s.todoRepository = s.todoRepository.ForOwner(userID)
+	return &s
+}
+
+// TodoOption 은 CreateTodo / ReplaceTodo 에서 title, description, completed 외의 필드를 설정한다.
+type TodoOption func(todo *model.Todo)
+
+// WithSchedule 은 마감 시각과 알림 시각을 설정한다. nil 이면 설정하지 않는다.
+func WithSchedule(dueAt, remindAt *time.Time) TodoOption {
+	return func(todo *model.Todo) {
+		todo.DueAt = dueAt
+		todo.RemindAt = remindAt
+	}
 }
 
-func NewTodoService(repo *repository.TodoRepository) *TodoService {
-	return &TodoService{todoRepository: repo}
+// WithAutoComplete 는 모든 item 이 완료되면 todo 도 완료할지 설정한다.
+func WithAutoComplete(autoComplete bool) TodoOption {
+	return func(todo *model.Todo) {
+		todo.AutoComplete = autoComplete
+	}
 }
 
-func (s TodoService) CreateTodo(title, description string) (*model.Todo, error) {
+// WithList 는 todo 가 속할 list 를 설정한다. nil 이면 list 에 속하지 않는다.
+func WithList(listID *int64) TodoOption {
+	return func(todo *model.Todo) {
+		todo.ListID = listID
+	}
+}
+
+// WithPriority 는 우선순위를 설정한다. 빈 값이면 기본값(normal)을 사용한다.
+func WithPriority(priority model.Priority) TodoOption {
+	return func(todo *model.Todo) {
+		if priority != "" {
+			todo.Priority = priority
+		}
+	}
+}
+
+func (s TodoService) CreateTodo(title, description string, opts ...TodoOption) (*model.Todo, error) {
+	return createTodo(s.todoRepository, title, description, opts...)
+}
+
+func createTodo(store repository.TodoStore, title, description string, opts ...TodoOption) (*model.Todo, error) {
 	todo := &model.Todo{
-		Title:       title,
+		Title:       strings.TrimSpace(title),
 		Description: description,
 		Completed:   false,
+		Priority:    model.PriorityNormal,
+	}
+	for _, opt := range opts {
+		opt(todo)
+	}
+	normalizeRRule(todo)
+	if err := validation.Struct(todo); err != nil {
+		return nil, err
+	}
+
+	created, err := store.Create(todo, model.EventTodoCreated)
+	if err != nil {
+		return nil, mapRepositoryError(err)
 	}
-	return s.todoRepository.Create(todo)
+	return created, nil
 }
 
-func (s TodoService) GetAllTodos() ([]*model.Todo, error) {
-	todos, err := s.todoRepository.GetAll()
+func (s TodoService) GetAllTodos(query model.TodoListQuery) (*model.TodoPage, error) {
+	query.Trashed = false
+	return s.listTodos(query)
+}
+
+// GetTrash 는 휴지통의 todo 목록을 조회한다. 기본 정렬은 휴지통으로 옮겨진 시각의 역순이다.
+func (s TodoService) GetTrash(query model.TodoListQuery) (*model.TodoPage, error) {
+	query.Trashed = true
+	return s.listTodos(query)
+}
+
+func (s TodoService) listTodos(query model.TodoListQuery) (*model.TodoPage, error) {
+	query, err := normalizeListQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.todoRepository.GetAll(query)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidListQuery, err)
+		}
 		return nil, err
 	}
-	return todos, nil
+
+	if terms := search.Terms(query.Q); len(terms) > 0 {
+		for _, todo := range page.Todos {
+			highlight(todo, terms)
+		}
+	}
+	return page, nil
+}
+
+// descriptionSnippetLength 는 검색 결과에서 description 하이라이트 조각의 최대 문자 수
+const descriptionSnippetLength = 160
+
+// highlight 는 검색 결과 todo 의 Match 에 필드별 하이라이트를 채운다.
+func highlight(todo *model.Todo, terms []string) {
+	if todo.Match == nil {
+		todo.Match = &model.TodoMatch{}
+	}
+	todo.Match.Highlights = make(map[string]string)
+	if snippet, ok := search.Highlight(todo.Title, terms, 0); ok {
+		todo.Match.Highlights["title"] = snippet
+	}
+	if snippet, ok := search.Highlight(todo.Description, terms, descriptionSnippetLength); ok {
+		todo.Match.Highlights["description"] = snippet
+	}
+}
+
+// normalizeListQuery 는 목록 조회 옵션의 기본값을 채우고 허용 범위를 검증한다.
+func normalizeListQuery(query model.TodoListQuery) (model.TodoListQuery, error) {
+	if query.Limit == 0 {
+		query.Limit = model.DefaultTodoListLimit
+	}
+	if query.Limit < 0 || query.Limit > model.MaxTodoListLimit {
+		return query, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidListQuery, model.MaxTodoListLimit)
+	}
+
+	if query.DueBefore != nil && query.DueAfter != nil && !query.DueAfter.Before(*query.DueBefore) {
+		return query, fmt.Errorf("%w: due_after must be before due_before", ErrInvalidListQuery)
+	}
+	if query.Now.IsZero() {
+		query.Now = time.Now()
+	}
+
+	query.Q = strings.TrimSpace(query.Q)
+	if utf8.RuneCountInString(query.Q) > search.MaxQueryLength {
+		return query, fmt.Errorf("%w: q must be at most %d characters", ErrInvalidListQuery, search.MaxQueryLength)
+	}
+	if query.Q != "" && len(search.Terms(query.Q)) == 0 {
+		return query, fmt.Errorf("%w: q must contain at least one search term", ErrInvalidListQuery)
+	}
+
+	if len(query.Tags) > 0 {
+		tags, ok := normalizeTagFilter(query.Tags)
+		if !ok {
+			return query, fmt.Errorf("%w: tag must not be blank", ErrInvalidListQuery)
+		}
+		if len(tags) > model.MaxTagFilters {
+			return query, fmt.Errorf("%w: at most %d tags can be given", ErrInvalidListQuery, model.MaxTagFilters)
+		}
+		query.Tags = tags
+	}
+	switch query.TagMatch {
+	case "":
+		query.TagMatch = model.TagMatchAny
+	case model.TagMatchAny, model.TagMatchAll:
+	default:
+		return query, fmt.Errorf("%w: unsupported tag_match %q", ErrInvalidListQuery, query.TagMatch)
+	}
+
+	switch query.Sort {
+	case "":
+		switch {
+		case query.Q != "":
+			query.Sort = model.SortRelevance
+		case query.Trashed:
+			query.Sort = model.SortDeletedAt
+		default:
+			query.Sort = model.SortCreatedAt
+		}
+	case model.SortCreatedAt, model.SortUpdatedAt, model.SortTitle, model.SortPriority, model.SortPosition:
+	case model.SortRelevance:
+		if query.Q == "" {
+			return query, fmt.Errorf("%w: sort %q requires q", ErrInvalidListQuery, query.Sort)
+		}
+	case model.SortDeletedAt:
+		if !query.Trashed {
+			return query, fmt.Errorf("%w: sort %q is only supported for trash", ErrInvalidListQuery, query.Sort)
+		}
+	default:
+		return query, fmt.Errorf("%w: unsupported sort %q", ErrInvalidListQuery, query.Sort)
+	}
+
+	switch query.Order {
+	case "":
+		// 수동 정렬은 사용자가 배치한 순서 그대로, 나머지는 최신/높은 값부터
+		query.Order = model.OrderDesc
+		if query.Sort == model.SortPosition {
+			query.Order = model.OrderAsc
+		}
+	case model.OrderAsc, model.OrderDesc:
+	default:
+		return query, fmt.Errorf("%w: unsupported order %q", ErrInvalidListQuery, query.Order)
+	}
+
+	return query, nil
 }
 
 func (s TodoService) GetTodoById(id int) (*model.Todo, error) {
 	todo, err := s.todoRepository.GetTodo(id)
 	if err != nil {
-		if err == repository.ErrTodoNotFound {
-			return nil, ErrTodoNotFound
-		}
-		return nil, err
+		return nil, mapRepositoryError(err)
 	}
 	return todo, nil
 }
 
-func (s *TodoService) UpdateTodo(id int, title, description *string, completed *bool) (*model.Todo, error) {
-	existingTodo, err := s.todoRepository.GetTodo(id)
-	if err != nil {
-		return nil, err
+// ReplaceTodo 는 변경 가능한 필드 전체를 주어진 값으로 교체한다. (PUT)
+// opts 로 주어지지 않은 필드는 빈 값이 된다.
+func (s *TodoService) ReplaceTodo(id int, ifMatch Precondition, title, description string, completed bool, opts ...TodoOption) (*model.Todo, error) {
+	return updateTodo(s.todoRepository, id, ifMatch, replaceFields(title, description, completed, opts...))
+}
+
+func replaceFields(title, description string, completed bool, opts ...TodoOption) func(todo *model.Todo) error {
+	return func(todo *model.Todo) error {
+		todo.Title = title
+		todo.Description = description
+		todo.Completed = completed
+		todo.ListID = nil
+		todo.AutoComplete = false
+		todo.Priority = model.PriorityNormal
+		todo.DueAt = nil
+		todo.RemindAt = nil
+		todo.RRule = ""
+		for _, opt := range opts {
+			opt(todo)
+		}
+		return nil
+	}
+}
+
+// todoDocument 는 PATCH 가 적용되는 todo 의 JSON 표현.
+// id, created_at 같은 필드는 포함하지 않으므로 patch 로 변경할 수 없다.
+type todoDocument struct {
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Completed    bool           `json:"completed"`
+	ListID       *int64         `json:"list_id"`
+	AutoComplete bool           `json:"auto_complete"`
+	Priority     model.Priority `json:"priority"`
+	DueAt        *time.Time     `json:"due_at"`
+	RemindAt     *time.Time     `json:"remind_at"`
+	RRule        string         `json:"rrule"`
+}
+
+// PatchTodo 는 저장된 todo 에 patch 를 적용한다. (PATCH)
+// patch 로 제거된 필드는 빈 값이 되므로 description 을 비울 수 있다.
+func (s *TodoService) PatchTodo(id int, ifMatch Precondition, p patch.Patch) (*model.Todo, error) {
+	return updateTodo(s.todoRepository, id, ifMatch, func(todo *model.Todo) error {
+		doc, err := json.Marshal(todoDocument{
+			Title:        todo.Title,
+			Description:  todo.Description,
+			Completed:    todo.Completed,
+			ListID:       todo.ListID,
+			AutoComplete: todo.AutoComplete,
+			Priority:     todo.Priority,
+			DueAt:        todo.DueAt,
+			RemindAt:     todo.RemindAt,
+			RRule:        todo.RRule,
+		})
+		if err != nil {
+			return err
+		}
+
+		patched, err := p.Apply(doc)
+		if err != nil {
+			if errors.Is(err, patch.ErrTestFailed) {
+				return fmt.Errorf("%w: %v", ErrPatchConflict, err)
+			}
+			return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+
+		var result todoDocument
+		decoder := json.NewDecoder(bytes.NewReader(patched))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&result); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+
+		todo.Title = result.Title
+		todo.Description = result.Description
+		todo.Completed = result.Completed
+		todo.ListID = result.ListID
+		todo.AutoComplete = result.AutoComplete
+		todo.Priority = result.Priority
+		todo.DueAt = result.DueAt
+		todo.RemindAt = result.RemindAt
+		todo.RRule = result.RRule
+		return nil
+	})
+}
+
+// updateTodo 는 저장된 todo 에 mutate 를 적용하고 검증한 뒤 저장한다.
+// 반복 todo 가 완료되면 같은 트랜잭션 안에서 다음 발생 todo 를 만든다. (completeOccurrence)
+// 읽은 뒤 다른 요청이 먼저 변경했다면 ifMatch 가 있으면 ErrPreconditionFailed,
+// 없으면 최신 상태를 다시 읽어 mutate 를 재적용한다.
+func updateTodo(store repository.TodoStore, id int, ifMatch Precondition, mutate func(todo *model.Todo) error) (*model.Todo, error) {
+	for attempt := 1; ; attempt++ {
+		existingTodo, err := store.GetTodo(id)
+		if err != nil {
+			return nil, mapRepositoryError(err)
+		}
+		if ifMatch != nil && !ifMatch(existingTodo) {
+			return nil, ErrPreconditionFailed
+		}
+
+		wasCompleted := existingTodo.Completed
+		remindAt := existingTodo.RemindAt
+		if err := mutate(existingTodo); err != nil {
+			return nil, err
+		}
+		existingTodo.Title = strings.TrimSpace(existingTodo.Title)
+		normalizeRRule(existingTodo)
+		// patch 로 제거된 우선순위는 기본값으로 돌아간다.
+		if existingTodo.Priority == "" {
+			existingTodo.Priority = model.PriorityNormal
+		}
+		// 알림 시각이 바뀌면 새 시각에 다시 알림을 보낸다.
+		if !sameTime(remindAt, existingTodo.RemindAt) {
+			existingTodo.RemindedAt = nil
+		}
+
+		if err := validation.Struct(existingTodo); err != nil {
+			return nil, err
+		}
+
+		events := []string{model.EventTodoUpdated}
+		// 미완료 → 완료로 실제 전환된 경우에만 완료 이벤트
+		if !wasCompleted && existingTodo.Completed {
+			events = append(events, model.EventTodoCompleted)
+		}
+
+		var updatedTodo *model.Todo
+		if !wasCompleted && existingTodo.Completed && existingTodo.RRule != "" {
+			updatedTodo, err = completeOccurrence(store, existingTodo, events)
+		} else {
+			updatedTodo, err = store.Update(existingTodo, events...)
+		}
+		if errors.Is(err, repository.ErrVersionConflict) {
+			if ifMatch == nil && attempt < maxUpdateAttempts {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, mapConflictError(mapRepositoryError(err), ifMatch)
+		}
+
+		return updatedTodo, nil
+	}
+}
+
+// DeleteTodo 는 todo 를 휴지통으로 옮긴다. 읽은 뒤 다른 요청이 먼저 변경했다면 updateTodo 와 같이 처리한다.
+func (s *TodoService) DeleteTodo(id int, ifMatch Precondition) error {
+	return deleteTodo(s.todoRepository, id, ifMatch)
+}
+
+func deleteTodo(store repository.TodoStore, id int, ifMatch Precondition) error {
+	for attempt := 1; ; attempt++ {
+		existingTodo, err := store.GetTodo(id)
+		if err != nil {
+			return mapRepositoryError(err)
+		}
+		if ifMatch != nil && !ifMatch(existingTodo) {
+			return ErrPreconditionFailed
+		}
+
+		err = store.Delete(id, existingTodo.Version, model.EventTodoDeleted)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			if ifMatch == nil && attempt < maxUpdateAttempts {
+				continue
+			}
+		}
+		if err != nil {
+			return mapConflictError(mapRepositoryError(err), ifMatch)
+		}
+
+		return nil
 	}
+}
 
-	if title != nil {
-		existingTodo.Title = *title
+// MoveTodo 는 todo 를 afterID todo 의 바로 뒤, 또는 beforeID todo 의 바로 앞으로 옮긴다. 둘 중 하나만 지정해야 한다.
+// 읽은 뒤 다른 요청이 먼저 변경했다면 updateTodo 와 같이 처리한다.
+func (s *TodoService) MoveTodo(id int, ifMatch Precondition, afterID, beforeID int) (*model.Todo, error) {
+	if (afterID == 0) == (beforeID == 0) {
+		return nil, fmt.Errorf("%w: exactly one of after_id and before_id is required", ErrInvalidMove)
 	}
-	if description != nil {
-		existingTodo.Description = *description
+	anchorID, after := afterID, true
+	if beforeID != 0 {
+		anchorID, after = beforeID, false
 	}
-	if completed != nil {
-		existingTodo.Completed = *completed
+	if anchorID == id {
+		return nil, fmt.Errorf("%w: a todo cannot be moved relative to itself", ErrInvalidMove)
 	}
 
-	updatedTodo, err := s.todoRepository.Update(existingTodo)
-	if err != nil {
-		return nil, err
+	for attempt := 1; ; attempt++ {
+		existingTodo, err := s.todoRepository.GetTodo(id)
+		if err != nil {
+			return nil, mapRepositoryError(err)
+		}
+		if ifMatch != nil && !ifMatch(existingTodo) {
+			return nil, ErrPreconditionFailed
+		}
+
+		movedTodo, err := s.todoRepository.Move(id, existingTodo.Version, anchorID, after, model.EventTodoUpdated)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			if ifMatch == nil && attempt < maxUpdateAttempts {
+				continue
+			}
+		}
+		if errors.Is(err, repository.ErrAnchorNotFound) {
+			return nil, fmt.Errorf("%w: todo %d to move relative to does not exist", ErrInvalidMove, anchorID)
+		}
+		if err != nil {
+			return nil, mapConflictError(mapRepositoryError(err), ifMatch)
+		}
+
+		return movedTodo, nil
 	}
+}
+
+// RestoreTodo 는 휴지통의 todo 를 되돌린다. 읽은 뒤 다른 요청이 먼저 변경했다면 updateTodo 와 같이 처리한다.
+func (s *TodoService) RestoreTodo(id int, ifMatch Precondition) (*model.Todo, error) {
+	for attempt := 1; ; attempt++ {
+		trashedTodo, err := s.todoRepository.GetTrashedTodo(id)
+		if err != nil {
+			return nil, mapRepositoryError(err)
+		}
+		if ifMatch != nil && !ifMatch(trashedTodo) {
+			return nil, ErrPreconditionFailed
+		}
+
+		restoredTodo, err := s.todoRepository.Restore(id, trashedTodo.Version, model.EventTodoRestored)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			if ifMatch == nil && attempt < maxUpdateAttempts {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, mapConflictError(mapRepositoryError(err), ifMatch)
+		}
 
-	return updatedTodo, nil
+		return restoredTodo, nil
+	}
 }
 
-func (s *TodoService) DeleteTodo(id int) error {
-	err := s.todoRepository.Delete(id)
-	if err != nil {
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// mapConflictError 는 version 충돌을 조건부 요청이면 ErrPreconditionFailed, 아니면 ErrTodoConflict 로 변환한다.
+func mapConflictError(err error, ifMatch Precondition) error {
+	if !errors.Is(err, repository.ErrVersionConflict) {
 		return err
 	}
+	if ifMatch != nil {
+		return ErrPreconditionFailed
+	}
+	return ErrTodoConflict
+}
 
-	return nil
+// mapRepositoryError 는 repository 에러를 service 에러로 변환한다.
+// todo 의 list_id 가 없는 list 를 가리키면 요청 필드의 문제이므로 검증 에러로 바꾼다.
+func mapRepositoryError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrTodoNotFound):
+		return ErrTodoNotFound
+	case errors.Is(err, repository.ErrListNotFound):
+		return validation.Errors{{Field: "list_id", Code: "list", Message: "must refer to an existing list"}}
+	}
+	return err
 }
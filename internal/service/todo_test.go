@@ -0,0 +1,757 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/patch"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/search"
+	"integration-test-example/internal/validation"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTodoService(t *testing.T) {
+	t.Run("Create Todo", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+
+		// Act
+		todo, err := svc.CreateTodo("dummy title", "dummy desc")
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotZero(t, todo.ID)
+		assert.Equal(t, "dummy title", todo.Title)
+		assert.False(t, todo.Completed)
+	})
+
+	t.Run("Create Todo Trims Title", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+
+		// Act
+		todo, err := svc.CreateTodo("  dummy title  ", "")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "dummy title", todo.Title)
+	})
+
+	t.Run("Create Todo Rejects Invalid Fields Without Storing", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+
+		// Act
+		_, err := svc.CreateTodo(" ", strings.Repeat("a", model.MaxTodoDescriptionLength+1))
+
+		// Assert
+		var validationErrors validation.Errors
+		require.ErrorAs(t, err, &validationErrors)
+		fields := []string{}
+		for _, fieldErr := range validationErrors {
+			fields = append(fields, fieldErr.Field)
+		}
+		assert.ElementsMatch(t, []string{"title", "description"}, fields)
+
+		page, err := svc.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, page.Todos)
+	})
+
+	t.Run("Replace Todo Rejects Invalid Title", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		todo, err := svc.CreateTodo("title", "")
+		require.NoError(t, err)
+
+		// Act
+		_, err = svc.ReplaceTodo(int(todo.ID), nil, "tab\there", "", false)
+
+		// Assert
+		var validationErrors validation.Errors
+		require.ErrorAs(t, err, &validationErrors)
+		stored, err := svc.GetTodoById(int(todo.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "title", stored.Title)
+	})
+
+	t.Run("Get All Todos Walks Pages With Cursor", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		for _, title := range []string{"c", "a", "e", "b", "d"} {
+			_, err := svc.CreateTodo(title, "")
+			require.NoError(t, err)
+		}
+		query := model.TodoListQuery{Limit: 2, Sort: model.SortTitle, Order: model.OrderAsc}
+
+		// Act
+		var titles []string
+		for {
+			page, err := svc.GetAllTodos(query)
+			require.NoError(t, err)
+			for _, todo := range page.Todos {
+				titles = append(titles, todo.Title)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+
+		// Assert
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}, titles)
+	})
+
+	t.Run("Get All Todos Filters By Completed", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		done, err := svc.CreateTodo("done", "")
+		require.NoError(t, err)
+		_, err = svc.CreateTodo("not done", "")
+		require.NoError(t, err)
+		_, err = svc.ReplaceTodo(int(done.ID), nil, "done", "", true)
+		require.NoError(t, err)
+		completed := true
+
+		// Act
+		page, err := svc.GetAllTodos(model.TodoListQuery{Completed: &completed})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, page.Todos, 1)
+		assert.Equal(t, done.ID, page.Todos[0].ID)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Search Todos Orders By Relevance With Highlights", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		for _, todo := range [][2]string{
+			{"우유 사기", "마트에서 우유 두 통"},
+			{"빵 사기", "우유 식빵"},
+			{"주말 장보기", "달걀"},
+			{"우유 우유 우유", ""},
+		} {
+			_, err := svc.CreateTodo(todo[0], todo[1])
+			require.NoError(t, err)
+		}
+		query := model.TodoListQuery{Q: "우유", Limit: 2}
+
+		// Act
+		var pages []*model.TodoPage
+		for {
+			page, err := svc.GetAllTodos(query)
+			require.NoError(t, err)
+			pages = append(pages, page)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		_, reusedErr := svc.GetAllTodos(model.TodoListQuery{Q: "빵", Cursor: pages[0].NextCursor})
+
+		// Assert
+		var titles []string
+		for _, page := range pages {
+			for _, todo := range page.Todos {
+				titles = append(titles, todo.Title)
+			}
+		}
+		assert.Equal(t, []string{"우유 우유 우유", "우유 사기", "빵 사기"}, titles)
+
+		first := pages[0].Todos[1]
+		require.NotNil(t, first.Match)
+		assert.Positive(t, first.Match.Score)
+		assert.Equal(t, map[string]string{
+			"title":       "<mark>우유</mark> 사기",
+			"description": "마트에서 <mark>우유</mark> 두 통",
+		}, first.Match.Highlights)
+		assert.NotContains(t, pages[1].Todos[0].Match.Highlights, "title", "fields without a match are omitted")
+		assert.ErrorIs(t, reusedErr, ErrInvalidListQuery, "cursors are bound to their search query")
+	})
+
+	t.Run("Get All Todos Filters By Due Date", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		now := time.Now()
+		yesterday, tomorrow := now.Add(-24*time.Hour), now.Add(24*time.Hour)
+		overdue, err := svc.CreateTodo("overdue", "", WithSchedule(&yesterday, nil))
+		require.NoError(t, err)
+		done, err := svc.CreateTodo("done late", "", WithSchedule(&yesterday, nil))
+		require.NoError(t, err)
+		_, err = svc.ReplaceTodo(int(done.ID), nil, "done late", "", true, WithSchedule(&yesterday, nil))
+		require.NoError(t, err)
+		upcoming, err := svc.CreateTodo("upcoming", "", WithSchedule(&tomorrow, nil))
+		require.NoError(t, err)
+		undated, err := svc.CreateTodo("undated", "")
+		require.NoError(t, err)
+		isOverdue, notOverdue := true, false
+
+		ids := func(query model.TodoListQuery) []int64 {
+			page, err := svc.GetAllTodos(query)
+			require.NoError(t, err)
+			var ids []int64
+			for _, todo := range page.Todos {
+				ids = append(ids, todo.ID)
+			}
+			return ids
+		}
+
+		// Act & Assert
+		assert.Equal(t, []int64{overdue.ID}, ids(model.TodoListQuery{Overdue: &isOverdue}))
+		assert.ElementsMatch(t, []int64{done.ID, upcoming.ID, undated.ID}, ids(model.TodoListQuery{Overdue: &notOverdue}))
+		assert.ElementsMatch(t, []int64{overdue.ID, done.ID}, ids(model.TodoListQuery{DueBefore: &now}))
+		assert.Equal(t, []int64{upcoming.ID}, ids(model.TodoListQuery{DueAfter: &now}))
+	})
+
+	t.Run("Get All Todos Sorts By Priority", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		for _, priority := range []model.Priority{model.PriorityHigh, "", model.PriorityUrgent, model.PriorityLow} {
+			_, err := svc.CreateTodo(string(priority)+" todo", "", WithPriority(priority))
+			require.NoError(t, err)
+		}
+		query := model.TodoListQuery{Sort: model.SortPriority, Limit: 3}
+
+		// Act
+		first, err := svc.GetAllTodos(query)
+		require.NoError(t, err)
+		query.Cursor = first.NextCursor
+		second, err := svc.GetAllTodos(query)
+		require.NoError(t, err)
+
+		// Assert
+		var priorities []model.Priority
+		for _, todo := range append(first.Todos, second.Todos...) {
+			priorities = append(priorities, todo.Priority)
+		}
+		assert.Equal(t, []model.Priority{
+			model.PriorityUrgent, model.PriorityHigh, model.PriorityNormal, model.PriorityLow,
+		}, priorities, "priority defaults to normal and sorts highest first")
+	})
+
+	t.Run("Repository Stores Zero-Value Priority As Normal", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+
+		// Act
+		created, createErr := repo.Create(&model.Todo{Title: "no priority"})
+		require.NoError(t, createErr)
+		created.Priority = ""
+		updated, updateErr := repo.Update(created)
+		require.NoError(t, updateErr)
+		_, invalidErr := repo.Create(&model.Todo{Title: "unknown", Priority: "someday"})
+		stored, err := svc.GetTodoById(int(created.ID))
+
+		// Assert
+		assert.Equal(t, model.PriorityNormal, updated.Priority)
+		assert.ErrorIs(t, invalidErr, repository.ErrInvalidPriority)
+		require.NoError(t, err)
+		assert.Equal(t, model.PriorityNormal, stored.Priority)
+	})
+
+	t.Run("Move Todo Reorders Only The Moved Todo", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		var todos []*model.Todo
+		for _, title := range []string{"a", "b", "c", "d"} {
+			todo, err := svc.CreateTodo(title, "")
+			require.NoError(t, err)
+			todos = append(todos, todo)
+		}
+		titles := func() []string {
+			page, err := svc.GetAllTodos(model.TodoListQuery{Sort: model.SortPosition})
+			require.NoError(t, err)
+			var titles []string
+			for _, todo := range page.Todos {
+				titles = append(titles, todo.Title)
+			}
+			return titles
+		}
+		require.Equal(t, []string{"a", "b", "c", "d"}, titles(), "new todos are appended")
+
+		// Act
+		moved, err := svc.MoveTodo(int(todos[3].ID), nil, 0, int(todos[0].ID))
+		require.NoError(t, err)
+		_, err = svc.MoveTodo(int(todos[0].ID), nil, int(todos[2].ID), 0)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, []string{"d", "b", "c", "a"}, titles())
+		assert.Equal(t, todos[3].Version+1, moved.Version)
+		unchanged, err := svc.GetTodoById(int(todos[1].ID))
+		require.NoError(t, err)
+		assert.Equal(t, todos[1].Position, unchanged.Position)
+	})
+
+	t.Run("Positions Are Ordered Per Owner", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		alice, bob := NewTodoService(repo).ForUser(1), NewTodoService(repo).ForUser(2)
+		first, err := alice.CreateTodo("alice 1", "")
+		require.NoError(t, err)
+		other, err := bob.CreateTodo("bob 1", "")
+		require.NoError(t, err)
+		second, err := alice.CreateTodo("alice 2", "")
+		require.NoError(t, err)
+
+		// Act
+		moved, err := alice.MoveTodo(int(first.ID), nil, int(second.ID), 0)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, first.Position, other.Position, "each owner has its own position sequence")
+		assert.Greater(t, moved.Position, second.Position)
+		page, err := alice.GetAllTodos(model.TodoListQuery{Sort: model.SortPosition})
+		require.NoError(t, err)
+		require.Len(t, page.Todos, 2)
+		assert.Equal(t, []int64{second.ID, first.ID}, []int64{page.Todos[0].ID, page.Todos[1].ID})
+	})
+
+	t.Run("Move Todo Rejects Invalid Anchors", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		todo, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		other, err := svc.CreateTodo("other", "")
+		require.NoError(t, err)
+		id := int(todo.ID)
+
+		invalidMoves := map[string][2]int{
+			"no anchor":      {0, 0},
+			"both anchors":   {int(other.ID), int(other.ID)},
+			"itself":         {id, 0},
+			"missing anchor": {0, 999},
+		}
+		for name, anchors := range invalidMoves {
+			t.Run(name, func(t *testing.T) {
+				// Act
+				_, err := svc.MoveTodo(id, nil, anchors[0], anchors[1])
+
+				// Assert
+				assert.ErrorIs(t, err, ErrInvalidMove)
+			})
+		}
+	})
+
+	t.Run("Get All Todos Rejects Invalid Query", func(t *testing.T) {
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		epoch := time.Unix(0, 0)
+
+		invalidQueries := map[string]model.TodoListQuery{
+			"limit too large":     {Limit: model.MaxTodoListLimit + 1},
+			"unsupported sort":    {Sort: "id"},
+			"unsupported order":   {Order: "up"},
+			"malformed cursor":    {Cursor: "not-a-cursor"},
+			"relevance without q": {Sort: model.SortRelevance},
+			"q without terms":     {Q: `+"" -`},
+			"q too long":          {Q: strings.Repeat("a", search.MaxQueryLength+1)},
+			"empty due range":     {DueBefore: &epoch, DueAfter: &epoch},
+		}
+		for name, query := range invalidQueries {
+			t.Run(name, func(t *testing.T) {
+				_, err := svc.GetAllTodos(query)
+				assert.ErrorIs(t, err, ErrInvalidListQuery)
+			})
+		}
+	})
+
+	t.Run("Get Not Exist Todo Returns ErrTodoNotFound", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+
+		// Act
+		_, err := svc.GetTodoById(42)
+
+		// Assert
+		assert.Equal(t, ErrTodoNotFound, err)
+	})
+
+	t.Run("Replace Todo Replaces All Fields", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		created, err := svc.CreateTodo("dummy title", "dummy desc")
+		require.NoError(t, err)
+
+		// Act
+		updated, err := svc.ReplaceTodo(int(created.ID), nil, "renamed", "", true)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "renamed", updated.Title)
+		assert.Empty(t, updated.Description)
+		assert.True(t, updated.Completed)
+	})
+
+	t.Run("Patch Priority", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		created, err := svc.CreateTodo("dummy title", "", WithPriority(model.PriorityHigh))
+		require.NoError(t, err)
+
+		// Act
+		_, invalidErr := svc.PatchTodo(int(created.ID), nil, mustMergePatch(t, `{"priority": "someday"}`))
+		cleared, err := svc.PatchTodo(int(created.ID), nil, mustMergePatch(t, `{"priority": null}`))
+
+		// Assert
+		var validationErrors validation.Errors
+		require.ErrorAs(t, invalidErr, &validationErrors)
+		assert.Equal(t, "priority", validationErrors[0].Field)
+		require.NoError(t, err)
+		assert.Equal(t, model.PriorityNormal, cleared.Priority, "removed priority falls back to normal")
+	})
+
+	t.Run("Schedule Is Validated And Replaced", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		due := time.Now().Add(time.Hour)
+		early, late := due.Add(-time.Minute), due.Add(time.Minute)
+		created, err := svc.CreateTodo("dummy title", "", WithSchedule(&due, &early))
+		require.NoError(t, err)
+
+		// Act
+		_, lateErr := svc.CreateTodo("dummy title", "", WithSchedule(&due, &late))
+		replaced, err := svc.ReplaceTodo(int(created.ID), nil, "dummy title", "", false)
+
+		// Assert
+		var validationErrors validation.Errors
+		require.ErrorAs(t, lateErr, &validationErrors)
+		assert.Equal(t, "remind_at", validationErrors[0].Field)
+		require.NoError(t, err)
+		assert.Nil(t, replaced.DueAt, "replace clears fields that are not given")
+		assert.Nil(t, replaced.RemindAt)
+	})
+
+	t.Run("Merge Patch Only Changes Given Fields And Clears Null", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		created, err := svc.CreateTodo("dummy title", "dummy desc")
+		require.NoError(t, err)
+		p, err := patch.DecodeMergePatch([]byte(`{"completed": true, "description": null}`))
+		require.NoError(t, err)
+
+		// Act
+		updated, err := svc.PatchTodo(int(created.ID), nil, p)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "dummy title", updated.Title)
+		assert.Empty(t, updated.Description)
+		assert.True(t, updated.Completed)
+	})
+
+	t.Run("JSON Patch Applies Operations In Order", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		created, err := svc.CreateTodo("dummy title", "dummy desc")
+		require.NoError(t, err)
+		p, err := patch.DecodeJSONPatch([]byte(`[
+			{"op": "test", "path": "/completed", "value": false},
+			{"op": "copy", "from": "/title", "path": "/description"},
+			{"op": "replace", "path": "/title", "value": "renamed"}
+		]`))
+		require.NoError(t, err)
+
+		// Act
+		updated, err := svc.PatchTodo(int(created.ID), nil, p)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "renamed", updated.Title)
+		assert.Equal(t, "dummy title", updated.Description)
+	})
+
+	t.Run("Patch Todo Rejects Invalid Patches", func(t *testing.T) {
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		created, err := svc.CreateTodo("dummy title", "dummy desc")
+		require.NoError(t, err)
+
+		patches := map[string]struct {
+			patch patch.Patch
+			err   error
+		}{
+			"failed test":     {mustJSONPatch(t, `[{"op": "test", "path": "/title", "value": "other"}]`), ErrPatchConflict},
+			"immutable field": {mustJSONPatch(t, `[{"op": "replace", "path": "/id", "value": 2}]`), ErrInvalidPatch},
+			"unknown field":   {mustMergePatch(t, `{"owner": "someone"}`), ErrInvalidPatch},
+			"wrong type":      {mustMergePatch(t, `{"completed": "yes"}`), ErrInvalidPatch},
+		}
+		for name, tc := range patches {
+			t.Run(name, func(t *testing.T) {
+				_, err := svc.PatchTodo(int(created.ID), nil, tc.patch)
+				assert.ErrorIs(t, err, tc.err)
+			})
+		}
+
+		stored, err := svc.GetTodoById(int(created.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "dummy title", stored.Title)
+	})
+
+	t.Run("Update Records Completion Event Only On Transition", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+		created, err := svc.CreateTodo("dummy title", "dummy desc")
+		require.NoError(t, err)
+
+		// Act
+		_, err = svc.ReplaceTodo(int(created.ID), nil, "dummy title", "dummy desc", true)
+		require.NoError(t, err)
+		_, err = svc.ReplaceTodo(int(created.ID), nil, "renamed", "dummy desc", true)
+		require.NoError(t, err)
+		_, err = svc.PatchTodo(int(created.ID), nil, mustMergePatch(t, `{"completed": false}`))
+		require.NoError(t, err)
+
+		// Assert
+		events, err := repo.ClaimPending(100, time.Minute)
+		require.NoError(t, err)
+		var eventTypes []string
+		for _, event := range events {
+			eventTypes = append(eventTypes, event.EventType)
+		}
+		assert.Equal(t, []string{
+			model.EventTodoCreated,
+			model.EventTodoUpdated,
+			model.EventTodoCompleted,
+			model.EventTodoUpdated,
+			model.EventTodoUpdated,
+		}, eventTypes)
+	})
+
+	t.Run("Update Increments Version", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		created, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+
+		// Act
+		updated, err := svc.ReplaceTodo(int(created.ID), nil, "renamed", "", false)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), created.Version)
+		assert.Equal(t, int64(2), updated.Version)
+	})
+
+	t.Run("Failed Precondition Leaves Todo Unchanged", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		created, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		staleVersion := func(current *model.Todo) bool { return current.Version == 0 }
+
+		// Act
+		_, updateErr := svc.ReplaceTodo(int(created.ID), staleVersion, "renamed", "", false)
+		deleteErr := svc.DeleteTodo(int(created.ID), staleVersion)
+
+		// Assert
+		assert.ErrorIs(t, updateErr, ErrPreconditionFailed)
+		assert.ErrorIs(t, deleteErr, ErrPreconditionFailed)
+		stored, err := svc.GetTodoById(int(created.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "dummy title", stored.Title)
+	})
+
+	t.Run("Concurrent Modification Between Read And Write", func(t *testing.T) {
+		// Arrange
+		repo := &racingStore{MemoryTodoRepository: repository.NewMemoryTodoRepository()}
+		svc := NewTodoService(repo)
+		created, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		repo.races = 1
+
+		// Act
+		conditional, conditionalErr := svc.PatchTodo(int(created.ID), func(*model.Todo) bool { return true },
+			mustMergePatch(t, `{"completed": true}`))
+		repo.races = 1
+		unconditional, unconditionalErr := svc.PatchTodo(int(created.ID), nil, mustMergePatch(t, `{"completed": true}`))
+
+		// Assert
+		assert.Nil(t, conditional)
+		assert.ErrorIs(t, conditionalErr, ErrPreconditionFailed)
+		require.NoError(t, unconditionalErr, "unconditional updates are retried on the latest version")
+		assert.True(t, unconditional.Completed)
+		assert.Equal(t, "raced", unconditional.Description)
+	})
+
+	t.Run("Delete Todo", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		created, err := svc.CreateTodo("dummy title", "dummy desc")
+		require.NoError(t, err)
+
+		// Act
+		err = svc.DeleteTodo(int(created.ID), nil)
+
+		// Assert
+		require.NoError(t, err)
+		_, err = svc.GetTodoById(int(created.ID))
+		assert.Equal(t, ErrTodoNotFound, err)
+	})
+
+	t.Run("Deleted Todo Moves To Trash", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		kept, err := svc.CreateTodo("kept", "")
+		require.NoError(t, err)
+		trashed, err := svc.CreateTodo("trashed", "")
+		require.NoError(t, err)
+
+		// Act
+		err = svc.DeleteTodo(int(trashed.ID), nil)
+
+		// Assert
+		require.NoError(t, err)
+		page, err := svc.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		require.Len(t, page.Todos, 1)
+		assert.Equal(t, kept.ID, page.Todos[0].ID)
+
+		trash, err := svc.GetTrash(model.TodoListQuery{})
+		require.NoError(t, err)
+		require.Len(t, trash.Todos, 1)
+		assert.Equal(t, trashed.ID, trash.Todos[0].ID)
+		assert.NotNil(t, trash.Todos[0].DeletedAt)
+		assert.Equal(t, trashed.Version+1, trash.Todos[0].Version)
+
+		_, err = svc.ReplaceTodo(int(trashed.ID), nil, "edited", "", false)
+		assert.ErrorIs(t, err, ErrTodoNotFound, "trashed todos cannot be modified")
+	})
+
+	t.Run("Get Trash Sorts By Deleted At", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		ids := []int64{}
+		for i := 0; i < 3; i++ {
+			todo, err := svc.CreateTodo("todo "+strconv.Itoa(i), "")
+			require.NoError(t, err)
+			ids = append(ids, todo.ID)
+		}
+		// 생성 순서와 다른 순서로 휴지통에 넣는다.
+		for _, id := range []int64{ids[1], ids[2], ids[0]} {
+			require.NoError(t, svc.DeleteTodo(int(id), nil))
+		}
+
+		// Act
+		first, err := svc.GetTrash(model.TodoListQuery{Limit: 2})
+		require.NoError(t, err)
+		second, err := svc.GetTrash(model.TodoListQuery{Limit: 2, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		_, sortErr := svc.GetAllTodos(model.TodoListQuery{Sort: model.SortDeletedAt})
+
+		// Assert
+		got := []int64{}
+		for _, todo := range append(first.Todos, second.Todos...) {
+			got = append(got, todo.ID)
+		}
+		assert.Equal(t, []int64{ids[0], ids[2], ids[1]}, got)
+		assert.Empty(t, second.NextCursor)
+		assert.ErrorIs(t, sortErr, ErrInvalidListQuery, "deleted_at sort is only for trash")
+	})
+
+	t.Run("Restore Todo", func(t *testing.T) {
+		// Arrange
+		svc := NewTodoService(repository.NewMemoryTodoRepository())
+		created, err := svc.CreateTodo("dummy title", "")
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteTodo(int(created.ID), nil))
+
+		// Act
+		stale, staleErr := svc.RestoreTodo(int(created.ID), func(current *model.Todo) bool {
+			return current.Version == created.Version
+		})
+		restored, err := svc.RestoreTodo(int(created.ID), nil)
+		_, againErr := svc.RestoreTodo(int(created.ID), nil)
+
+		// Assert
+		assert.Nil(t, stale)
+		assert.ErrorIs(t, staleErr, ErrPreconditionFailed)
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		assert.Equal(t, created.Version+2, restored.Version)
+		assert.ErrorIs(t, againErr, ErrTodoNotFound, "only trashed todos can be restored")
+
+		stored, err := svc.GetTodoById(int(created.ID))
+		require.NoError(t, err)
+		assert.Equal(t, "dummy title", stored.Title)
+		trash, err := svc.GetTrash(model.TodoListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, trash.Todos)
+	})
+
+	t.Run("Todos Are Scoped To Their Owner", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		alice := NewTodoService(repo).ForUser(1)
+		bob := NewTodoService(repo).ForUser(2)
+		due := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+		created, err := alice.CreateTodo("alice's todo", "", WithSchedule(&due, nil), WithRecurrence("FREQ=DAILY"))
+		require.NoError(t, err)
+		_, _, err = alice.CreateItem(int(created.ID), nil, "step", false)
+		require.NoError(t, err)
+
+		// Act
+		_, getErr := bob.GetTodoById(int(created.ID))
+		bobPage, err := bob.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		_, patchErr := bob.PatchTodo(int(created.ID), nil, mustMergePatch(t, `{"title": "stolen"}`))
+		deleteErr := bob.DeleteTodo(int(created.ID), nil)
+		_, itemsErr := bob.GetItems(int(created.ID))
+		_, err = alice.PatchTodo(int(created.ID), nil, mustMergePatch(t, `{"completed": true}`))
+		require.NoError(t, err)
+
+		// Assert
+		assert.ErrorIs(t, getErr, ErrTodoNotFound)
+		assert.Empty(t, bobPage.Todos)
+		assert.ErrorIs(t, patchErr, ErrTodoNotFound)
+		assert.ErrorIs(t, deleteErr, ErrTodoNotFound)
+		assert.ErrorIs(t, itemsErr, ErrTodoNotFound)
+
+		alicePage, err := alice.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		assert.Len(t, alicePage.Todos, 2, "the next occurrence belongs to the same owner")
+		bobPage, err = bob.GetAllTodos(model.TodoListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, bobPage.Todos)
+	})
+}
+
+func mustMergePatch(t *testing.T, data string) patch.Patch {
+	t.Helper()
+	p, err := patch.DecodeMergePatch([]byte(data))
+	require.NoError(t, err)
+	return p
+}
+
+func mustJSONPatch(t *testing.T, data string) patch.Patch {
+	t.Helper()
+	p, err := patch.DecodeJSONPatch([]byte(data))
+	require.NoError(t, err)
+	return p
+}
+
+// racingStore 는 Update 직전에 다른 요청이 먼저 todo 를 변경한 상황을 races 횟수만큼 흉내 낸다.
+type racingStore struct {
+	*repository.MemoryTodoRepository
+	races int
+}
+
+func (r *racingStore) Update(todo *model.Todo, events ...string) (*model.Todo, error) {
+	if r.races > 0 {
+		r.races--
+		current, err := r.MemoryTodoRepository.GetTodo(int(todo.ID))
+		if err != nil {
+			return nil, err
+		}
+		current.Description = "raced"
+		if _, err := r.MemoryTodoRepository.Update(current); err != nil {
+			return nil, err
+		}
+	}
+	return r.MemoryTodoRepository.Update(todo, events...)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"integration-test-example/internal/repository"
+	"log"
+	"time"
+)
+
+const (
+	defaultTrashRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval  = time.Hour
+	defaultPurgeBatchSize = 500
+)
+
+// TrashPurger 는 휴지통에 retention 이상 머문 todo 를 주기적으로 영구 삭제한다.
+type TrashPurger struct {
+	store     repository.TrashStore
+	retention time.Duration
+	interval  time.Duration
+	batchSize int
+}
+
+func NewTrashPurger(store repository.TrashStore, retention, interval time.Duration, batchSize int) *TrashPurger {
+	if retention <= 0 {
+		retention = defaultTrashRetention
+	}
+	if interval <= 0 {
+		interval = defaultPurgeInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatchSize
+	}
+
+	return &TrashPurger{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run 은 ctx 가 취소될 때까지 interval 마다 휴지통을 정리한다.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := p.Purge(time.Now()); err != nil {
+			log.Printf("trash purger: %v", err)
+		} else if purged > 0 {
+			log.Printf("trash purger: purged %d todos", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge 는 now 기준으로 retention 이 지난 todo 가 없을 때까지 배치 단위로 영구 삭제하고 삭제된 건수를 반환한다.
+// 한 번에 많은 행을 잠그지 않도록 batchSize 건씩 나누어 삭제한다.
+func (p *TrashPurger) Purge(now time.Time) (int64, error) {
+	before := now.Add(-p.retention)
+
+	var total int64
+	for {
+		purged, err := p.store.Purge(before, p.batchSize)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+		if purged < int64(p.batchSize) {
+			return total, nil
+		}
+	}
+}
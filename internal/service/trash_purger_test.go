@@ -0,0 +1,45 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestTrashPurger(t *testing.T) {
+	t.Run("Purge Removes Only Todos Past Retention", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewTodoService(repo)
+		ids := []int{}
+		for _, title := range []string{"first", "second", "active"} {
+			todo, err := svc.CreateTodo(title, "")
+			require.NoError(t, err)
+			ids = append(ids, int(todo.ID))
+		}
+		require.NoError(t, svc.DeleteTodo(ids[0], nil))
+		require.NoError(t, svc.DeleteTodo(ids[1], nil))
+		purger := NewTrashPurger(repo, time.Hour, time.Minute, 1)
+
+		// Act
+		early, earlyErr := purger.Purge(time.Now())
+		purged, err := purger.Purge(time.Now().Add(time.Hour + time.Second))
+
+		// Assert
+		require.NoError(t, earlyErr)
+		assert.Zero(t, early, "todos within retention are kept")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), purged, "purges in batches until nothing is left")
+
+		trash, err := svc.GetTrash(model.TodoListQuery{})
+		require.NoError(t, err)
+		assert.Empty(t, trash.Todos)
+		_, err = svc.RestoreTodo(ids[0], nil)
+		assert.ErrorIs(t, err, ErrTodoNotFound)
+		_, err = svc.GetTodoById(ids[2])
+		assert.NoError(t, err, "active todos are never purged")
+	})
+}
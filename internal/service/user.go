@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"integration-test-example/internal/model"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"strings"
+)
+
+var (
+	// ErrEmailTaken 은 같은 이메일로 이미 가입한 사용자가 있을 때 반환된다.
+	ErrEmailTaken = errors.New("email already registered")
+	// ErrInvalidCredentials 는 이메일이 없거나 비밀번호가 틀릴 때 반환된다. 어느 쪽인지는 구분하지 않는다.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+// dummyPasswordHash 는 없는 이메일로 로그인할 때도 비밀번호를 비교해, 응답 시간으로 가입 여부를 알 수 없게 하는 해시
+const dummyPasswordHash = "$2a$10$dUY5NykBVLqvan9VMcv7JuEdSygJraAI.XBACecrRnc4eAvU1N8W."
+
+// UserService 는 가입과 로그인을 처리한다. 비밀번호는 bcrypt 해시로만 저장한다.
+// 토큰 발급은 handler 가 인증 미들웨어로 한다.
+type UserService struct {
+	userRepository repository.UserStore
+}
+
+func NewUserService(repo repository.UserStore) *UserService {
+	return &UserService{userRepository: repo}
+}
+
+// Register 는 사용자를 만든다. 이메일은 앞뒤 공백을 제거하고 소문자로 저장한다.
+func (s UserService) Register(email, password string) (*model.User, error) {
+	user := &model.User{Email: normalizeEmail(email)}
+
+	var errs validation.Errors
+	if err := validation.Struct(user); err != nil && !errors.As(err, &errs) {
+		return nil, err
+	}
+	// bcrypt 는 72 byte 까지만 해시에 반영하므로 문자 수가 아닌 byte 수로 제한한다.
+	if n := len(password); n < model.MinPasswordLength || n > model.MaxPasswordLength {
+		errs = append(errs, validation.FieldError{
+			Field:   "password",
+			Code:    "length",
+			Message: fmt.Sprintf("must be between %d and %d bytes", model.MinPasswordLength, model.MaxPasswordLength),
+		})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = string(hash)
+
+	created, err := s.userRepository.CreateUser(user)
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+// Login 은 이메일과 비밀번호를 확인하고 사용자를 반환한다. 확인에 실패하면 ErrInvalidCredentials 를 반환한다.
+func (s UserService) Login(email, password string) (*model.User, error) {
+	user, err := s.userRepository.GetUserByEmail(normalizeEmail(email))
+	if err != nil {
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, err
+		}
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
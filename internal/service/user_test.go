@@ -0,0 +1,67 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/repository"
+	"integration-test-example/internal/validation"
+	"testing"
+)
+
+func TestUserService(t *testing.T) {
+	t.Run("Register Hashes Password And Normalizes Email", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryTodoRepository()
+		svc := NewUserService(repo)
+
+		// Act
+		user, err := svc.Register("  Alice@Example.com ", "correct horse")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", user.Email)
+		stored, err := repo.GetUserByEmail("alice@example.com")
+		require.NoError(t, err)
+		assert.NotEqual(t, "correct horse", stored.PasswordHash)
+		assert.NotEmpty(t, stored.PasswordHash)
+	})
+
+	t.Run("Register Rejects Invalid Fields And Duplicate Email", func(t *testing.T) {
+		// Arrange
+		svc := NewUserService(repository.NewMemoryTodoRepository())
+		_, err := svc.Register("alice@example.com", "correct horse")
+		require.NoError(t, err)
+
+		// Act
+		_, invalidErr := svc.Register("not an email", "short")
+		_, duplicateErr := svc.Register("ALICE@example.com", "another password")
+
+		// Assert
+		var errs validation.Errors
+		require.ErrorAs(t, invalidErr, &errs)
+		fields := map[string]string{}
+		for _, fieldErr := range errs {
+			fields[fieldErr.Field] = fieldErr.Code
+		}
+		assert.Equal(t, map[string]string{"email": "email", "password": "length"}, fields)
+		assert.ErrorIs(t, duplicateErr, ErrEmailTaken)
+	})
+
+	t.Run("Login Checks Password", func(t *testing.T) {
+		// Arrange
+		svc := NewUserService(repository.NewMemoryTodoRepository())
+		registered, err := svc.Register("alice@example.com", "correct horse")
+		require.NoError(t, err)
+
+		// Act
+		user, err := svc.Login("Alice@example.com", "correct horse")
+		_, wrongPasswordErr := svc.Login("alice@example.com", "battery staple")
+		_, unknownEmailErr := svc.Login("bob@example.com", "correct horse")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, registered.ID, user.ID)
+		assert.ErrorIs(t, wrongPasswordErr, ErrInvalidCredentials)
+		assert.ErrorIs(t, unknownEmailErr, ErrInvalidCredentials)
+	})
+}
@@ -0,0 +1,196 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"integration-test-example/internal/recurrence"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FieldError represents a validation failure on a single field
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors 는 한 번의 검증에서 발견된 모든 필드 에러
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + " " + fieldErr.Message
+	}
+	return "validation failed: " + strings.Join(messages, ", ")
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	if err := Register(v); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Register 는 커스텀 규칙과 json 필드 이름 규칙을 v 에 등록한다.
+// gin 의 binding 검증기에도 등록해 handler 와 service 가 같은 규칙을 쓰도록 한다.
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(jsonFieldName)
+
+	rules := map[string]validator.Func{
+		"notblank":   notBlank,
+		"singleline": singleLine,
+		"nocontrol":  noControl,
+		"notafter":   notAfter,
+		"rrule":      rrule,
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Struct 는 s 의 validate 태그를 검사하고, 위반이 있으면 Errors 를 반환한다.
+func Struct(s interface{}) error {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return FromValidator(validationErrors)
+	}
+	return err
+}
+
+// FromValidator 는 validator 에러를 필드 에러 목록으로 변환한다.
+func FromValidator(validationErrors validator.ValidationErrors) Errors {
+	errs := make(Errors, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		errs = append(errs, FieldError{
+			Field:   fieldPath(fieldErr),
+			Code:    fieldErr.Tag(),
+			Message: message(fieldErr),
+		})
+	}
+	return errs
+}
+
+// fieldPath 는 최상위 struct 이름을 뺀 필드 경로 (예: operations[0].title)
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, ok := strings.Cut(fieldErr.Namespace(), ".")
+	if !ok {
+		return fieldErr.Field()
+	}
+	return path
+}
+
+func message(fieldErr validator.FieldError) string {
+	unit := ""
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch fieldErr.Tag() {
+	case "required", "required_unless", "required_if", "required_with":
+		return "is required"
+	case "notblank":
+		return "must not be blank"
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fieldErr.Param(), unit)
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fieldErr.Param(), unit)
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fieldErr.Param(), " ", ", "))
+	case "singleline":
+		return "must be a single line without control characters"
+	case "nocontrol":
+		return "must not contain control characters"
+	case "notafter":
+		return "must not be after " + fieldErr.Param()
+	case "rrule":
+		return "must be a supported RFC 5545 recurrence rule"
+	case "email":
+		return "must be a valid email address"
+	default:
+		return fmt.Sprintf("failed %q validation", fieldErr.Tag())
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// notBlank: 앞뒤 공백을 제거해도 비어 있지 않아야 한다.
+func notBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// singleLine: 줄바꿈, 탭을 포함한 제어 문자를 허용하지 않는다.
+func singleLine(fl validator.FieldLevel) bool {
+	return strings.IndexFunc(fl.Field().String(), unicode.IsControl) < 0
+}
+
+// noControl: 줄바꿈과 탭을 제외한 제어 문자를 허용하지 않는다.
+func noControl(fl validator.FieldLevel) bool {
+	return strings.IndexFunc(fl.Field().String(), func(r rune) bool {
+		return unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t'
+	}) < 0
+}
+
+// notAfter: 시각이 param(json 이름)으로 지정한 같은 struct 의 시각 필드보다 늦지 않아야 한다.
+// param 필드가 nil 이면 비교 대상이 없으므로 통과한다.
+func notAfter(fl validator.FieldLevel) bool {
+	value, ok := timeValue(fl.Field())
+	if !ok {
+		return true
+	}
+
+	parent := reflect.Indirect(fl.Parent())
+	for i := 0; i < parent.NumField(); i++ {
+		if jsonFieldName(parent.Type().Field(i)) != fl.Param() {
+			continue
+		}
+		other, ok := timeValue(parent.Field(i))
+		return !ok || !value.After(other)
+	}
+	return false
+}
+
+// rrule: recurrence 패키지가 지원하는 RRULE 이어야 한다.
+func rrule(fl validator.FieldLevel) bool {
+	_, err := recurrence.Parse(fl.Field().String())
+	return err == nil
+}
+
+// timeValue 는 time.Time 또는 nil 이 아닌 *time.Time 값을 꺼낸다.
+func timeValue(v reflect.Value) (time.Time, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return time.Time{}, false
+		}
+		v = v.Elem()
+	}
+	t, ok := v.Interface().(time.Time)
+	return t, ok
+}
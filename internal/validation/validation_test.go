@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type sample struct {
+	Title string `json:"title" validate:"required,notblank,max=5,singleline"`
+	Body  string `json:"body" validate:"nocontrol"`
+}
+
+type schedule struct {
+	DueAt    *time.Time `json:"due_at"`
+	RemindAt *time.Time `json:"remind_at" validate:"omitempty,notafter=due_at"`
+}
+
+type recurring struct {
+	DueAt *time.Time `json:"due_at" validate:"required_with=RRule"`
+	RRule string     `json:"rrule" validate:"omitempty,rrule"`
+}
+
+func TestStruct(t *testing.T) {
+	t.Run("Valid Struct Returns Nil", func(t *testing.T) {
+		// Act
+		err := Struct(sample{Title: "héllo", Body: "line\nnext\ttab"})
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Reports Every Violation With Json Field Names", func(t *testing.T) {
+		// Act
+		err := Struct(sample{Title: "\t\t", Body: "null\x00"})
+
+		// Assert
+		var errs Errors
+		require.ErrorAs(t, err, &errs)
+		codes := map[string]string{}
+		for _, fieldErr := range errs {
+			codes[fieldErr.Field] = fieldErr.Code
+			assert.NotEmpty(t, fieldErr.Message)
+		}
+		assert.Equal(t, map[string]string{"title": "notblank", "body": "nocontrol"}, codes)
+	})
+
+	t.Run("Max Counts Characters Not Bytes", func(t *testing.T) {
+		// Act
+		err := Struct(sample{Title: "한글다섯자"})
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Not After Compares With Named Field", func(t *testing.T) {
+		// Arrange
+		due := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+		before, after := due.Add(-time.Hour), due.Add(time.Hour)
+
+		// Act & Assert
+		assert.NoError(t, Struct(schedule{DueAt: &due, RemindAt: &before}))
+		assert.NoError(t, Struct(schedule{DueAt: &due, RemindAt: &due}))
+		assert.NoError(t, Struct(schedule{RemindAt: &after}), "nothing to compare without due_at")
+		assert.NoError(t, Struct(schedule{DueAt: &due}))
+
+		err := Struct(schedule{DueAt: &due, RemindAt: &after})
+		var errs Errors
+		require.ErrorAs(t, err, &errs)
+		assert.Equal(t, Errors{{Field: "remind_at", Code: "notafter", Message: "must not be after due_at"}}, errs)
+	})
+	t.Run("RRule Requires Supported Rule And Due At", func(t *testing.T) {
+		// Arrange
+		due := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+		// Act & Assert
+		assert.NoError(t, Struct(recurring{}))
+		assert.NoError(t, Struct(recurring{DueAt: &due, RRule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"}))
+
+		err := Struct(recurring{RRule: "FREQ=HOURLY"})
+		var errs Errors
+		require.ErrorAs(t, err, &errs)
+		assert.Equal(t, Errors{
+			{Field: "due_at", Code: "required_with", Message: "is required"},
+			{Field: "rrule", Code: "rrule", Message: "must be a supported RFC 5545 recurrence rule"},
+		}, errs)
+	})
+}
@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"integration-test-example/pkg/sqs"
+	"log"
+)
+
+// NotificationHandler 는 특정 이벤트 종류의 NotificationMessage 를 처리한다.
+type NotificationHandler interface {
+	Handle(ctx context.Context, message sqs.NotificationMessage) error
+}
+
+// NotificationHandlerFunc 는 함수를 NotificationHandler 로 사용하기 위한 어댑터
+type NotificationHandlerFunc func(ctx context.Context, message sqs.NotificationMessage) error
+
+func (f NotificationHandlerFunc) Handle(ctx context.Context, message sqs.NotificationMessage) error {
+	return f(ctx, message)
+}
+
+// Dispatcher 는 NotificationMessage 를 event_type 별로 등록된 handler 들에게 전달한다.
+type Dispatcher struct {
+	handlers map[string][]NotificationHandler
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		handlers: make(map[string][]NotificationHandler),
+	}
+}
+
+// Register 는 eventType 에 handler 를 추가한다. 같은 eventType 에 여러 handler 를 등록할 수 있다.
+func (d *Dispatcher) Register(eventType string, handler NotificationHandler) {
+	d.handlers[eventType] = append(d.handlers[eventType], handler)
+}
+
+// HandleMessage 는 sqs.Consumer 의 Handler 로 사용된다.
+func (d *Dispatcher) HandleMessage(ctx context.Context, message *sqs.Message) error {
+	var notification sqs.NotificationMessage
+	if err := json.Unmarshal([]byte(message.Body), &notification); err != nil {
+		// 파싱할 수 없는 메시지는 재시도해도 성공할 수 없다.
+		log.Printf("worker: dropping malformed message %s: %v", message.ID, err)
+		return nil
+	}
+
+	handlers, ok := d.handlers[notification.EventType]
+	if !ok {
+		log.Printf("worker: no handler for event type %q (message %s)", notification.EventType, message.ID)
+		return nil
+	}
+
+	for _, handler := range handlers {
+		if err := handler.Handle(ctx, notification); err != nil {
+			return fmt.Errorf("failed to handle %s event for todo %d: %w", notification.EventType, notification.TodoID, err)
+		}
+	}
+
+	return nil
+}
+
+// LogHandler 는 알림 내용을 로그로 출력한다.
+type LogHandler struct{}
+
+func (LogHandler) Handle(_ context.Context, message sqs.NotificationMessage) error {
+	log.Printf("[%s] todo %d: %s", message.EventType, message.TodoID, message.Message)
+	return nil
+}
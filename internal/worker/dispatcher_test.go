@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/pkg/sqs"
+	"testing"
+)
+
+func newMessage(t *testing.T, notification sqs.NotificationMessage) *sqs.Message {
+	body, err := json.Marshal(notification)
+	require.NoError(t, err)
+	return &sqs.Message{ID: "msg", Body: string(body)}
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("Routes Message To Handlers Of Its Event Type", func(t *testing.T) {
+		// Arrange
+		dispatcher := NewDispatcher()
+		var completed, created []int64
+		dispatcher.Register("todo_completed", NotificationHandlerFunc(func(_ context.Context, m sqs.NotificationMessage) error {
+			completed = append(completed, m.TodoID)
+			return nil
+		}))
+		dispatcher.Register("todo_created", NotificationHandlerFunc(func(_ context.Context, m sqs.NotificationMessage) error {
+			created = append(created, m.TodoID)
+			return nil
+		}))
+
+		// Act
+		err := dispatcher.HandleMessage(context.Background(), newMessage(t, sqs.NotificationMessage{EventType: "todo_completed", TodoID: 7}))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []int64{7}, completed)
+		assert.Empty(t, created)
+	})
+
+	t.Run("Handler Error Is Returned For Redelivery", func(t *testing.T) {
+		// Arrange
+		dispatcher := NewDispatcher()
+		dispatcher.Register("todo_completed", NotificationHandlerFunc(func(context.Context, sqs.NotificationMessage) error {
+			return errors.New("downstream unavailable")
+		}))
+
+		// Act
+		err := dispatcher.HandleMessage(context.Background(), newMessage(t, sqs.NotificationMessage{EventType: "todo_completed"}))
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Malformed And Unknown Messages Are Acknowledged", func(t *testing.T) {
+		dispatcher := NewDispatcher()
+
+		assert.NoError(t, dispatcher.HandleMessage(context.Background(), &sqs.Message{ID: "bad", Body: "{"}))
+		assert.NoError(t, dispatcher.HandleMessage(context.Background(), newMessage(t, sqs.NotificationMessage{EventType: "unknown"})))
+	})
+}
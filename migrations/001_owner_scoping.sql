@@ -0,0 +1,32 @@
+-- 사용자 도입 전에 만든 데이터베이스를 init.sql 의 스키마로 옮긴다. (init.sql 은 빈 볼륨에서만 실행된다)
+-- 한 번만 실행한다. 기존 todo, list, 태그는 소유자가 없는 (user_id 가 NULL 인) 채로 남아 어떤 사용자의 API 에도 보이지 않으므로,
+-- 새 서버를 띄우고 넘겨받을 사용자가 가입한 뒤 `server claim-unowned -email EMAIL` 로 그 사용자에게 넘긴다.
+
+CREATE TABLE IF NOT EXISTS users (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(254) NOT NULL,
+    password_hash VARCHAR(60) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    UNIQUE INDEX uq_users_email (email)
+);
+
+ALTER TABLE lists
+    ADD COLUMN user_id INT NULL AFTER id,
+    ADD INDEX idx_lists_user (user_id, created_at, id),
+    ADD FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE todos
+    ADD COLUMN user_id INT NULL AFTER completed,
+    -- position 은 사용자마다 따로 정렬된다.
+    DROP INDEX uq_todos_position,
+    ADD UNIQUE INDEX uq_todos_position (user_id, position),
+    ADD INDEX idx_todos_user (user_id, deleted_at, created_at, id),
+    ADD FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE tags
+    ADD COLUMN user_id INT NULL AFTER id,
+    -- 태그 이름은 사용자마다 유일하다.
+    DROP INDEX uq_tags_name,
+    ADD UNIQUE INDEX uq_tags_user_name (user_id, name),
+    ADD FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"integration-test-example/pkg/redis"
+	"integration-test-example/pkg/sqs"
 	"os"
 )
 
@@ -18,22 +19,80 @@ type DatabaseConfig struct {
 	Name     string `json:"name"`
 }
 
+// Store backend 종류
+const (
+	StoreMySQL  = "mysql"
+	StoreMemory = "memory"
+)
+
+type OutboxConfig struct {
+	// PollIntervalMs 는 relay 가 outbox 를 확인하는 주기 (기본 1000ms)
+	PollIntervalMs int `json:"poll_interval_ms"`
+	// BatchSize 는 relay 가 한 번에 가져오는 이벤트 수 (기본 50)
+	BatchSize int `json:"batch_size"`
+	// RetentionHours 는 발행된 이벤트를 outbox 에서 삭제하기 전까지 보관하는 기간 (기본 168시간)
+	RetentionHours int `json:"retention_hours"`
+}
+
+type TrashConfig struct {
+	// RetentionDays 는 휴지통의 todo 를 영구 삭제하기 전까지 보관하는 기간 (기본 30일)
+	RetentionDays int `json:"retention_days"`
+	// PurgeIntervalMinutes 는 purger 가 휴지통을 확인하는 주기 (기본 60분)
+	PurgeIntervalMinutes int `json:"purge_interval_minutes"`
+	// BatchSize 는 purger 가 한 번에 영구 삭제하는 todo 수 (기본 500)
+	BatchSize int `json:"batch_size"`
+}
+
+type ReminderConfig struct {
+	// PollIntervalSeconds 는 scheduler 가 알림 시각이 지난 todo 를 확인하는 주기 (기본 30초)
+	PollIntervalSeconds int `json:"poll_interval_seconds"`
+	// BatchSize 는 scheduler 가 한 번에 처리하는 todo 수 (기본 100)
+	BatchSize int `json:"batch_size"`
+}
+
+// Config 는 API 서버와 worker 가 함께 쓰는 config.json 설정
+// internal 패키지의 middleware, service 설정은 cmd/server 가 Config 를 내장한 구조체로 함께 읽는다.
 type Config struct {
 	Server   ServerConfig   `json:"server"`
+	Store    string         `json:"store"`
 	Database DatabaseConfig `json:"database"`
 	Redis    redis.Config   `json:"redis"`
+	// SQS 가 설정되지 않으면(queue_name 이 비어 있으면) 알림 없이 동작한다.
+	SQS    sqs.SQSConfig `json:"sqs"`
+	Outbox OutboxConfig  `json:"outbox"`
+	Trash  TrashConfig   `json:"trash"`
+	// Reminder 알림 이벤트는 outbox 를 거쳐 SQS 로 발행된다.
+	Reminder ReminderConfig `json:"reminder"`
+	// Worker 는 cmd/worker 의 SQS 소비자 설정
+	Worker sqs.ConsumerConfig `json:"worker"`
 }
 
-func Load(filename string) (*Config, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
+func (c Config) SQSEnabled() bool {
+	return c.SQS.QueueName != ""
+}
+
+// SetDefaults 는 설정되지 않은 값에 기본값을 채운다.
+func (c *Config) SetDefaults() {
+	if c.Store == "" {
+		c.Store = StoreMySQL
 	}
+}
 
+func Load(filename string) (*Config, error) {
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	if err := Decode(filename, &cfg); err != nil {
 		return nil, err
 	}
 
+	cfg.SetDefaults()
 	return &cfg, nil
 }
+
+// Decode 는 filename 의 JSON 설정을 v 로 읽는다. 기본값은 채우지 않는다.
+func Decode(filename string, v interface{}) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
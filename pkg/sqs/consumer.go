@@ -0,0 +1,164 @@
+package sqs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// MessageQueue 는 Consumer 가 사용하는 큐 연산. SQSClient 가 구현한다.
+type MessageQueue interface {
+	ReceiveMessages(ctx context.Context, maxMessages, waitSeconds, visibilityTimeoutSeconds int64) ([]*Message, error)
+	DeleteMessage(ctx context.Context, receiptHandle string) error
+	ChangeMessageVisibility(ctx context.Context, receiptHandle string, visibilityTimeoutSeconds int64) error
+}
+
+var _ MessageQueue = (*SQSClient)(nil)
+
+// Handler 는 수신한 메시지를 처리한다.
+// nil 을 반환하면 메시지가 삭제되고, 에러를 반환하면 visibility timeout 이후 재전달된다.
+type Handler func(ctx context.Context, message *Message) error
+
+type ConsumerConfig struct {
+	// Concurrency 는 동시에 처리할 메시지 수 (기본 4)
+	Concurrency int `json:"concurrency"`
+	// WaitTimeSeconds 는 long polling 대기 시간 (기본 20, 최대 20)
+	WaitTimeSeconds int64 `json:"wait_time_seconds"`
+	// VisibilityTimeoutSeconds 는 수신 후 다른 소비자에게 보이지 않는 시간 (기본 30)
+	// 처리 중에는 주기적으로 연장된다.
+	VisibilityTimeoutSeconds int64 `json:"visibility_timeout_seconds"`
+}
+
+const (
+	defaultConcurrency       = 4
+	defaultWaitTimeSeconds   = 20
+	defaultVisibilityTimeout = 30
+
+	// 한 번의 ReceiveMessage 로 가져올 수 있는 최대 메시지 수 (SQS 제한)
+	maxReceiveMessages = 10
+
+	receiveErrorBackoff = time.Second
+)
+
+type Consumer struct {
+	queue   MessageQueue
+	handler Handler
+	config  ConsumerConfig
+}
+
+func NewConsumer(queue MessageQueue, handler Handler, config ConsumerConfig) *Consumer {
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaultConcurrency
+	}
+	if config.WaitTimeSeconds <= 0 || config.WaitTimeSeconds > defaultWaitTimeSeconds {
+		config.WaitTimeSeconds = defaultWaitTimeSeconds
+	}
+	if config.VisibilityTimeoutSeconds <= 0 {
+		config.VisibilityTimeoutSeconds = defaultVisibilityTimeout
+	}
+
+	return &Consumer{
+		queue:   queue,
+		handler: handler,
+		config:  config,
+	}
+}
+
+// Run 은 ctx 가 취소될 때까지 메시지를 수신해 handler 풀에 분배한다.
+// ctx 가 취소되면 새 메시지 수신을 멈추고, 처리 중인 메시지가 끝날 때까지 기다린 뒤 반환한다.
+func (c *Consumer) Run(ctx context.Context) {
+	messages := make(chan *Message)
+
+	// 종료 신호가 와도 처리 중인 메시지는 끝까지 처리한다.
+	handlerCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for message := range messages {
+				c.process(handlerCtx, message)
+			}
+		}()
+	}
+
+	defer func() {
+		close(messages)
+		wg.Wait()
+	}()
+
+	batchSize := int64(c.config.Concurrency)
+	if batchSize > maxReceiveMessages {
+		batchSize = maxReceiveMessages
+	}
+
+	for ctx.Err() == nil {
+		received, err := c.queue.ReceiveMessages(ctx, batchSize, c.config.WaitTimeSeconds, c.config.VisibilityTimeoutSeconds)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("sqs consumer: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(receiveErrorBackoff):
+			}
+			continue
+		}
+
+		// 이미 수신한 메시지는 종료 중이어도 처리한다.
+		for _, message := range received {
+			messages <- message
+		}
+	}
+}
+
+func (c *Consumer) process(ctx context.Context, message *Message) {
+	stopHeartbeat := c.startHeartbeat(ctx, message)
+	err := c.handler(ctx, message)
+	stopHeartbeat()
+
+	if err != nil {
+		log.Printf("sqs consumer: failed to handle message %s (receive count %d): %v", message.ID, message.ReceiveCount, err)
+		return
+	}
+
+	if err := c.queue.DeleteMessage(ctx, message.ReceiptHandle); err != nil {
+		log.Printf("sqs consumer: %v", err)
+	}
+}
+
+// startHeartbeat 는 handler 가 실행되는 동안 visibility timeout 의 절반마다 timeout 을 연장한다.
+func (c *Consumer) startHeartbeat(ctx context.Context, message *Message) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		interval := time.Duration(c.config.VisibilityTimeoutSeconds) * time.Second / 2
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := c.queue.ChangeMessageVisibility(ctx, message.ReceiptHandle, c.config.VisibilityTimeoutSeconds)
+				if err != nil {
+					log.Printf("sqs consumer: %v", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
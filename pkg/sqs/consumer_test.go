@@ -0,0 +1,101 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeQueue: 준비된 메시지를 한 번만 돌려주고 삭제/연장 호출을 기록하는 테스트 더블
+type fakeQueue struct {
+	mu       sync.Mutex
+	pending  []*Message
+	deleted  []string
+	extended int
+}
+
+func (q *fakeQueue) ReceiveMessages(ctx context.Context, maxMessages, _, _ int64) ([]*Message, error) {
+	q.mu.Lock()
+	if len(q.pending) > 0 {
+		n := int(maxMessages)
+		if n > len(q.pending) {
+			n = len(q.pending)
+		}
+		received := q.pending[:n]
+		q.pending = q.pending[n:]
+		q.mu.Unlock()
+		return received, nil
+	}
+	q.mu.Unlock()
+
+	// long polling 흉내
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (q *fakeQueue) DeleteMessage(_ context.Context, receiptHandle string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.deleted = append(q.deleted, receiptHandle)
+	return nil
+}
+
+func (q *fakeQueue) ChangeMessageVisibility(context.Context, string, int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.extended++
+	return nil
+}
+
+func TestConsumer(t *testing.T) {
+	t.Run("Deletes Only Successfully Handled Messages", func(t *testing.T) {
+		// Arrange
+		queue := &fakeQueue{pending: []*Message{
+			{ID: "1", ReceiptHandle: "ok", Body: "ok"},
+			{ID: "2", ReceiptHandle: "fail", Body: "fail"},
+		}}
+		handled := make(chan string, 2)
+		handler := func(_ context.Context, message *Message) error {
+			handled <- message.Body
+			if message.Body == "fail" {
+				return errors.New("boom")
+			}
+			return nil
+		}
+		consumer := NewConsumer(queue, handler, ConsumerConfig{Concurrency: 2})
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// Act
+		done := make(chan struct{})
+		go func() {
+			consumer.Run(ctx)
+			close(done)
+		}()
+		<-handled
+		<-handled
+		cancel()
+		<-done
+
+		// Assert
+		assert.Equal(t, []string{"ok"}, queue.deleted)
+	})
+
+	t.Run("Extends Visibility While Handling", func(t *testing.T) {
+		// Arrange
+		queue := &fakeQueue{}
+		consumer := NewConsumer(queue, func(context.Context, *Message) error {
+			time.Sleep(1200 * time.Millisecond)
+			return nil
+		}, ConsumerConfig{VisibilityTimeoutSeconds: 1})
+
+		// Act
+		consumer.process(context.Background(), &Message{ID: "1", ReceiptHandle: "slow"})
+
+		// Assert
+		assert.GreaterOrEqual(t, queue.extended, 2)
+		assert.Equal(t, []string{"slow"}, queue.deleted)
+	})
+}
@@ -0,0 +1,139 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"strconv"
+)
+
+var (
+	ErrDLQNotConfigured = errors.New("dead-letter queue is not configured")
+)
+
+// dlqVisibilityTimeout 은 redrive 중인 DLQ 메시지를 다른 소비자로부터 숨기는 시간
+const dlqVisibilityTimeout = 30
+
+// newRedrivePolicy 는 DLQ 의 ARN 을 조회해 RedrivePolicy 속성 값을 만든다.
+func newRedrivePolicy(client sqsiface.SQSAPI, dlqURL string, maxReceiveCount int) (string, error) {
+	if maxReceiveCount <= 0 {
+		maxReceiveCount = defaultMaxReceiveCount
+	}
+
+	result, err := client.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(dlqURL),
+		AttributeNames: []*string{aws.String(sqs.QueueAttributeNameQueueArn)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get dead-letter queue arn: %w", err)
+	}
+
+	policy, err := json.Marshal(map[string]string{
+		"deadLetterTargetArn": aws.StringValue(result.Attributes[sqs.QueueAttributeNameQueueArn]),
+		"maxReceiveCount":     strconv.Itoa(maxReceiveCount),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal redrive policy: %w", err)
+	}
+
+	return string(policy), nil
+}
+
+// DLQStats 는 DLQ 의 대략적인 메시지 수
+type DLQStats struct {
+	Messages           int
+	MessagesNotVisible int
+}
+
+// GetDLQStats 는 DLQ 에 쌓인 메시지 수를 조회한다.
+func (s *SQSClient) GetDLQStats(ctx context.Context) (*DLQStats, error) {
+	if s.dlqURL == "" {
+		return nil, ErrDLQNotConfigured
+	}
+
+	input := &sqs.GetQueueAttributesInput{
+		QueueUrl: aws.String(s.dlqURL),
+		AttributeNames: []*string{
+			aws.String(sqs.QueueAttributeNameApproximateNumberOfMessages),
+			aws.String(sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible),
+		},
+	}
+
+	result, err := s.client.GetQueueAttributesWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead-letter queue attributes: %w", err)
+	}
+
+	stats := &DLQStats{}
+	stats.Messages, _ = strconv.Atoi(aws.StringValue(result.Attributes[sqs.QueueAttributeNameApproximateNumberOfMessages]))
+	stats.MessagesNotVisible, _ = strconv.Atoi(aws.StringValue(result.Attributes[sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible]))
+
+	return stats, nil
+}
+
+// PeekDeadLetters 는 DLQ 메시지를 최대 maxMessages 건 조회한다. maxMessages 는 SQS 가 한 번에 돌려주는 1~10 으로 맞춘다.
+// visibility timeout 을 0 으로 수신하므로 메시지는 DLQ 에 그대로 남는다.
+// 같은 메시지가 다시 수신될 수 있어 여러 번 나누어 읽지 않는다.
+func (s *SQSClient) PeekDeadLetters(ctx context.Context, maxMessages int64) ([]*Message, error) {
+	if s.dlqURL == "" {
+		return nil, ErrDLQNotConfigured
+	}
+
+	if maxMessages < 1 {
+		maxMessages = 1
+	}
+	if maxMessages > maxReceiveMessages {
+		maxMessages = maxReceiveMessages
+	}
+	return s.receive(ctx, s.dlqURL, maxMessages, 0, 0)
+}
+
+// RedriveDeadLetters 는 DLQ 메시지를 최대 limit 건 메인 큐로 되돌린다. (limit <= 0 이면 DLQ 가 빌 때까지)
+// 메인 큐 전송에 성공한 메시지만 DLQ 에서 삭제하므로, 중간에 실패해도 메시지는 유실되지 않는다.
+func (s *SQSClient) RedriveDeadLetters(ctx context.Context, limit int) (int, error) {
+	if s.dlqURL == "" {
+		return 0, ErrDLQNotConfigured
+	}
+
+	moved := 0
+	for limit <= 0 || moved < limit {
+		batchSize := int64(maxReceiveMessages)
+		if limit > 0 && int64(limit-moved) < batchSize {
+			batchSize = int64(limit - moved)
+		}
+
+		messages, err := s.receive(ctx, s.dlqURL, batchSize, 0, dlqVisibilityTimeout)
+		if err != nil {
+			return moved, err
+		}
+		if len(messages) == 0 {
+			return moved, nil
+		}
+
+		for _, message := range messages {
+			input := &sqs.SendMessageInput{
+				QueueUrl:          aws.String(s.queueURL),
+				MessageBody:       aws.String(message.Body),
+				MessageAttributes: message.messageAttributes,
+			}
+			if _, err := s.client.SendMessageWithContext(ctx, input); err != nil {
+				return moved, fmt.Errorf("failed to redrive message %s: %w", message.ID, err)
+			}
+
+			deleteInput := &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(s.dlqURL),
+				ReceiptHandle: aws.String(message.ReceiptHandle),
+			}
+			if _, err := s.client.DeleteMessageWithContext(ctx, deleteInput); err != nil {
+				return moved, fmt.Errorf("failed to delete redriven message %s: %w", message.ID, err)
+			}
+			moved++
+		}
+	}
+
+	return moved, nil
+}
@@ -0,0 +1,204 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+const (
+	testQueueURL = "http://localhost/queue/todo-notifications"
+	testDLQURL   = "http://localhost/queue/todo-notifications-dlq"
+)
+
+// fakeSQS: 큐 URL 별 메시지를 메모리에 두는 SQS API 테스트 더블.
+// visibility timeout 이 0 보다 크게 수신된 메시지는 삭제될 때까지 in flight 로 숨긴다.
+type fakeSQS struct {
+	sqsiface.SQSAPI
+
+	queues   map[string][]*sqs.Message
+	inFlight map[string][]*sqs.Message
+	// sendFailsAfter 는 0 이상이면 그만큼 전송한 뒤부터 SendMessage 가 실패한다.
+	sendFailsAfter int
+	sent           int
+}
+
+func newFakeSQS() *fakeSQS {
+	return &fakeSQS{
+		queues:         make(map[string][]*sqs.Message),
+		inFlight:       make(map[string][]*sqs.Message),
+		sendFailsAfter: -1,
+	}
+}
+
+func (f *fakeSQS) add(queueURL string, n int) {
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("%d", len(f.queues[queueURL])+len(f.inFlight[queueURL])+1)
+		f.queues[queueURL] = append(f.queues[queueURL], &sqs.Message{
+			MessageId:     aws.String(id),
+			ReceiptHandle: aws.String("receipt-" + id),
+			Body:          aws.String("message " + id),
+			MessageAttributes: map[string]*sqs.MessageAttributeValue{
+				"EventType": {DataType: aws.String("String"), StringValue: aws.String("todo_created")},
+			},
+		})
+	}
+}
+
+func (f *fakeSQS) ReceiveMessageWithContext(_ aws.Context, input *sqs.ReceiveMessageInput, _ ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	// 실제 SQS 와 같이 MaxNumberOfMessages 는 1~10 이어야 한다.
+	n := int(aws.Int64Value(input.MaxNumberOfMessages))
+	if n < 1 || n > 10 {
+		return nil, errors.New("InvalidParameterValue: MaxNumberOfMessages must be between 1 and 10")
+	}
+
+	url := aws.StringValue(input.QueueUrl)
+	if n > len(f.queues[url]) {
+		n = len(f.queues[url])
+	}
+	received := f.queues[url][:n]
+	if aws.Int64Value(input.VisibilityTimeout) > 0 {
+		f.queues[url] = f.queues[url][n:]
+		f.inFlight[url] = append(f.inFlight[url], received...)
+	}
+	return &sqs.ReceiveMessageOutput{Messages: received}, nil
+}
+
+func (f *fakeSQS) SendMessageWithContext(_ aws.Context, input *sqs.SendMessageInput, _ ...request.Option) (*sqs.SendMessageOutput, error) {
+	if f.sendFailsAfter >= 0 && f.sent >= f.sendFailsAfter {
+		return nil, errors.New("sqs unavailable")
+	}
+	f.sent++
+
+	url := aws.StringValue(input.QueueUrl)
+	f.queues[url] = append(f.queues[url], &sqs.Message{
+		MessageId:         aws.String(strconv.Itoa(f.sent)),
+		Body:              input.MessageBody,
+		MessageAttributes: input.MessageAttributes,
+	})
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func (f *fakeSQS) DeleteMessageWithContext(_ aws.Context, input *sqs.DeleteMessageInput, _ ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	url := aws.StringValue(input.QueueUrl)
+	for i, message := range f.inFlight[url] {
+		if aws.StringValue(message.ReceiptHandle) == aws.StringValue(input.ReceiptHandle) {
+			f.inFlight[url] = append(f.inFlight[url][:i], f.inFlight[url][i+1:]...)
+			return &sqs.DeleteMessageOutput{}, nil
+		}
+	}
+	return nil, errors.New("ReceiptHandleIsInvalid")
+}
+
+func (f *fakeSQS) GetQueueAttributesWithContext(_ aws.Context, input *sqs.GetQueueAttributesInput, _ ...request.Option) (*sqs.GetQueueAttributesOutput, error) {
+	url := aws.StringValue(input.QueueUrl)
+	return &sqs.GetQueueAttributesOutput{Attributes: map[string]*string{
+		sqs.QueueAttributeNameApproximateNumberOfMessages:           aws.String(strconv.Itoa(len(f.queues[url]))),
+		sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible: aws.String(strconv.Itoa(len(f.inFlight[url]))),
+	}}, nil
+}
+
+func newTestClient(fake *fakeSQS) *SQSClient {
+	return &SQSClient{client: fake, queueURL: testQueueURL, dlqURL: testDLQURL}
+}
+
+func TestDeadLetterQueue(t *testing.T) {
+	t.Run("Peek Clamps Message Count And Keeps Messages", func(t *testing.T) {
+		// Arrange
+		fake := newFakeSQS()
+		fake.add(testDLQURL, 12)
+		client := newTestClient(fake)
+		ctx := context.Background()
+
+		// Act
+		many, manyErr := client.PeekDeadLetters(ctx, 50)
+		none, noneErr := client.PeekDeadLetters(ctx, 0)
+		stats, err := client.GetDLQStats(ctx)
+
+		// Assert
+		require.NoError(t, manyErr)
+		assert.Len(t, many, 10)
+		assert.Equal(t, "message 1", many[0].Body)
+		assert.Equal(t, "todo_created", many[0].Attributes["EventType"])
+		require.NoError(t, noneErr)
+		assert.Len(t, none, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &DLQStats{Messages: 12}, stats)
+	})
+
+	t.Run("Redrive Moves All Messages In Batches", func(t *testing.T) {
+		// Arrange
+		fake := newFakeSQS()
+		fake.add(testDLQURL, 12)
+		client := newTestClient(fake)
+
+		// Act
+		moved, err := client.RedriveDeadLetters(context.Background(), 0)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 12, moved)
+		assert.Empty(t, fake.queues[testDLQURL])
+		assert.Empty(t, fake.inFlight[testDLQURL])
+		require.Len(t, fake.queues[testQueueURL], 12)
+		assert.Equal(t, "message 1", aws.StringValue(fake.queues[testQueueURL][0].Body))
+		assert.Equal(t, "todo_created", aws.StringValue(fake.queues[testQueueURL][0].MessageAttributes["EventType"].StringValue))
+	})
+
+	t.Run("Redrive Stops At Limit", func(t *testing.T) {
+		// Arrange
+		fake := newFakeSQS()
+		fake.add(testDLQURL, 5)
+		client := newTestClient(fake)
+
+		// Act
+		moved, err := client.RedriveDeadLetters(context.Background(), 3)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, moved)
+		assert.Len(t, fake.queues[testQueueURL], 3)
+		assert.Len(t, fake.queues[testDLQURL], 2)
+	})
+
+	t.Run("Redrive Keeps Messages That Failed To Send", func(t *testing.T) {
+		// Arrange
+		fake := newFakeSQS()
+		fake.add(testDLQURL, 3)
+		fake.sendFailsAfter = 1
+		client := newTestClient(fake)
+
+		// Act
+		moved, err := client.RedriveDeadLetters(context.Background(), 0)
+
+		// Assert: 전송하지 못한 메시지는 DLQ 에서 삭제되지 않고 visibility timeout 뒤 다시 보인다.
+		assert.Error(t, err)
+		assert.Equal(t, 1, moved)
+		assert.Len(t, fake.queues[testQueueURL], 1)
+		assert.Len(t, fake.inFlight[testDLQURL], 2)
+	})
+
+	t.Run("DLQ Not Configured", func(t *testing.T) {
+		// Arrange
+		client := &SQSClient{client: newFakeSQS(), queueURL: testQueueURL}
+		ctx := context.Background()
+
+		// Act
+		_, statsErr := client.GetDLQStats(ctx)
+		_, peekErr := client.PeekDeadLetters(ctx, 10)
+		_, redriveErr := client.RedriveDeadLetters(ctx, 0)
+
+		// Assert
+		assert.ErrorIs(t, statsErr, ErrDLQNotConfigured)
+		assert.ErrorIs(t, peekErr, ErrDLQNotConfigured)
+		assert.ErrorIs(t, redriveErr, ErrDLQNotConfigured)
+	})
+}
@@ -1,12 +1,15 @@
 package sqs
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"strconv"
 )
 
 type SQSConfig struct {
@@ -15,14 +18,24 @@ type SQSConfig struct {
 	QueueName   string `json:"queue_name"`
 	AccessKey   string `json:"access_key"`
 	SecretKey   string `json:"secret_key"`
+	// DLQName 이 설정되면 dead-letter queue 를 만들고 redrive policy 를 연결한다.
+	DLQName string `json:"dlq_name"`
+	// MaxReceiveCount 는 메시지가 DLQ 로 옮겨지기 전 최대 수신 횟수 (기본 5)
+	MaxReceiveCount int `json:"max_receive_count"`
 }
 
+const defaultMaxReceiveCount = 5
+
 type SQSClient struct {
-	client   *sqs.SQS
+	client   sqsiface.SQSAPI
 	queueURL string
+	// dlqURL 은 DLQ 가 설정되지 않았으면 비어 있다.
+	dlqURL string
 }
 
 type NotificationMessage struct {
+	// EventID 는 outbox 이벤트 ID. at-least-once 전달이므로 소비자는 이 값으로 중복을 걸러낸다.
+	EventID   int64  `json:"event_id,omitempty"`
 	EventType string `json:"event_type"`
 	TodoID    int64  `json:"todo_id"`
 	Title     string `json:"title"`
@@ -45,8 +58,30 @@ func NewSQSClient(config SQSConfig) (*SQSClient, error) {
 	// SQS 클라이언트 생성
 	sqsClient := sqs.New(sess)
 
+	attributes := map[string]*string{
+		sqs.QueueAttributeNameVisibilityTimeout:      aws.String("300"),     // 5분
+		sqs.QueueAttributeNameMessageRetentionPeriod: aws.String("1209600"), // 14일
+	}
+
+	// DLQ 를 먼저 만들고 메인 큐에 redrive policy 로 연결
+	var dlqURL string
+	if config.DLQName != "" {
+		dlqURL, err = getOrCreateQueue(sqsClient, config.DLQName, map[string]*string{
+			sqs.QueueAttributeNameMessageRetentionPeriod: aws.String("1209600"), // 14일
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get or create dead-letter queue: %w", err)
+		}
+
+		redrivePolicy, err := newRedrivePolicy(sqsClient, dlqURL, config.MaxReceiveCount)
+		if err != nil {
+			return nil, err
+		}
+		attributes[sqs.QueueAttributeNameRedrivePolicy] = aws.String(redrivePolicy)
+	}
+
 	// 큐 URL 가져오기 (큐가 없으면 생성)
-	queueURL, err := getOrCreateQueue(sqsClient, config.QueueName)
+	queueURL, err := getOrCreateQueue(sqsClient, config.QueueName, attributes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get or create queue: %w", err)
 	}
@@ -54,10 +89,11 @@ func NewSQSClient(config SQSConfig) (*SQSClient, error) {
 	return &SQSClient{
 		client:   sqsClient,
 		queueURL: queueURL,
+		dlqURL:   dlqURL,
 	}, nil
 }
 
-func getOrCreateQueue(client *sqs.SQS, queueName string) (string, error) {
+func getOrCreateQueue(client sqsiface.SQSAPI, queueName string, attributes map[string]*string) (string, error) {
 	// 기존 큐 URL 가져오기 시도
 	getQueueURLInput := &sqs.GetQueueUrlInput{
 		QueueName: aws.String(queueName),
@@ -65,16 +101,25 @@ func getOrCreateQueue(client *sqs.SQS, queueName string) (string, error) {
 
 	result, err := client.GetQueueUrl(getQueueURLInput)
 	if err == nil {
+		// 이미 있는 큐에도 redrive policy 는 반영한다.
+		if policy, ok := attributes[sqs.QueueAttributeNameRedrivePolicy]; ok {
+			_, err := client.SetQueueAttributes(&sqs.SetQueueAttributesInput{
+				QueueUrl: result.QueueUrl,
+				Attributes: map[string]*string{
+					sqs.QueueAttributeNameRedrivePolicy: policy,
+				},
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to set redrive policy: %w", err)
+			}
+		}
 		return *result.QueueUrl, nil
 	}
 
 	// 큐가 없으면 생성
 	createQueueInput := &sqs.CreateQueueInput{
-		QueueName: aws.String(queueName),
-		Attributes: map[string]*string{
-			"VisibilityTimeoutSeconds": aws.String("300"),     // 5분
-			"MessageRetentionPeriod":   aws.String("1209600"), // 14일
-		},
+		QueueName:  aws.String(queueName),
+		Attributes: attributes,
 	}
 
 	createResult, err := client.CreateQueue(createQueueInput)
@@ -132,3 +177,86 @@ func (s *SQSClient) GetQueueAttributes() (map[string]*string, error) {
 
 	return result.Attributes, nil
 }
+
+// Message 는 큐에서 수신한 메시지
+type Message struct {
+	ID            string
+	ReceiptHandle string
+	Body          string
+	Attributes    map[string]string
+	// ReceiveCount 는 이 메시지가 수신된 횟수 (ApproximateReceiveCount)
+	ReceiveCount int
+
+	// messageAttributes 는 redrive 시 원본 타입 그대로 재전송하기 위한 원본 속성
+	messageAttributes map[string]*sqs.MessageAttributeValue
+}
+
+// ReceiveMessages 는 long polling 으로 최대 maxMessages(1~10)건의 메시지를 수신한다.
+func (s *SQSClient) ReceiveMessages(ctx context.Context, maxMessages, waitSeconds, visibilityTimeoutSeconds int64) ([]*Message, error) {
+	return s.receive(ctx, s.queueURL, maxMessages, waitSeconds, visibilityTimeoutSeconds)
+}
+
+func (s *SQSClient) receive(ctx context.Context, queueURL string, maxMessages, waitSeconds, visibilityTimeoutSeconds int64) ([]*Message, error) {
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(queueURL),
+		MaxNumberOfMessages:   aws.Int64(maxMessages),
+		WaitTimeSeconds:       aws.Int64(waitSeconds),
+		VisibilityTimeout:     aws.Int64(visibilityTimeoutSeconds),
+		AttributeNames:        []*string{aws.String(sqs.MessageSystemAttributeNameApproximateReceiveCount)},
+		MessageAttributeNames: []*string{aws.String("All")},
+	}
+
+	result, err := s.client.ReceiveMessageWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive messages from SQS: %w", err)
+	}
+
+	messages := make([]*Message, 0, len(result.Messages))
+	for _, m := range result.Messages {
+		message := &Message{
+			ID:                aws.StringValue(m.MessageId),
+			ReceiptHandle:     aws.StringValue(m.ReceiptHandle),
+			Body:              aws.StringValue(m.Body),
+			Attributes:        make(map[string]string, len(m.MessageAttributes)),
+			messageAttributes: m.MessageAttributes,
+		}
+		for name, value := range m.MessageAttributes {
+			message.Attributes[name] = aws.StringValue(value.StringValue)
+		}
+		if count, ok := m.Attributes[sqs.MessageSystemAttributeNameApproximateReceiveCount]; ok {
+			message.ReceiveCount, _ = strconv.Atoi(aws.StringValue(count))
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// DeleteMessage 는 처리 완료된 메시지를 큐에서 삭제한다.
+func (s *SQSClient) DeleteMessage(ctx context.Context, receiptHandle string) error {
+	input := &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(s.queueURL),
+		ReceiptHandle: aws.String(receiptHandle),
+	}
+
+	if _, err := s.client.DeleteMessageWithContext(ctx, input); err != nil {
+		return fmt.Errorf("failed to delete message from SQS: %w", err)
+	}
+
+	return nil
+}
+
+// ChangeMessageVisibility 는 처리 중인 메시지의 visibility timeout 을 연장한다.
+func (s *SQSClient) ChangeMessageVisibility(ctx context.Context, receiptHandle string, visibilityTimeoutSeconds int64) error {
+	input := &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(s.queueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: aws.Int64(visibilityTimeoutSeconds),
+	}
+
+	if _, err := s.client.ChangeMessageVisibilityWithContext(ctx, input); err != nil {
+		return fmt.Errorf("failed to change message visibility: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,8 @@ package integration
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -11,6 +13,7 @@ import (
 	"integration-test-example/internal/repository"
 	"integration-test-example/pkg/config"
 	"integration-test-example/pkg/database"
+	redisClient "integration-test-example/pkg/redis"
 	"io"
 	"net/http"
 	"strconv"
@@ -24,7 +27,8 @@ func TestTodoIntegration(t *testing.T) {
 	composeStack, err := compose.NewDockerCompose("../../docker-compose.yml")
 	require.NoError(t, err, "Failed to create docker-compose stack")
 
-	err = composeStack.Up(ctx,
+	// 서명 키는 커밋하지 않으므로 실행마다 임의로 만들어 app 컨테이너에 넘긴다.
+	err = composeStack.WithEnv(map[string]string{"AUTH_SECRET": randomSecret(t)}).Up(ctx,
 		compose.Wait(true),
 	)
 	require.NoError(t, err, "Failed to start docker-compose stack")
@@ -39,12 +43,25 @@ func TestTodoIntegration(t *testing.T) {
 
 	baseURL := "http://localhost:8080"
 
-	db, err := database.Connect(getTestDatabaseConfig())
-	repo := repository.NewTodoRepository(db)
-
 	t.Log("Waiting for application to be ready...")
 	waitForApplication(t, baseURL)
 
+	// todo API 는 로그인한 사용자만 사용할 수 있으므로, 가입한 사용자의 토큰으로 요청하고 그 사용자의 todo 로 검증한다.
+	token, userID := registerAndLogin(t, baseURL)
+	db, err := database.Connect(getTestDatabaseConfig())
+	require.NoError(t, err)
+	repo := repository.NewTodoRepository(db).ForOwner(userID)
+
+	t.Run("Todo API Requires Login", func(t *testing.T) {
+		// Act
+		resp, err := http.Get(baseURL + "/api/v1/todos")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
 	t.Run("Create Todo", func(t *testing.T) {
 		// Arrange
 		todoReq := map[string]interface{}{
@@ -55,8 +72,7 @@ func TestTodoIntegration(t *testing.T) {
 		require.NoError(t, err)
 
 		// Act
-		resp, err := http.Post(baseURL+"/api/v1/todos", "application/json", bytes.NewBuffer(reqBody))
-		require.NoError(t, err)
+		resp := send(t, http.MethodPost, baseURL+"/api/v1/todos", token, reqBody)
 
 		// Assert
 		assert.Equal(t, resp.StatusCode, http.StatusOK)
@@ -95,8 +111,7 @@ func TestTodoIntegration(t *testing.T) {
 		}
 
 		// Act
-		resp, err := http.Get(baseURL + "/api/v1/todos")
-		assert.NoError(t, err)
+		resp := send(t, http.MethodGet, baseURL+"/api/v1/todos", token, nil)
 
 		// Assert
 		assert.Equal(t, resp.StatusCode, http.StatusOK)
@@ -117,8 +132,7 @@ func TestTodoIntegration(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Act
-		resp, err := http.Get(baseURL + "/api/v1/todos/" + strconv.Itoa(int(dummyTodo.ID)))
-		assert.NoError(t, err)
+		resp := send(t, http.MethodGet, baseURL+"/api/v1/todos/"+strconv.Itoa(int(dummyTodo.ID)), token, nil)
 
 		// Assert
 		assert.Equal(t, resp.StatusCode, http.StatusOK)
@@ -131,6 +145,8 @@ func TestTodoIntegration(t *testing.T) {
 		err = json.Unmarshal(body, &todoResp)
 		assert.NoError(t, err)
 		assert.Equal(t, dummyTodo.ID, todoResp.Todo.ID)
+		// 우선순위 없이 저장한 todo 는 기본값(normal)으로 조회된다.
+		assert.Equal(t, model.PriorityNormal, todoResp.Todo.Priority)
 	})
 
 	t.Run("Update todo", func(t *testing.T) {
@@ -150,14 +166,8 @@ func TestTodoIntegration(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Act
-		client := &http.Client{}
-		req, err := http.NewRequest(http.MethodPut, baseURL+"/api/v1/todos/"+strconv.Itoa(int(dummyTodo.ID)), bytes.NewBuffer(reqBody))
-		assert.NoError(t, err)
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := client.Do(req)
+		resp := send(t, http.MethodPut, baseURL+"/api/v1/todos/"+strconv.Itoa(int(dummyTodo.ID)), token, reqBody)
 		defer resp.Body.Close()
-		assert.NoError(t, err)
 
 		// Assert
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
@@ -185,29 +195,115 @@ func TestTodoIntegration(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Act
-		client := &http.Client{}
-		req, err := http.NewRequest(http.MethodDelete, baseURL+"/api/v1/todos/"+strconv.Itoa(int(dummyTodo.ID)), nil)
-		assert.NoError(t, err)
-		req.Header.Set("Content-Type", "application/json")
-		resp, err := client.Do(req)
+		resp := send(t, http.MethodDelete, baseURL+"/api/v1/todos/"+strconv.Itoa(int(dummyTodo.ID)), token, nil)
 		defer resp.Body.Close()
-		assert.NoError(t, err)
 
 		// Assert
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 		_, err = repo.GetTodo(int(dummyTodo.ID))
 		assert.Equal(t, err, repository.ErrTodoNotFound)
+		trashed, err := repo.GetTrashedTodo(int(dummyTodo.ID))
+		require.NoError(t, err)
+		assert.NotNil(t, trashed.DeletedAt)
 	})
 
 	t.Run("Get Not Exist Todo Should Return 404", func(t *testing.T) {
 		// Act
-		resp, err := http.Get(baseURL + "/api/v1/todos/" + strconv.Itoa(int(time.Now().Unix())))
-		assert.NoError(t, err)
+		resp := send(t, http.MethodGet, baseURL+"/api/v1/todos/"+strconv.Itoa(int(time.Now().Unix())), token, nil)
 
 		// Assert
 		assert.Equal(t, resp.StatusCode, http.StatusNotFound)
 	})
 
+	t.Run("Lists And Tags Of Another User Should Return 404", func(t *testing.T) {
+		// Arrange
+		owner := repository.NewTodoRepository(db)
+		list, err := owner.ListsForOwner(userID).CreateList(&model.List{Name: "work"})
+		require.NoError(t, err)
+		tag, err := owner.TagsForOwner(userID).CreateTag(&model.Tag{Name: "work" + strconv.FormatInt(time.Now().UnixNano(), 10)})
+		require.NoError(t, err)
+		otherToken, _ := registerAndLogin(t, baseURL)
+
+		// Act
+		listResp := send(t, http.MethodGet, baseURL+"/api/v1/lists/"+strconv.Itoa(int(list.ID)), otherToken, nil)
+		defer listResp.Body.Close()
+		tagResp := send(t, http.MethodDelete, baseURL+"/api/v1/tags/"+strconv.Itoa(int(tag.ID)), otherToken, nil)
+		defer tagResp.Body.Close()
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, listResp.StatusCode)
+		assert.Equal(t, http.StatusNotFound, tagResp.StatusCode)
+		_, err = owner.ListsForOwner(userID).GetList(int(list.ID))
+		assert.NoError(t, err)
+		_, err = owner.TagsForOwner(userID).GetTag(int(tag.ID))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unowned Rows Should Be Claimed By A User", func(t *testing.T) {
+		// Arrange: 사용자 도입 전에 만든 (소유자가 없는) list, 태그, todo. 넘겨받을 사용자에게는 같은 이름의 태그가 이미 있다.
+		legacy := repository.NewTodoRepository(db)
+		tagName := "legacy" + strconv.FormatInt(time.Now().UnixNano(), 10)
+		list, err := legacy.CreateList(&model.List{Name: "legacy"})
+		require.NoError(t, err)
+		legacyTag, err := legacy.CreateTag(&model.Tag{Name: tagName})
+		require.NoError(t, err)
+		todo, err := legacy.Create(&model.Todo{Title: "legacy todo", ListID: &list.ID, Tags: []model.Tag{{ID: legacyTag.ID}}})
+		require.NoError(t, err)
+		claimerToken, claimerID := registerAndLogin(t, baseURL)
+		claimerTag, err := legacy.TagsForOwner(claimerID).CreateTag(&model.Tag{Name: tagName})
+		require.NoError(t, err)
+
+		// Act
+		claimed, err := legacy.ClaimUnowned(claimerID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, claimed.Todos, int64(1))
+		assert.GreaterOrEqual(t, claimed.Lists, int64(1))
+		assert.GreaterOrEqual(t, claimed.Tags, int64(1))
+
+		todoResp := send(t, http.MethodGet, baseURL+"/api/v1/todos/"+strconv.Itoa(int(todo.ID)), claimerToken, nil)
+		defer todoResp.Body.Close()
+		require.Equal(t, http.StatusOK, todoResp.StatusCode)
+		var body struct {
+			Todo model.Todo `json:"todo"`
+		}
+		require.NoError(t, json.NewDecoder(todoResp.Body).Decode(&body))
+		require.Len(t, body.Todo.Tags, 1)
+		assert.Equal(t, claimerTag.ID, body.Todo.Tags[0].ID, "같은 이름의 태그는 사용자의 태그로 합쳐진다")
+
+		listResp := send(t, http.MethodGet, baseURL+"/api/v1/lists/"+strconv.Itoa(int(list.ID)), claimerToken, nil)
+		defer listResp.Body.Close()
+		assert.Equal(t, http.StatusOK, listResp.StatusCode)
+		otherResp := send(t, http.MethodGet, baseURL+"/api/v1/todos/"+strconv.Itoa(int(todo.ID)), token, nil)
+		defer otherResp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, otherResp.StatusCode)
+	})
+
+	t.Run("Rate Limit Headers On Every Response", func(t *testing.T) {
+		// Act
+		resp := send(t, http.MethodGet, baseURL+"/api/v1/todos", token, nil)
+		defer resp.Body.Close()
+
+		// Assert
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("X-RateLimit-Limit"))
+		assert.NotEmpty(t, resp.Header.Get("X-RateLimit-Remaining"))
+		assert.NotEmpty(t, resp.Header.Get("RateLimit-Policy"))
+		assert.NotEmpty(t, resp.Header.Get("RateLimit"))
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, reset, time.Now().Unix())
+	})
+
+	t.Run("Rate Limit Algorithms", func(t *testing.T) {
+		rdb, err := redisClient.NewRedisClient(getTestRedisConfig())
+		require.NoError(t, err)
+		defer rdb.Close()
+
+		testRateLimitAlgorithms(t, rdb)
+	})
+
 	t.Run("Invalid Request", func(t *testing.T) {
 		t.Run("Create Todo with empty title", func(t *testing.T) {
 			// Arrange: 잘못된 요청 데이터 (제목 없음)
@@ -218,8 +314,7 @@ func TestTodoIntegration(t *testing.T) {
 			reqBody, _ := json.Marshal(invalidReq)
 
 			// Act
-			resp, err := http.Post(baseURL+"/api/v1/todos", "application/json", bytes.NewBuffer(reqBody))
-			assert.NoError(t, err)
+			resp := send(t, http.MethodPost, baseURL+"/api/v1/todos", token, reqBody)
 			defer resp.Body.Close()
 
 			// Assert
@@ -229,6 +324,54 @@ func TestTodoIntegration(t *testing.T) {
 	})
 }
 
+// randomSecret: 실행마다 새로 만드는 32 byte 서명 키 테스트 픽스쳐
+func randomSecret(t *testing.T) string {
+	t.Helper()
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	require.NoError(t, err)
+	return hex.EncodeToString(secret)
+}
+
+// registerAndLogin: 새 사용자를 가입시키고 로그인해 access token 과 사용자 ID 를 반환하는 테스트 픽스쳐
+func registerAndLogin(t *testing.T, baseURL string) (string, int64) {
+	t.Helper()
+	credentials, err := json.Marshal(map[string]interface{}{
+		"email":    "user" + strconv.FormatInt(time.Now().UnixNano(), 10) + "@example.com",
+		"password": "integration-password",
+	})
+	require.NoError(t, err)
+
+	registered := send(t, http.MethodPost, baseURL+"/api/v1/auth/register", "", credentials)
+	registered.Body.Close()
+	require.Equal(t, http.StatusOK, registered.StatusCode)
+
+	resp := send(t, http.MethodPost, baseURL+"/api/v1/auth/login", "", credentials)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var loginResp struct {
+		AccessToken string     `json:"access_token"`
+		User        model.User `json:"user"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&loginResp))
+	return loginResp.AccessToken, loginResp.User.ID
+}
+
+// send: token 이 있으면 Bearer 토큰으로 인증해 JSON 요청을 보내는 테스트 헬퍼
+func send(t *testing.T, method, url, token string, body []byte) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
 func waitForApplication(t *testing.T, baseURL string) {
 	maxAttempts := 30
 	for i := 0; i < maxAttempts; i++ {
@@ -259,3 +402,11 @@ func getTestDatabaseConfig() config.DatabaseConfig {
 		Name:     "todoapp",
 	}
 }
+
+// getTestRedisConfig: Test용 Redis 설정 테스트 픽스쳐
+func getTestRedisConfig() redisClient.Config {
+	return redisClient.Config{
+		Host: "localhost",
+		Port: 6379,
+	}
+}
@@ -0,0 +1,229 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"integration-test-example/internal/middleware"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testClientIP 는 rate limit 요청자를 구분하는 IP (httptest 요청의 기본 RemoteAddr)
+const testClientIP = "192.0.2.1"
+
+// testRateLimitAlgorithms 는 compose 의 Redis 위에서 각 알고리즘의 Lua 스크립트가 limit, Retry-After, reset 과 RateLimit 헤더를 올바르게 계산하는지 검증한다.
+func testRateLimitAlgorithms(t *testing.T, rdb *redis.Client) {
+	t.Run("Fixed Window", func(t *testing.T) {
+		// Arrange
+		name := uniquePolicyName("fixed")
+		router := rateLimitedRouter(t, rdb, middleware.PolicyConfig{Name: name, Algorithm: middleware.FixedWindow, Limit: 3, WindowSeconds: 2})
+		key := "rate_limit:fw:" + subject(name)
+
+		// Act & Assert: limit 까지는 통과한다.
+		for i := 1; i <= 3; i++ {
+			w := hit(router)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, strconv.Itoa(3-i), w.Header().Get("X-RateLimit-Remaining"))
+		}
+
+		// Act & Assert: 다음 요청은 window 가 끝날 때까지 거부된다. (Retry-After = 카운터 키의 남은 TTL)
+		w := hit(router)
+		ttl := rdb.PTTL(context.Background(), key).Val()
+		windowEnd := time.Now().Add(ttl)
+		assertRateLimited(t, w, name, windowEnd)
+
+		time.Sleep(time.Until(windowEnd.Add(-200 * time.Millisecond)))
+		assert.Equal(t, http.StatusTooManyRequests, hit(router).Code, "window 가 끝나기 전에는 거부된다")
+
+		// Act & Assert: window 가 끝나면 카운터가 초기화된다.
+		time.Sleep(time.Until(windowEnd.Add(50 * time.Millisecond)))
+		w = hit(router)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Remaining"))
+	})
+
+	t.Run("Sliding Window Log", func(t *testing.T) {
+		// Arrange
+		name := uniquePolicyName("log")
+		router := rateLimitedRouter(t, rdb, middleware.PolicyConfig{Name: name, Algorithm: middleware.SlidingWindowLog, Limit: 3, WindowSeconds: 2})
+		key := "rate_limit:swl:" + subject(name)
+
+		// Act & Assert: 첫 요청 0.5초 뒤에 두 요청을 더 보내 limit 을 채운다.
+		require.Equal(t, http.StatusOK, hit(router).Code)
+		time.Sleep(500 * time.Millisecond)
+		for _, remaining := range []string{"1", "0"} {
+			w := hit(router)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, remaining, w.Header().Get("X-RateLimit-Remaining"))
+		}
+
+		// Act & Assert: 다음 요청은 가장 오래된 (첫) 요청이 window 밖으로 나갈 때까지 거부된다.
+		w := hit(router)
+		oldest, err := rdb.ZRangeWithScores(context.Background(), key, 0, 0).Result()
+		require.NoError(t, err)
+		require.Len(t, oldest, 1)
+		slotFreed := time.UnixMilli(int64(oldest[0].Score)).Add(2 * time.Second)
+		assertRateLimited(t, w, name, slotFreed)
+
+		time.Sleep(time.Until(slotFreed.Add(-200 * time.Millisecond)))
+		assert.Equal(t, http.StatusTooManyRequests, hit(router).Code, "첫 요청이 window 안에 있는 동안은 거부된다")
+
+		// Act & Assert: 한 자리만 비므로 한 요청만 통과하고, 나머지 두 요청이 window 안에 있는 동안은 다시 거부된다.
+		time.Sleep(time.Until(slotFreed.Add(50 * time.Millisecond)))
+		w = hit(router)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, http.StatusTooManyRequests, hit(router).Code)
+	})
+
+	t.Run("Sliding Window Counter", func(t *testing.T) {
+		// Arrange: window 번호는 Redis 시각으로 정해지므로 window 가 막 시작된 시점부터 보낸다.
+		name := uniquePolicyName("counter")
+		router := rateLimitedRouter(t, rdb, middleware.PolicyConfig{Name: name, Algorithm: middleware.SlidingWindowCounter, Limit: 4, WindowSeconds: 2})
+		windowStart := nextWindowStart(t, rdb, 2*time.Second)
+		time.Sleep(time.Until(windowStart.Add(50 * time.Millisecond)))
+
+		// Act & Assert: 이전 window 에 요청이 없으므로 limit 까지 통과한다.
+		for i := 1; i <= 4; i++ {
+			w := hit(router)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, strconv.Itoa(4-i), w.Header().Get("X-RateLimit-Remaining"))
+		}
+
+		// Act & Assert: 다음 요청은 window 가 끝날 때까지 거부된다.
+		windowEnd := windowStart.Add(2 * time.Second)
+		assertRateLimited(t, hit(router), name, windowEnd)
+
+		// Act & Assert: 다음 window 가 시작돼도 이전 window 의 요청 4개가 (2000-50)/2000 만큼 남아 있어 거부되고,
+		// 가중치가 3개 이하로 줄어드는 시점(window 시작 0.5초 뒤)까지 기다리게 한다.
+		time.Sleep(time.Until(windowEnd.Add(50 * time.Millisecond)))
+		assertRateLimited(t, hit(router), name, windowEnd.Add(500*time.Millisecond))
+
+		time.Sleep(time.Until(windowEnd.Add(600 * time.Millisecond)))
+		w := hit(router)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	})
+
+	t.Run("Token Bucket", func(t *testing.T) {
+		// Arrange: 초당 2개씩 채워지는 3개짜리 bucket. 빈 bucket 이 가득 차기까지 1.5초 걸린다.
+		name := uniquePolicyName("bucket")
+		router := rateLimitedRouter(t, rdb, middleware.PolicyConfig{Name: name, Algorithm: middleware.TokenBucket, Rate: 2, Burst: 3})
+		key := "rate_limit:tb:" + subject(name)
+
+		// Act & Assert: 새 bucket 은 가득 차 있다. 토큰 하나를 다시 채우는 0.5초 뒤에 만료된다.
+		w := hit(router)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, fmt.Sprintf("%q;q=3;w=2", name), w.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, fmt.Sprintf("%q;r=2;t=1", name), w.Header().Get("RateLimit"))
+		assert.InDelta(t, 500, rdb.PTTL(context.Background(), key).Val().Milliseconds(), 50)
+
+		// Act & Assert: burst 만큼 연속으로 통과한다.
+		for _, remaining := range []string{"1", "0"} {
+			w := hit(router)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, remaining, w.Header().Get("X-RateLimit-Remaining"))
+		}
+
+		// Act & Assert: bucket 이 비면 토큰 하나가 채워질 때까지 (0.5초) 거부되고, reset 은 bucket 이 가득 차는 시점이다.
+		w = hit(router)
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		assert.Equal(t, fmt.Sprintf("%q;r=0;t=2", name), w.Header().Get("RateLimit"))
+		fullAfter := rdb.PTTL(context.Background(), key).Val()
+		assert.InDelta(t, 1500, fullAfter.Milliseconds(), 50)
+		reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+		require.NoError(t, err)
+		assert.InDelta(t, math.Ceil(float64(time.Now().Add(fullAfter).UnixMilli())/1000), reset, 1)
+
+		// Act & Assert: 0.5초 (1/rate) 가 지나면 토큰 하나만 채워진다.
+		time.Sleep(550 * time.Millisecond)
+		w = hit(router)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, http.StatusTooManyRequests, hit(router).Code)
+
+		// Act & Assert: bucket 이 가득 차는 시점(full_after)에 키가 만료되고, 다시 burst 만큼 사용할 수 있다.
+		fullAfter = rdb.PTTL(context.Background(), key).Val()
+		require.Positive(t, fullAfter)
+		time.Sleep(fullAfter + 50*time.Millisecond)
+		assert.Zero(t, rdb.Exists(context.Background(), key).Val())
+		w = hit(router)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Remaining"))
+	})
+}
+
+// rateLimitedRouter 는 policy 하나만 적용된 RateLimiter 뒤에 GET /limited 를 둔 router
+func rateLimitedRouter(t *testing.T, rdb *redis.Client, policy middleware.PolicyConfig) *gin.Engine {
+	t.Helper()
+
+	rateLimiter, err := middleware.NewRateLimiter(rdb, middleware.RateLimitConfig{
+		Policies:    []middleware.PolicyConfig{policy},
+		FailureMode: middleware.FailClosed,
+	})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(rateLimiter.RateLimit())
+	router.GET("/limited", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func hit(router *gin.Engine) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/limited", nil))
+	return w
+}
+
+// assertRateLimited 는 w 가 429 이고 Retry-After, X-RateLimit-Reset, RateLimit 헤더가 retryAt 까지 기다리게 하는지 검증한다.
+// 헤더는 초 단위로 올림되므로 retryAt 이 초 경계에 걸려 1초 차이 나는 것은 허용한다.
+func assertRateLimited(t *testing.T, w *httptest.ResponseRecorder, name string, retryAt time.Time) {
+	t.Helper()
+
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.InDelta(t, math.Ceil(time.Until(retryAt).Seconds()), retryAfter, 1)
+
+	reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, math.Ceil(float64(retryAt.UnixMilli())/1000), reset, 1)
+
+	assert.Equal(t, fmt.Sprintf("%q;r=0;t=%d", name, retryAfter), w.Header().Get("RateLimit"))
+}
+
+// nextWindowStart 는 Redis 시각 기준으로 다음 window 가 시작되는 시각
+func nextWindowStart(t *testing.T, rdb *redis.Client, window time.Duration) time.Time {
+	t.Helper()
+
+	now, err := rdb.Time(context.Background()).Result()
+	require.NoError(t, err)
+	// 스크립트와 같이 Unix epoch 부터의 ms 를 window 로 나눠 window 번호를 정한다.
+	current := now.UnixMilli() / window.Milliseconds()
+	return time.UnixMilli((current + 1) * window.Milliseconds())
+}
+
+// uniquePolicyName 은 실행마다 다른 정책 이름을 만든다. Redis 볼륨이 남아 있어도 이전 실행의 카운터와 섞이지 않는다.
+func uniquePolicyName(prefix string) string {
+	return prefix + "-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// subject 는 RateLimiter 가 name 정책과 testClientIP 요청자로 만드는 카운터 키의 suffix
+func subject(name string) string {
+	return fmt.Sprintf("%s:%s:%s", name, middleware.KeyByIP, testClientIP)
+}